@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var (
+	commandListAttachments     = command.ListAttachments
+	commandDownloadAttachments = command.DownloadAttachments
+)
+
+// attachmentsCmd represents the attachments command
+var attachmentsCmd = &cobra.Command{
+	Use:   "attachments",
+	Short: "List and download attachments of an email",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// attachmentsListCmd represents the attachments list command
+var attachmentsListCmd = &cobra.Command{
+	Use:   "list messageID",
+	Short: "List attachments and inline files of an email",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		messageID := args[0]
+
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+		result, err := commandListAttachments(ctx, command.ListAttachmentsOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			MessageID: messageID,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// attachmentsDownloadCmd represents the attachments download command
+var attachmentsDownloadCmd = &cobra.Command{
+	Use:   "download messageID",
+	Short: "Download attachments and inline files of an email",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		messageID := args[0]
+
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+		result, err := commandDownloadAttachments(ctx, command.DownloadAttachmentsOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			MessageID: messageID,
+			Name:      cmd.Flag("name").Value.String(),
+			All:       all,
+			Dir:       cmd.Flag("dir").Value.String(),
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachmentsCmd)
+	attachmentsCmd.AddCommand(attachmentsListCmd)
+	attachmentsCmd.AddCommand(attachmentsDownloadCmd)
+	attachmentsDownloadCmd.Flags().String("name", "", "Filename or content ID of the file to download")
+	attachmentsDownloadCmd.Flags().Bool("all", false, "Download all attachments and inline files")
+	attachmentsDownloadCmd.Flags().String("dir", ".", "Output directory")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachments(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"attachments", "list", "message-id"})
+
+	commandListAttachments = func(_ context.Context, _ command.ListAttachmentsOptions) (*email.AttachmentsResult, error) {
+		return &email.AttachmentsResult{
+			MessageID:   "message-id",
+			Attachments: []email.Attachment{{ContentID: "a1", Filename: "a.pdf", ContentType: "application/pdf"}},
+		}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "List attachments and inline files of an email", c.Short)
+	assert.Contains(t, buf.String(), `"filename": "a.pdf"`)
+	assert.Equal(t, 0, exitCode)
+
+	// download
+	buf.Reset()
+	var options command.DownloadAttachmentsOptions
+	commandDownloadAttachments = func(_ context.Context, o command.DownloadAttachmentsOptions) (*command.DownloadAttachmentsResult, error) {
+		options = o
+		return &command.DownloadAttachmentsResult{MessageID: "message-id"}, nil
+	}
+	rootCmd.SetArgs([]string{"attachments", "download", "message-id", "--all", "--dir", "out"})
+	c, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Download attachments and inline files of an email", c.Short)
+	assert.Equal(t, command.DownloadAttachmentsOptions{Retries: 2, MessageID: "message-id", All: true, Dir: "out"}, options)
+	assert.Equal(t, 0, exitCode)
+
+	// error
+	buf.Reset()
+	commandDownloadAttachments = func(_ context.Context, _ command.DownloadAttachmentsOptions) (*command.DownloadAttachmentsResult, error) {
+		return nil, errors.New("error")
+	}
+	rootCmd.SetArgs([]string{"attachments", "download", "message-id", "--all=false", "--name", "a.pdf"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
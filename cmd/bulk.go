@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/harryzcy/mailbox-cli/internal/bulk"
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var commandSelect = command.Select
+
+// The number of emails selected by --where above which a confirmation is required, and the number previewed
+const (
+	whereConfirmThreshold = 10
+	wherePreview          = 10
+)
+
+var errAborted = errors.New("aborted, pass --yes to confirm without a prompt")
+
+// messageIDArgs requires message IDs as arguments, unless the emails are selected by --where
+func messageIDArgs(cmd *cobra.Command, args []string) error {
+	if where := cmd.Flag("where"); where != nil && where.Value.String() != "" {
+		if len(args) > 0 {
+			return errors.New("message IDs can't be combined with --where")
+		}
+		return nil
+	}
+	return cobra.MinimumNArgs(1)(cmd, args)
+}
+
+// isBulk reports whether the arguments name several emails or stdin, which prints a summary instead of a single result
+func isBulk(args []string) bool {
+	return len(args) > 1 || args[0] == bulk.Stdin
+}
+
+// bulkMessageIDs returns the message IDs selected by --where, or given as several arguments or on stdin.
+// It returns nil for a single message ID argument, which is handled on its own.
+// The action, such as "Trash", is used to confirm a large selection.
+func bulkMessageIDs(ctx context.Context, cmd *cobra.Command, args []string, action string, client clientSettings, verbose bool) ([]string, error) {
+	where := cmd.Flag("where")
+	if where == nil || where.Value.String() == "" {
+		if isBulk(args) {
+			return bulk.MessageIDs(args, cmd.InOrStdin())
+		}
+		return nil, nil
+	}
+
+	months, err := cmd.Flags().GetInt("months")
+	if err != nil {
+		return nil, err
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := commandSelect(ctx, command.SelectOptions{
+		APIID:    client.APIID,
+		Region:   client.Region,
+		Endpoint: client.Endpoint,
+		Retries:  client.Retries,
+		Verbose:  verbose,
+
+		Where:  where.Value.String(),
+		Type:   cmd.Flag("type").Value.String(),
+		Months: months,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := previewSelection(cmd, result); err != nil {
+		return nil, err
+	}
+	if len(result.Items) > whereConfirmThreshold && !yes {
+		if err := confirm(cmd, fmt.Sprintf("%s %d emails? [y/N] ", action, len(result.Items))); err != nil {
+			return nil, err
+		}
+	}
+
+	messageIDs := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		messageIDs[i] = item.MessageID
+	}
+	return messageIDs, nil
+}
+
+// previewSelection writes the number of selected emails and the newest of them to stderr
+func previewSelection(cmd *cobra.Command, result *email.ListResult) error {
+	if len(result.Items) == 0 {
+		cmd.PrintErrln("No emails selected")
+		return nil
+	}
+
+	cmd.PrintErrf("Selected %d emails:\n", len(result.Items))
+	preview := &email.ListResult{Items: result.Items[:min(len(result.Items), wherePreview)]}
+	if err := output.Write(cmd.ErrOrStderr(), output.Format{Name: output.FormatTable}, preview); err != nil {
+		return err
+	}
+	if more := len(result.Items) - len(preview.Items); more > 0 {
+		cmd.PrintErrf("... and %d more\n", more)
+	}
+	return nil
+}
+
+// confirm prompts on stderr and reads the answer from stdin, returning errAborted unless it's yes
+func confirm(cmd *cobra.Command, prompt string) error {
+	cmd.PrintErr(prompt)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		// without an interactive stdin, nobody can confirm
+		cmd.PrintErrln()
+		return errAborted
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+// runBulk runs the operation on each message ID and prints the summary,
+// exiting with 1 if any operation failed
+func runBulk(ctx context.Context, cmd *cobra.Command, messageIDs []string, operation func(ctx context.Context, messageID string) (any, error)) {
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		cmd.PrintErrln(err)
+		osExit(1)
+		return
+	}
+
+	result := bulk.Run(ctx, bulk.Options{MessageIDs: messageIDs, Concurrency: concurrency}, operation)
+	if err := printResult(cmd, result); err != nil {
+		cmd.PrintErrln(err)
+		osExit(1)
+		return
+	}
+	if result.Failed > 0 {
+		osExit(1)
+	}
+}
+
+// addBulkFlags adds the flags of commands accepting several message IDs
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("concurrency", command.DefaultConcurrency, "Maximum number of concurrent requests with several message IDs")
+}
+
+// addWhereFlags adds the flags of commands selecting emails by --where, in addition to addBulkFlags
+func addWhereFlags(cmd *cobra.Command) {
+	cmd.Flags().String("where", "", "Select the emails by a query such as 'from:alerts@example.com before:2025-01-01' instead of message IDs")
+	cmd.Flags().String("type", "", "Type of emails selected by --where: inbox (default), sent or draft")
+	cmd.Flags().Int("months", command.DefaultSelectMonths, "Number of months listed by --where, unless it has since: or after:")
+	cmd.Flags().Bool("yes", false, fmt.Sprintf("Skip the confirmation when --where selects more than %d emails", whereConfirmThreshold))
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandCompose = command.Compose
+
+// composeCmd represents the compose command
+var composeCmd = &cobra.Command{
+	Use:   "compose",
+	Short: "Compose a draft in an editor",
+	Long: `Compose a draft in $VISUAL or $EDITOR (vi if neither is set).
+
+The editor opens a header block (From, To, Cc, Bcc, Reply-To, Subject) followed by a blank line and the body.
+The body is plain text, or Markdown rendered to both the text and HTML body with --markdown.
+Saving an empty file aborts. If the draft is invalid or can't be created, the edited file is kept for recovery.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		subject, err := cmd.Flags().GetString("subject")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		from, err := cmd.Flags().GetStringArray("from")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		to, err := cmd.Flags().GetStringArray("to")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		cc, err := cmd.Flags().GetStringArray("cc")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		bcc, err := cmd.Flags().GetStringArray("bcc")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		replyTo, err := cmd.Flags().GetStringArray("reply-to")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		send, err := cmd.Flags().GetBool("send")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		isMarkdown, err := cmd.Flags().GetBool("markdown")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlineCSS, err := cmd.Flags().GetBool("inline-css")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandCompose(ctx, command.ComposeOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Subject: subject,
+			From:    from,
+			To:      to,
+			Cc:      cc,
+			Bcc:     bcc,
+			ReplyTo: replyTo,
+			Send:    send,
+
+			Markdown:  isMarkdown,
+			InlineCSS: inlineCSS,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(composeCmd)
+	composeCmd.Flags().String("subject", "", "Subject to prefill")
+	composeCmd.Flags().StringArray("from", []string{}, "From to prefill")
+	composeCmd.Flags().StringArray("to", []string{}, "To to prefill")
+	composeCmd.Flags().StringArray("cc", []string{}, "Cc to prefill")
+	composeCmd.Flags().StringArray("bcc", []string{}, "Bcc to prefill")
+	composeCmd.Flags().StringArray("reply-to", []string{}, "Reply-To to prefill")
+	composeCmd.Flags().Bool("send", false, "Send email immediately without using draft (optional)")
+	composeCmd.Flags().Bool("markdown", false, "Render the body as Markdown")
+	composeCmd.Flags().Bool("inline-css", false, "Add inline styles to the HTML rendered from Markdown")
+}
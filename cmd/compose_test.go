@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"compose", "--to", "a@example.com", "--subject", "Hello"})
+
+	var options command.ComposeOptions
+	commandCompose = func(_ context.Context, o command.ComposeOptions) (*email.Email, error) {
+		options = o
+		return &email.Email{MessageID: "message-id", Subject: "Hello"}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Compose a draft in an editor", c.Short)
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\",\n  \"subject\": \"Hello\"\n}\n", buf.String())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, []string{"a@example.com"}, options.To)
+	assert.Equal(t, "Hello", options.Subject)
+
+	// error
+	buf.Reset()
+	commandCompose = func(_ context.Context, _ command.ComposeOptions) (*email.Email, error) {
+		return nil, errors.New("empty draft, aborting")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "empty draft, aborting\n", buf.String())
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage configuration profiles",
+	Long: `Manage configuration profiles stored in $XDG_CONFIG_HOME/mailbox-cli/config.yaml.
+
+Settings are resolved with the precedence: flag > environment variable > profile.
+The profile is selected by --profile, $MAILBOX_PROFILE, or the current profile, in that order.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:       "get key",
+	Short:     "Get a setting of the selected profile",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: config.Keys,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, _, err := loadConfig()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		name := cfg.ProfileName(cmd.Flag("profile").Value.String())
+		value, err := cfg.Profile(name).Get(args[0])
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		cmd.Println(value)
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:       "set key value",
+	Short:     "Set a setting of the selected profile",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: config.Keys,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, path, err := loadConfig()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		name := cfg.ProfileName(cmd.Flag("profile").Value.String())
+		profile := cfg.Profile(name)
+		if err := profile.Set(args[0], args[1]); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		cfg.SetProfile(name, profile)
+		if cfg.CurrentProfile == "" {
+			cfg.CurrentProfile = name
+		}
+
+		if err := cfg.Save(path); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+	},
+}
+
+// configListCmd represents the config list command
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles and their settings",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		cfg, _, err := loadConfig()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		current := cfg.ProfileName("")
+		for _, name := range cfg.ProfileNames() {
+			marker := " "
+			if name == current {
+				marker = "*"
+			}
+			cmd.Printf("%s %s\n", marker, name)
+
+			profile := cfg.Profile(name)
+			for _, key := range config.Keys {
+				value, _ := profile.Get(key)
+				if value != "" {
+					cmd.Printf("    %s: %s\n", key, value)
+				}
+			}
+		}
+	},
+}
+
+// configUseCmd represents the config use command
+var configUseCmd = &cobra.Command{
+	Use:   "use profile",
+	Short: "Set the current profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, path, err := loadConfig()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		if err := cfg.Use(args[0]); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		if err := cfg.Save(path); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+	},
+}
+
+func loadConfig() (*config.Config, string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, path, nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configUseCmd)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("MAILBOX_PROFILE", "")
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	rootCmd.SetArgs([]string{"config", "set", "api-id", "staging-id", "--profile", "staging"})
+	_, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	rootCmd.SetArgs([]string{"config", "set", "region", "us-east-1", "--profile", "production"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"config", "list", "--profile", ""})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "  production\n    region: us-east-1\n* staging\n    api-id: staging-id\n", buf.String())
+
+	rootCmd.SetArgs([]string{"config", "use", "production"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"config", "get", "region"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "us-east-1\n", buf.String())
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"config", "get", "api-id", "--profile", "staging"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "staging-id\n", buf.String())
+
+	// error
+	buf.Reset()
+	rootCmd.SetArgs([]string{"config", "use", "invalid"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "profile not found: invalid\n", buf.String())
+
+	buf.Reset()
+	exitCode = 0
+	rootCmd.SetArgs([]string{"config", "get", "invalid", "--profile", ""})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "invalid key: invalid\n", buf.String())
+}
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/contacts"
+	"github.com/spf13/cobra"
+)
+
+var commandHarvestContacts = command.HarvestContacts
+
+// contactsCmd represents the contacts command
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "Manage the local address book",
+	Long: `Manage the local address book stored in $XDG_CONFIG_HOME/mailbox-cli/contacts.yaml.
+
+Address flags of create, save, compose and forward expand @name to the contact with the alias,
+or to all contacts in the group, e.g. --to @oncall.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// contactsAddCmd represents the contacts add command
+var contactsAddCmd = &cobra.Command{
+	Use:   "add email",
+	Short: "Add a contact, or update the contact with the same email address",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		alias, err := cmd.Flags().GetString("alias")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		groups, err := cmd.Flags().GetStringArray("group")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		book, path, err := loadContacts()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		if _, err := book.Add(contacts.Contact{Name: name, Email: args[0], Alias: alias, Groups: groups}); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		if err := book.Save(path); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+	},
+}
+
+// contactsListCmd represents the contacts list command
+var contactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List contacts",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		group, err := cmd.Flags().GetString("group")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		book, _, err := loadContacts()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		list := book.Contacts
+		if group != "" {
+			list = book.Group(group)
+		}
+		if list == nil {
+			list = []contacts.Contact{}
+		}
+
+		if err := printResult(cmd, contacts.List(list)); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// contactsRemoveCmd represents the contacts remove command
+var contactsRemoveCmd = &cobra.Command{
+	Use:   "remove email|@alias",
+	Short: "Remove a contact by email address or alias",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		book, path, err := loadContacts()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		if _, err := book.Remove(args[0]); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		if err := book.Save(path); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+	},
+}
+
+// contactsImportCmd represents the contacts import command
+var contactsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import contacts from a vCard 3.0 or 4.0 file",
+	Long: `Import contacts from a vCard 3.0 or 4.0 file.
+
+Each email address of a card becomes a contact with the card's full name.
+The first nickname becomes the alias of the preferred address, and categories become groups.
+A nickname that isn't a valid alias, or is already taken, is skipped with a warning, and the contact imported without it.
+Existing contacts with the same email address are updated.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		file, err := cmd.Flags().GetString("vcard")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		list, err := contacts.ReadVCards(bytes.NewReader(data))
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		book, path, err := loadContacts()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		result := book.Import(list)
+		if err := book.Save(path); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		for _, skipped := range result.SkippedAliases {
+			cmd.PrintErrf("Imported %s without its alias: %s\n", skipped.Email, skipped.Error)
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		if len(result.Skipped) > 0 {
+			osExit(1)
+		}
+	},
+}
+
+// contactsExportCmd represents the contacts export command
+var contactsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export contacts as vCards",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		file, err := cmd.Flags().GetString("vcard")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		version, err := cmd.Flags().GetString("vcard-version")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		group, err := cmd.Flags().GetString("group")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		book, _, err := loadContacts()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		list := book.Contacts
+		if group != "" {
+			list = book.Group(group)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := contacts.WriteVCards(buf, list, version); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		if file == "" || file == "-" {
+			_, err = cmd.OutOrStdout().Write(buf.Bytes())
+		} else {
+			err = os.WriteFile(file, buf.Bytes(), 0o600)
+		}
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+	},
+}
+
+// contactsHarvestCmd represents the contacts harvest command
+var contactsHarvestCmd = &cobra.Command{
+	Use:   "harvest",
+	Short: "Add the recipients of sent emails to the address book",
+	Args:  cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		year, err := cmd.Flags().GetString("year")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		month, err := cmd.Flags().GetString("month")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		group, err := cmd.Flags().GetString("group")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandHarvestContacts(ctx, command.HarvestContactsOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Year:  year,
+			Month: month,
+			Group: group,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func loadContacts() (*contacts.Book, string, error) {
+	path, err := contacts.Path()
+	if err != nil {
+		return nil, "", err
+	}
+	book, err := contacts.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return book, path, nil
+}
+
+func init() {
+	rootCmd.AddCommand(contactsCmd)
+
+	contactsCmd.AddCommand(contactsAddCmd)
+	contactsAddCmd.Flags().String("name", "", "Display name")
+	contactsAddCmd.Flags().String("alias", "", "Alias referenced as @alias in address flags")
+	contactsAddCmd.Flags().StringArray("group", []string{}, "Group referenced as @group in address flags (repeatable)")
+
+	contactsCmd.AddCommand(contactsListCmd)
+	contactsListCmd.Flags().String("group", "", "Only list the contacts in the group")
+
+	contactsCmd.AddCommand(contactsRemoveCmd)
+
+	contactsCmd.AddCommand(contactsImportCmd)
+	contactsImportCmd.Flags().String("vcard", "", "vCard file to import")
+	_ = contactsImportCmd.MarkFlagRequired("vcard")
+
+	contactsCmd.AddCommand(contactsExportCmd)
+	contactsExportCmd.Flags().String("vcard", "", "vCard file to write (default stdout)")
+	contactsExportCmd.Flags().String("vcard-version", contacts.VCardVersion4, "vCard version: 3.0 or 4.0")
+	contactsExportCmd.Flags().String("group", "", "Only export the contacts in the group")
+
+	contactsCmd.AddCommand(contactsHarvestCmd)
+	contactsHarvestCmd.Flags().String("year", "", "Only harvest emails sent in the year")
+	contactsHarvestCmd.Flags().String("month", "", "Only harvest emails sent in the month")
+	contactsHarvestCmd.Flags().String("group", "", "Add the harvested contacts to the group")
+}
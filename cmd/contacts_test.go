@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/contacts"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContacts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	rootCmd.SetArgs([]string{"contacts", "add", "alice@example.com", "--name", "Alice", "--alias", "alice", "--group", "oncall"})
+	_, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	// string array flags keep their values between executions
+	err = contactsAddCmd.Flags().Lookup("group").Value.(pflag.SliceValue).Replace(nil)
+	assert.Nil(t, err)
+	rootCmd.SetArgs([]string{"contacts", "add", "bob@example.com", "--name", "", "--alias", "", "--group", "team"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"contacts", "list", "--group", "oncall", "-o", "table"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "NAME   EMAIL              ALIAS  GROUPS\nAlice  alice@example.com  alice  oncall\n", buf.String())
+
+	// invalid alias
+	buf.Reset()
+	rootCmd.SetArgs([]string{"contacts", "add", "carol@example.com", "--alias", "on call"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, buf.String(), "invalid contact")
+
+	// export and import
+	exitCode = 0
+	dir := t.TempDir()
+	vcard := filepath.Join(dir, "contacts.vcf")
+	rootCmd.SetArgs([]string{"contacts", "export", "--vcard", vcard, "--vcard-version", "3.0", "--group", ""})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+	data, err := os.ReadFile(vcard)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "EMAIL;TYPE=INTERNET:bob@example.com\r\n")
+
+	rootCmd.SetArgs([]string{"contacts", "remove", "@alice"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"contacts", "import", "--vcard", vcard, "-o", "json"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, buf.String(), `"added": 1`)
+	assert.Contains(t, buf.String(), `"updated": 1`)
+
+	path, err := contacts.Path()
+	assert.Nil(t, err)
+	book, err := contacts.Load(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []contacts.Contact{
+		{Name: "bob@example.com", Email: "bob@example.com", Groups: []string{"team"}},
+		{Name: "Alice", Email: "alice@example.com", Alias: "alice", Groups: []string{"oncall"}},
+	}, book.Contacts)
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"contacts", "remove", "unknown@example.com"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, buf.String(), "contact not found")
+}
+
+func TestContactsHarvest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"contacts", "harvest", "--year", "2025", "--month", "01", "--group", "sent", "-o", "json"})
+
+	var options command.HarvestContactsOptions
+	commandHarvestContacts = func(_ context.Context, o command.HarvestContactsOptions) (*command.HarvestContactsResult, error) {
+		options = o
+		return &command.HarvestContactsResult{
+			Added:    1,
+			Contacts: []contacts.Contact{{Email: "bob@example.com"}},
+		}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Add the recipients of sent emails to the address book", c.Short)
+	assert.Equal(t, command.HarvestContactsOptions{Retries: 2, Year: "2025", Month: "01", Group: "sent"}, options)
+	assert.Contains(t, buf.String(), `"added": 1`)
+	assert.Equal(t, 0, exitCode)
+
+	// error
+	buf.Reset()
+	commandHarvestContacts = func(_ context.Context, _ command.HarvestContactsOptions) (*command.HarvestContactsResult, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
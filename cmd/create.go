@@ -73,11 +73,52 @@ var createCmd = &cobra.Command{
 			cmd.PrintErrln(err)
 			osExit(1)
 		}
+		attachments, err := cmd.Flags().GetStringArray("attach")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlines, err := cmd.Flags().GetStringArray("inline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		markdownFile, err := cmd.Flags().GetString("markdown")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlineCSS, err := cmd.Flags().GetBool("inline-css")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		templateFile, err := cmd.Flags().GetString("template")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		vars, err := cmd.Flags().GetStringArray("var")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
 
-		result, err := commandCreate(command.CreateOptions{
-			APIID:    cmd.Flag("api-id").Value.String(),
-			Region:   cmd.Flag("region").Value.String(),
-			Endpoint: cmd.Flag("endpoint").Value.String(),
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandCreate(ctx, command.CreateOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
 			Verbose:  verbose,
 
 			Subject:      subject,
@@ -90,16 +131,25 @@ var createCmd = &cobra.Command{
 			HTML:         html,
 			GenerateText: generateText,
 			Send:         send,
+			Attachments:  attachments,
+			Inlines:      inlines,
+			Markdown:     markdownFile,
+			InlineCSS:    inlineCSS,
+			Template:     templateFile,
+			Vars:         vars,
 
 			File: file,
 		})
 		if err != nil {
 			cmd.PrintErrln(err)
-			osExit(1)
+			osExit(exitCode(err))
 			return
 		}
 
-		cmd.Println(result)
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
 	},
 }
 
@@ -116,4 +166,10 @@ func init() {
 	createCmd.Flags().String("generate-text", "", "Generate text from HTML (optional)")
 	createCmd.Flags().String("file", "", "File")
 	createCmd.Flags().Bool("send", false, "Send email immediately without using draft (optional)")
+	createCmd.Flags().StringArray("attach", []string{}, "Attach a file, in the format of path[;type=...;name=...] (repeatable)")
+	createCmd.Flags().StringArray("inline", []string{}, "Attach an inline file referenced by cid, in the format of cid=path[;type=...] (repeatable)")
+	createCmd.Flags().String("markdown", "", "Markdown file rendered to both the text and HTML body")
+	createCmd.Flags().Bool("inline-css", false, "Add inline styles to the HTML rendered from Markdown")
+	createCmd.Flags().String("template", "", "YAML template with Go template placeholders; other flags override its fields")
+	createCmd.Flags().StringArray("var", []string{}, "Template variable, in the format of key=value (repeatable)")
 }
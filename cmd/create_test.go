@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +17,8 @@ func TestCreate(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"create"})
 
-	commandCreate = func(options command.CreateOptions) (string, error) {
-		return "result", nil
+	commandCreate = func(_ context.Context, options command.CreateOptions) (*email.Email, error) {
+		return &email.Email{MessageID: "message-id", Subject: "subject"}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,7 +26,7 @@ func TestCreate(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "Create an email", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\",\n  \"subject\": \"subject\"\n}\n", buf.String())
 	assert.Equal(t, 0, exitCode)
 
 	// error
@@ -34,12 +36,38 @@ func TestCreate(t *testing.T) {
 	assert.NotNil(t, err)
 
 	buf.Reset()
-	commandCreate = func(_ command.CreateOptions) (string, error) {
-		return "result", errors.New("error")
+	commandCreate = func(_ context.Context, _ command.CreateOptions) (*email.Email, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"create"})
 	_, err = rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, 1, exitCode)
 	assert.Equal(t, "error\n", buf.String())
+
+	// attachments
+	var options command.CreateOptions
+	commandCreate = func(_ context.Context, o command.CreateOptions) (*email.Email, error) {
+		options = o
+		return &email.Email{}, nil
+	}
+	rootCmd.SetArgs([]string{"create", "--attach", "a.pdf", "--attach", "b.bin;type=application/pdf", "--inline", "logo=logo.png"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.pdf", "b.bin;type=application/pdf"}, options.Attachments)
+	assert.Equal(t, []string{"logo=logo.png"}, options.Inlines)
+
+	// markdown
+	rootCmd.SetArgs([]string{"create", "--markdown", "notice.md", "--inline-css"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "notice.md", options.Markdown)
+	assert.True(t, options.InlineCSS)
+
+	// template
+	rootCmd.SetArgs([]string{"create", "--markdown", "", "--inline-css=false", "--template", "welcome.yaml", "--var", "name=Tom", "--var", "email=tom@example.com"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "welcome.yaml", options.Template)
+	assert.Equal(t, []string{"name=Tom", "email=tom@example.com"}, options.Vars)
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/archive"
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/spf13/cobra"
+)
+
+var commandExport = command.Export
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export emails to mbox, Maildir or .eml files",
+	Long: `Export emails to mbox, Maildir or .eml files.
+
+The exported message IDs are recorded next to the destination,
+so running an interrupted export again continues where it stopped.
+An mbox is first truncated to the end of the last recorded message, dropping a partially written one.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+		result, err := commandExport(ctx, command.ExportOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Type:  cmd.Flag("type").Value.String(),
+			Year:  cmd.Flag("year").Value.String(),
+			Month: cmd.Flag("month").Value.String(),
+
+			Format: cmd.Flag("format").Value.String(),
+			Dest:   cmd.Flag("dest").Value.String(),
+			Progress: func(e email.Email) {
+				cmd.PrintErrf("Exported %s\n", e.MessageID)
+			},
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("type", email.EmailTypeInbox, "Type")
+	exportCmd.Flags().String("year", "", "Year")
+	exportCmd.Flags().String("month", "", "Month")
+	exportCmd.Flags().String("format", archive.FormatMbox, "Format: mbox, maildir or eml")
+	exportCmd.Flags().String("dest", "", "Destination file (mbox) or directory (maildir, eml)")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"export", "--year", "2025", "--format", "maildir", "--dest", "out"})
+
+	var options command.ExportOptions
+	commandExport = func(_ context.Context, o command.ExportOptions) (*command.ExportResult, error) {
+		options = o
+		o.Progress(email.Email{MessageID: "message-id"})
+		return &command.ExportResult{Format: o.Format, Dest: o.Dest, Exported: 1}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Export emails to mbox, Maildir or .eml files", c.Short)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "inbox", options.Type)
+	assert.Equal(t, "2025", options.Year)
+	assert.Equal(t, "maildir", options.Format)
+	assert.Equal(t, "out", options.Dest)
+	assert.Contains(t, buf.String(), "Exported message-id\n")
+	assert.Contains(t, buf.String(), `"exported": 1`)
+
+	// error
+	buf.Reset()
+	commandExport = func(_ context.Context, _ command.ExportOptions) (*command.ExportResult, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
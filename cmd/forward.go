@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandForward = command.Forward
+
+// forwardCmd represents the forward command
+var forwardCmd = &cobra.Command{
+	Use:   "forward messageID",
+	Short: "Forward an email",
+	Long:  `Forward an email by creating a draft with the original email and its attachments included.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		from, err := cmd.Flags().GetStringArray("from")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		to, err := cmd.Flags().GetStringArray("to")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		cc, err := cmd.Flags().GetStringArray("cc")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		bcc, err := cmd.Flags().GetStringArray("bcc")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		text, err := cmd.Flags().GetString("text")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		html, err := cmd.Flags().GetString("html")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		generateText, err := cmd.Flags().GetString("generate-text")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		send, err := cmd.Flags().GetBool("send")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		attachments, err := cmd.Flags().GetStringArray("attach")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlines, err := cmd.Flags().GetStringArray("inline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandForward(ctx, command.ForwardOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			MessageID:    args[0],
+			From:         from,
+			To:           to,
+			Cc:           cc,
+			Bcc:          bcc,
+			Text:         text,
+			HTML:         html,
+			GenerateText: generateText,
+			Send:         send,
+			Attachments:  attachments,
+			Inlines:      inlines,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forwardCmd)
+	forwardCmd.Flags().StringArray("from", []string{}, "From (defaults to the address the email was sent to)")
+	forwardCmd.Flags().StringArray("to", []string{}, "To")
+	forwardCmd.Flags().StringArray("cc", []string{}, "Cc")
+	forwardCmd.Flags().StringArray("bcc", []string{}, "Bcc")
+	forwardCmd.Flags().String("text", "", "Text")
+	forwardCmd.Flags().String("html", "", "HTML")
+	forwardCmd.Flags().String("generate-text", "", "Generate text from HTML (optional)")
+	forwardCmd.Flags().Bool("send", false, "Send email immediately without using draft (optional)")
+	forwardCmd.Flags().StringArray("attach", []string{}, "Attach a file, in the format of path[;type=...;name=...] (repeatable)")
+	forwardCmd.Flags().StringArray("inline", []string{}, "Attach an inline file referenced by cid, in the format of cid=path[;type=...] (repeatable)")
+}
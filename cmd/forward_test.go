@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForward(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"forward", "message-id", "--to", "a@example.com", "--to", "b@example.com"})
+
+	var options command.ForwardOptions
+	commandForward = func(_ context.Context, o command.ForwardOptions) (*email.Email, error) {
+		options = o
+		return &email.Email{MessageID: "forward-id", Subject: "Fwd: subject"}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Forward an email", c.Short)
+	assert.Equal(t, "{\n  \"messageID\": \"forward-id\",\n  \"subject\": \"Fwd: subject\"\n}\n", buf.String())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "message-id", options.MessageID)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, options.To)
+
+	// error
+	buf.Reset()
+	commandForward = func(_ context.Context, _ command.ForwardOptions) (*email.Email, error) {
+		return nil, errors.New("error")
+	}
+	rootCmd.SetArgs([]string{"forward", "message-id"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
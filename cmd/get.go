@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/harryzcy/mailbox-cli/internal/command"
 	"github.com/spf13/cobra"
 )
@@ -9,36 +11,76 @@ var commandGet = command.Get
 
 // getCmd represents the get command
 var getCmd = &cobra.Command{
-	Use:   "get messageID",
+	Use:   "get messageID...",
 	Short: "Get an email by messageID",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		messageID := args[0]
+	Long: `Get emails by message ID.
 
+With several message IDs, or - to read them newline-delimited from stdin, the emails are processed concurrently
+and a summary reports the status of each ID. The exit code is 1 if any of them failed.`,
+	Args: messageIDArgs,
+	Run: func(cmd *cobra.Command, args []string) {
 		verbose, err := cmd.Flags().GetBool("verbose")
 		if err != nil {
 			cmd.PrintErrln(err)
 			osExit(1)
 		}
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
 
-		result, err := commandGet(command.GetOptions{
-			APIID:    cmd.Flag("api-id").Value.String(),
-			Region:   cmd.Flag("region").Value.String(),
-			Endpoint: cmd.Flag("endpoint").Value.String(),
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		options := command.GetOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
 			Verbose:  verbose,
 
-			MessageID: messageID,
-		})
+			Offline: offline,
+		}
+		messageIDs, err := bulkMessageIDs(ctx, cmd, args, "Get", client, verbose)
 		if err != nil {
 			cmd.PrintErrln(err)
-			osExit(1)
+			osExit(exitCode(err))
+			return
+		}
+		if messageIDs != nil {
+			runBulk(ctx, cmd, messageIDs, func(ctx context.Context, messageID string) (any, error) {
+				options := options
+				options.MessageID = messageID
+				return commandGet(ctx, options)
+			})
 			return
 		}
 
-		cmd.Println(result)
+		options.MessageID = args[0]
+		result, err := commandGet(ctx, options)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(getCmd)
+	addBulkFlags(getCmd)
+	getCmd.Flags().Bool("offline", false, "Get from the offline cache populated by sync")
 }
@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/harryzcy/mailbox-cli/internal/cache"
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +21,8 @@ func TestGet(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"get", "message-id"})
 
-	commandGet = func(_ command.GetOptions) (string, error) {
-		return "result", nil
+	commandGet = func(_ context.Context, _ command.GetOptions) (*email.Email, error) {
+		return &email.Email{MessageID: "message-id", Subject: "subject"}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,7 +30,7 @@ func TestGet(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "Get an email by messageID", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\",\n  \"subject\": \"subject\"\n}\n", buf.String())
 
 	// error
 	buf.Reset()
@@ -33,12 +39,67 @@ func TestGet(t *testing.T) {
 	assert.NotNil(t, err)
 
 	buf.Reset()
-	commandGet = func(_ command.GetOptions) (string, error) {
-		return "result", errors.New("error")
+	commandGet = func(_ context.Context, _ command.GetOptions) (*email.Email, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"get", "message-id"})
 	_, err = rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, 1, exitCode)
 	assert.Equal(t, "error\n", buf.String())
+
+	buf.Reset()
+	commandGet = func(_ context.Context, _ command.GetOptions) (*email.Email, error) {
+		return nil, &email.APIError{StatusCode: http.StatusNotFound, Message: "email not found"}
+	}
+	rootCmd.SetArgs([]string{"get", "message-id"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, exitCodeNotFound, exitCode)
+	assert.Equal(t, "api error: 404 Not Found: email not found\n", buf.String())
+
+	// offline
+	buf.Reset()
+	var options command.GetOptions
+	commandGet = func(_ context.Context, o command.GetOptions) (*email.Email, error) {
+		options = o
+		return nil, fmt.Errorf("%w: message-id", cache.ErrNotCached)
+	}
+	rootCmd.SetArgs([]string{"get", "message-id", "--offline"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.True(t, options.Offline)
+	assert.Equal(t, exitCodeNotFound, exitCode)
+	assert.Equal(t, "not found in the offline cache: message-id\n", buf.String())
+	rootCmd.SetArgs([]string{"get", "message-id", "--offline=false"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+}
+
+func TestGet_Bulk(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetIn(strings.NewReader("id-1\nid-2\n"))
+	rootCmd.SetArgs([]string{"get", "-", "--offline", "-o", "ndjson"})
+
+	commandGet = func(_ context.Context, o command.GetOptions) (*email.Email, error) {
+		assert.True(t, o.Offline)
+		return &email.Email{MessageID: o.MessageID, Subject: "subject"}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	_, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"messageID":"id-1","status":"ok","result":{"messageID":"id-1","subject":"subject"}}`+"\n"+
+		`{"messageID":"id-2","status":"ok","result":{"messageID":"id-2","subject":"subject"}}`+"\n", buf.String())
+	assert.Equal(t, 0, exitCode)
+
+	commandGet = func(_ context.Context, o command.GetOptions) (*email.Email, error) {
+		return &email.Email{MessageID: o.MessageID}, nil
+	}
+	rootCmd.SetArgs([]string{"get", "id-1", "--offline=false", "-o", "json"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/archive"
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandImport = command.Import
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import path",
+	Short: "Import .eml or mbox files as drafts",
+	Long: `Import .eml or mbox files as drafts.
+
+For the eml format, path is a .eml file or a directory containing .eml files.
+For the mbox format, path is an mbox file.
+Each message is reported separately; the exit code is 1 if any message failed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+		result, err := commandImport(ctx, command.ImportOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Format: cmd.Flag("format").Value.String(),
+			Path:   args[0],
+		})
+		if result != nil {
+			if err := printResult(cmd, result); err != nil {
+				cmd.PrintErrln(err)
+				osExit(1)
+				return
+			}
+		}
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+		if result.Failed > 0 {
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().String("format", archive.FormatEML, "Format: eml or mbox")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"import", "--format", "mbox", "templates.mbox"})
+
+	var options command.ImportOptions
+	commandImport = func(_ context.Context, o command.ImportOptions) (*command.ImportResult, error) {
+		options = o
+		return &command.ImportResult{
+			Imported: 1,
+			Messages: []command.ImportedMessage{{Source: "templates.mbox#1", MessageID: "draft-id"}},
+		}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Import .eml or mbox files as drafts", c.Short)
+	assert.Equal(t, command.ImportOptions{Retries: 2, Format: "mbox", Path: "templates.mbox"}, options)
+	assert.Contains(t, buf.String(), `"messageID": "draft-id"`)
+	assert.Equal(t, 0, exitCode)
+
+	// partial failure
+	buf.Reset()
+	commandImport = func(_ context.Context, _ command.ImportOptions) (*command.ImportResult, error) {
+		return &command.ImportResult{
+			Failed:   1,
+			Messages: []command.ImportedMessage{{Source: "a.eml", Error: "invalid message"}},
+		}, nil
+	}
+	rootCmd.SetArgs([]string{"import", "--format", "eml", "a.eml"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, buf.String(), `"error": "invalid message"`)
+
+	// error
+	buf.Reset()
+	exitCode = 0
+	commandImport = func(_ context.Context, _ command.ImportOptions) (*command.ImportResult, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
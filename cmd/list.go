@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/thread"
 	"github.com/spf13/cobra"
 )
 
@@ -19,10 +20,42 @@ var listCmd = &cobra.Command{
 			osExit(1)
 		}
 
-		result, err := commandList(command.ListOptions{
-			APIID:    cmd.Flag("api-id").Value.String(),
-			Region:   cmd.Flag("region").Value.String(),
-			Endpoint: cmd.Flag("endpoint").Value.String(),
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		threads, err := cmd.Flags().GetBool("threads")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandList(ctx, command.ListOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
 			Verbose:  verbose,
 
 			Type:       cmd.Flag("type").Value.String(),
@@ -30,14 +63,26 @@ var listCmd = &cobra.Command{
 			Month:      cmd.Flag("month").Value.String(),
 			Order:      cmd.Flag("order").Value.String(),
 			NextCursor: cmd.Flag("next-cursor").Value.String(),
+
+			All:   all,
+			Limit: limit,
+
+			Offline: offline,
 		})
 		if err != nil {
 			cmd.PrintErrln(err)
-			osExit(1)
+			osExit(exitCode(err))
 			return
 		}
 
-		cmd.Println(result)
+		var output any = result
+		if threads {
+			output = thread.Group(result.Items)
+		}
+		if err := printResult(cmd, output); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
 	},
 }
 
@@ -48,4 +93,8 @@ func init() {
 	listCmd.Flags().String("month", "", "Month")
 	listCmd.Flags().String("order", "", "Order")
 	listCmd.Flags().String("next-cursor", "", "Next Cursor")
+	listCmd.Flags().Bool("all", false, "Follow cursors until all emails are listed")
+	listCmd.Flags().Int("limit", 0, "Stop after listing this many emails, following cursors if needed")
+	listCmd.Flags().Bool("offline", false, "List from the offline cache populated by sync; year and month are optional")
+	listCmd.Flags().Bool("threads", false, "Group the listed emails by thread, with counts")
 }
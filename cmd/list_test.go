@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/output"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +18,8 @@ func TestList(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"list"})
 
-	commandList = func(_ command.ListOptions) (string, error) {
-		return "result", nil
+	commandList = func(_ context.Context, _ command.ListOptions) (*email.ListResult, error) {
+		return &email.ListResult{Count: 1, Items: []email.Email{{MessageID: "message-id"}}}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,16 +27,72 @@ func TestList(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "List emails", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"count\": 1,\n  \"items\": [\n    {\n      \"messageID\": \"message-id\",\n      \"subject\": \"\"\n    }\n  ]\n}\n", buf.String())
 
 	// error
 	buf.Reset()
-	commandList = func(_ command.ListOptions) (string, error) {
-		return "result", errors.New("error")
+	commandList = func(_ context.Context, _ command.ListOptions) (*email.ListResult, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"list"})
 	_, err = rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, 1, exitCode)
 	assert.Equal(t, "error\n", buf.String())
+
+	// pagination
+	var options command.ListOptions
+	commandList = func(_ context.Context, o command.ListOptions) (*email.ListResult, error) {
+		options = o
+		return &email.ListResult{}, nil
+	}
+	rootCmd.SetArgs([]string{"list", "--type", "inbox", "--all", "--limit", "10"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.True(t, options.All)
+	assert.Equal(t, 10, options.Limit)
+	assert.False(t, options.Offline)
+
+	// offline
+	rootCmd.SetArgs([]string{"list", "--all=false", "--limit", "0", "--offline"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.True(t, options.Offline)
+	rootCmd.SetArgs([]string{"list", "--offline=false"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+
+	// output format
+	buf.Reset()
+	commandList = func(_ context.Context, _ command.ListOptions) (*email.ListResult, error) {
+		return &email.ListResult{Count: 1, Items: []email.Email{{MessageID: "message-id"}}}, nil
+	}
+	rootCmd.SetArgs([]string{"list", "--all=false", "--limit", "0", "-o", "jsonpath={.items[*].messageID}"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "message-id\n", buf.String())
+
+	// threads
+	buf.Reset()
+	commandList = func(_ context.Context, _ command.ListOptions) (*email.ListResult, error) {
+		return &email.ListResult{Count: 2, Items: []email.Email{
+			{MessageID: "2", Subject: "Re: Hello"},
+			{MessageID: "1", Subject: "Hello"},
+		}}, nil
+	}
+	rootCmd.SetArgs([]string{"list", "--threads", "-o", "jsonpath={.items[0].count}"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", buf.String())
+	rootCmd.SetArgs([]string{"list", "--threads=false", "-o", "json"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"list", "-o", "xml"})
+	_, err = rootCmd.ExecuteC()
+	assert.ErrorIs(t, err, output.ErrInvalidFormat)
+	rootCmd.SetArgs([]string{"list", "-o", "json"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
 }
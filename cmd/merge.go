@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandMerge = command.Merge
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Create one email per CSV row from a template",
+	Long: `Create one email per CSV row from a template.
+
+The header row of the CSV file names the variables available to the template, such as {{.name}}.
+Each row is reported separately; the exit code is 1 if any row failed.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		templateFile, err := cmd.Flags().GetString("template")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		data, err := cmd.Flags().GetString("data")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		send, err := cmd.Flags().GetBool("send")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		concurrency, err := cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandMerge(ctx, command.MergeOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Template:    templateFile,
+			Data:        data,
+			Send:        send,
+			DryRun:      dryRun,
+			Concurrency: concurrency,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		if result.Failed > 0 {
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().String("template", "", "YAML template with Go template placeholders")
+	mergeCmd.Flags().String("data", "", "CSV file with a header row naming the variables")
+	mergeCmd.Flags().Bool("send", false, "Send the emails immediately without using drafts (optional)")
+	mergeCmd.Flags().Bool("dry-run", false, "Render the emails without creating them")
+	mergeCmd.Flags().Int("concurrency", command.DefaultConcurrency, "Maximum number of concurrent requests")
+	_ = mergeCmd.MarkFlagRequired("template")
+	_ = mergeCmd.MarkFlagRequired("data")
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"merge", "--template", "welcome.yaml", "--data", "recipients.csv", "--dry-run"})
+
+	var options command.MergeOptions
+	commandMerge = func(_ context.Context, o command.MergeOptions) (*command.MergeResult, error) {
+		options = o
+		return &command.MergeResult{
+			Rows: []command.MergedRow{{Row: 1, To: []string{"tom@example.com"}, Subject: "Welcome Tom"}},
+		}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Create one email per CSV row from a template", c.Short)
+	assert.Equal(t, command.MergeOptions{
+		Retries:     2,
+		Template:    "welcome.yaml",
+		Data:        "recipients.csv",
+		DryRun:      true,
+		Concurrency: command.DefaultConcurrency,
+	}, options)
+	assert.Contains(t, buf.String(), `"subject": "Welcome Tom"`)
+	assert.Equal(t, 0, exitCode)
+
+	// partial failure
+	buf.Reset()
+	commandMerge = func(_ context.Context, o command.MergeOptions) (*command.MergeResult, error) {
+		options = o
+		return &command.MergeResult{
+			Created: 1,
+			Failed:  1,
+			Rows: []command.MergedRow{
+				{Row: 1, MessageID: "id-tom"},
+				{Row: 2, Error: "invalid recipient"},
+			},
+		}, nil
+	}
+	rootCmd.SetArgs([]string{"merge", "--template", "welcome.yaml", "--data", "recipients.csv", "--dry-run=false", "--send", "--concurrency", "8"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.True(t, options.Send)
+	assert.False(t, options.DryRun)
+	assert.Equal(t, 8, options.Concurrency)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, buf.String(), `"error": "invalid recipient"`)
+
+	// error
+	buf.Reset()
+	exitCode = 0
+	commandMerge = func(_ context.Context, _ command.MergeOptions) (*command.MergeResult, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// outputFormat returns the format selected by the --output flag
+func outputFormat(cmd *cobra.Command) (output.Format, error) {
+	return output.ParseFormat(cmd.Flag("output").Value.String())
+}
+
+// printResult writes the result to the command's output in the selected format
+func printResult(cmd *cobra.Command, result any) error {
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	return output.Write(cmd.OutOrStdout(), format, result)
+}
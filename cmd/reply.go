@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandReply = command.Reply
+
+// replyCmd represents the reply command
+var replyCmd = &cobra.Command{
+	Use:   "reply messageID",
+	Short: "Reply to an email",
+	Long: `Reply to an email by creating a draft with the original email quoted.
+
+The reply goes to the Reply-To or From addresses of the original email,
+and with --all also to its other recipients.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		from, err := cmd.Flags().GetStringArray("from")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		text, err := cmd.Flags().GetString("text")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		html, err := cmd.Flags().GetString("html")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		generateText, err := cmd.Flags().GetString("generate-text")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		send, err := cmd.Flags().GetBool("send")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		attachments, err := cmd.Flags().GetStringArray("attach")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlines, err := cmd.Flags().GetStringArray("inline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandReply(ctx, command.ReplyOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			MessageID:    args[0],
+			All:          all,
+			From:         from,
+			Text:         text,
+			HTML:         html,
+			GenerateText: generateText,
+			Send:         send,
+			Attachments:  attachments,
+			Inlines:      inlines,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replyCmd)
+	replyCmd.Flags().Bool("all", false, "Reply to all recipients")
+	replyCmd.Flags().StringArray("from", []string{}, "From (defaults to the address the email was sent to)")
+	replyCmd.Flags().String("text", "", "Text")
+	replyCmd.Flags().String("html", "", "HTML")
+	replyCmd.Flags().String("generate-text", "", "Generate text from HTML (optional)")
+	replyCmd.Flags().Bool("send", false, "Send email immediately without using draft (optional)")
+	replyCmd.Flags().StringArray("attach", []string{}, "Attach a file, in the format of path[;type=...;name=...] (repeatable)")
+	replyCmd.Flags().StringArray("inline", []string{}, "Attach an inline file referenced by cid, in the format of cid=path[;type=...] (repeatable)")
+}
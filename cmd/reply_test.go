@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReply(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"reply", "message-id", "--all", "--text", "Thanks", "--send"})
+
+	var options command.ReplyOptions
+	commandReply = func(_ context.Context, o command.ReplyOptions) (*email.Email, error) {
+		options = o
+		return &email.Email{MessageID: "reply-id", Subject: "Re: subject"}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Reply to an email", c.Short)
+	assert.Equal(t, "{\n  \"messageID\": \"reply-id\",\n  \"subject\": \"Re: subject\"\n}\n", buf.String())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "message-id", options.MessageID)
+	assert.True(t, options.All)
+	assert.True(t, options.Send)
+	assert.Equal(t, "Thanks", options.Text)
+
+	// error
+	buf.Reset()
+	rootCmd.SetArgs([]string{"reply"})
+	_, err = rootCmd.ExecuteC()
+	assert.NotNil(t, err)
+
+	buf.Reset()
+	commandReply = func(_ context.Context, _ command.ReplyOptions) (*email.Email, error) {
+		return nil, errors.New("error")
+	}
+	rootCmd.SetArgs([]string{"reply", "message-id"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
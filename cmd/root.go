@@ -1,17 +1,35 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/config"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/output"
+	"github.com/harryzcy/mailbox-cli/internal/search"
 	"github.com/spf13/cobra"
 )
 
-var osExit = os.Exit
+var (
+	osExit        = os.Exit
+	configResolve = config.Resolve
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "mailbox-cli",
 	Short: "Handle mailbox APIs from the command line.",
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		// validate the output format before any request is made
+		_, err := outputFormat(cmd)
+		return err
+	},
 	Run: func(cmd *cobra.Command, _ []string) {
 		err := cmd.Help()
 		if err != nil {
@@ -24,18 +42,106 @@ var rootCmd = &cobra.Command{
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		osExit(1)
 	}
 }
 
+// The exit codes of the CLI, based on the class of the error
+const (
+	exitCodeError      = 1
+	exitCodeAuth       = 3
+	exitCodeNotFound   = 4
+	exitCodeValidation = 5
+	exitCodeServer     = 6
+)
+
+// exitCode returns the exit code that corresponds to the error
+func exitCode(err error) int {
+	if errors.Is(err, email.ErrMissingCredentials) {
+		return exitCodeAuth
+	}
+	if errors.Is(err, email.ErrInvalidAddress) || errors.Is(err, search.ErrInvalidQuery) {
+		return exitCodeValidation
+	}
+	if errors.Is(err, cache.ErrNotCached) {
+		return exitCodeNotFound
+	}
+
+	var apiErr *email.APIError
+	if !errors.As(err, &apiErr) {
+		return exitCodeError
+	}
+	switch {
+	case apiErr.IsAuth():
+		return exitCodeAuth
+	case apiErr.IsNotFound():
+		return exitCodeNotFound
+	case apiErr.IsServer():
+		return exitCodeServer
+	case apiErr.IsValidation():
+		return exitCodeValidation
+	}
+	return exitCodeError
+}
+
+// commandContext returns the context of the command, bounded by the --timeout flag if set
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// clientSettings contains the settings used to construct the client
+type clientSettings struct {
+	config.Profile
+	Retries int
+}
+
+// clientConfig resolves the client settings from flags, environment variables and the config file
+func clientConfig(cmd *cobra.Command) (clientSettings, error) {
+	retries, err := cmd.Flags().GetInt("retries")
+	if err != nil {
+		return clientSettings{}, err
+	}
+
+	profile, err := configResolve(config.ResolveOptions{
+		Profile:  cmd.Flag("profile").Value.String(),
+		APIID:    cmd.Flag("api-id").Value.String(),
+		Region:   cmd.Flag("region").Value.String(),
+		Endpoint: cmd.Flag("endpoint").Value.String(),
+	})
+	if err != nil {
+		return clientSettings{}, err
+	}
+
+	return clientSettings{
+		Profile: profile,
+		Retries: retries,
+	}, nil
+}
+
 func init() {
 	rootCmd.SetOut(os.Stdout)
 	rootCmd.SetErr(os.Stderr)
 
+	rootCmd.PersistentFlags().String("profile", "", "Config profile (default from $MAILBOX_PROFILE or config file)")
 	rootCmd.PersistentFlags().String("api-id", "", "API ID")
 	rootCmd.PersistentFlags().String("region", "", "Region")
 	rootCmd.PersistentFlags().String("endpoint", "", "Endpoint")
+	rootCmd.PersistentFlags().Int("retries", 2, "Number of retries for throttled or transient failures of idempotent requests")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout of the whole command, e.g. 30s (default no timeout)")
+	rootCmd.PersistentFlags().StringP("output", "o", output.FormatJSON, "Output format: "+strings.Join(output.Formats, ", "))
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose mode")
 }
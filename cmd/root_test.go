@@ -2,11 +2,56 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/harryzcy/mailbox-cli/internal/config"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain isolates the tests from the configuration, cache and MAILBOX_* environment variables of the developer
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mailbox-cli-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir+"/config")
+	os.Setenv("XDG_CACHE_HOME", dir+"/cache")
+	for _, env := range []string{config.EnvProfile, config.EnvAPIID, config.EnvRegion, config.EnvEndpoint} {
+		os.Unsetenv(env)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestMain_Hermetic(t *testing.T) {
+	if os.Getenv(config.EnvProfile) != "" {
+		t.Fatalf("%s leaked into the tests", config.EnvProfile)
+	}
+
+	// run a command test in a fresh process, with a profile that doesn't exist
+	c := exec.Command(os.Args[0], "-test.run=^TestGet$", "-test.count=1")
+	c.Env = append(os.Environ(),
+		config.EnvProfile+"=nonexistent",
+		config.EnvAPIID+"=leaked-id",
+		config.EnvRegion+"=leaked-region",
+	)
+	out, err := c.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
+
 func TestRoot(t *testing.T) {
 	buf := new(bytes.Buffer)
 	rootCmd.SetOut(buf)
@@ -36,3 +81,45 @@ func TestExe(t *testing.T) {
 	Execute()
 	assert.Equal(t, 1, exitCode)
 }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{err: errors.New("error"), code: exitCodeError},
+		{err: email.ErrMissingCredentials, code: exitCodeAuth},
+		{err: &email.AddressError{Field: "to", Value: "bob@", Err: errors.New("no angle-addr")}, code: exitCodeValidation},
+		{err: &email.APIError{StatusCode: http.StatusUnauthorized}, code: exitCodeAuth},
+		{err: &email.APIError{StatusCode: http.StatusNotFound}, code: exitCodeNotFound},
+		{err: &email.APIError{StatusCode: http.StatusUnprocessableEntity}, code: exitCodeValidation},
+		{err: &email.APIError{StatusCode: http.StatusServiceUnavailable}, code: exitCodeServer},
+		{err: fmt.Errorf("wrapped: %w", &email.APIError{StatusCode: http.StatusForbidden}), code: exitCodeAuth},
+		{err: &email.APIError{StatusCode: http.StatusMultipleChoices}, code: exitCodeError},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.code, exitCode(test.err))
+		})
+	}
+}
+
+func TestCommandContext(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Duration("timeout", 0, "")
+
+	ctx, cancel := commandContext(cmd)
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	err := cmd.Flags().Set("timeout", "1m")
+	assert.Nil(t, err)
+	ctx, cancel = commandContext(cmd)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandApplyRules = command.ApplyRules
+
+// rulesCmd represents the rules command
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Apply filing rules to emails",
+	Long: `Apply filing rules to emails, defined in $XDG_CONFIG_HOME/mailbox-cli/rules.yaml:
+
+  rules:
+    - name: old notifications
+      match:
+        type: inbox            # inbox (default), sent or draft
+        from: notifications@   # substring of a From address
+        to: team@example.com   # substring of a To, Cc or Bcc address
+        subject: '^\[GitHub\]' # regular expression
+        olderThan: 30d         # or newerThan, such as 2w or 12h
+        hasAttachment: false
+      actions:
+        mark: read             # or unread
+        hook: ./archive.sh     # run with the email JSON on stdin
+        forward: {to: [me@example.com], text: FYI, send: true} # from defaults to the address the email was sent to
+        trash: true            # or delete: true
+
+Actions are applied in the order above, stopping at the first failure.
+An email that can't be fetched to check the rules is listed under errors, without stopping the other emails.
+Emails are fetched without changing their unread state, so --dry-run leaves the mailbox as it was.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		err := cmd.Help()
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// rulesApplyCmd represents the rules apply command
+var rulesApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the rules to the emails of the recent months",
+	Long: `Apply the rules to the emails of the recent months, reporting the emails matched by each rule.
+
+Each email is handled by the first rule it matches. The exit code is 1 if any action failed.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		months, err := cmd.Flags().GetInt("months")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandApplyRules(ctx, command.RulesOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			File:   file,
+			Months: months,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		if result.Failed > 0 {
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+
+	rulesCmd.AddCommand(rulesApplyCmd)
+	rulesApplyCmd.Flags().String("file", "", "Path of the rules file (default $XDG_CONFIG_HOME/mailbox-cli/rules.yaml)")
+	rulesApplyCmd.Flags().Int("months", command.DefaultRulesMonths, "Number of months to apply the rules to, including the current month")
+	rulesApplyCmd.Flags().Bool("dry-run", false, "Report the matching emails without applying the actions")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesApply(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"rules", "apply", "--file", "rules.yaml", "--months", "2", "--dry-run"})
+
+	var options command.RulesOptions
+	commandApplyRules = func(_ context.Context, o command.RulesOptions) (*command.RulesResult, error) {
+		options = o
+		return &command.RulesResult{
+			DryRun:  true,
+			Matched: 1,
+			Rules: []command.RuleReport{{Name: "spam", Matched: 1, Emails: []command.RuleEmail{
+				{MessageID: "id", Subject: "Spam", Actions: []string{"trash"}},
+			}}},
+		}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Apply the rules to the emails of the recent months", c.Short)
+	assert.Equal(t, command.RulesOptions{Retries: 2, File: "rules.yaml", Months: 2, DryRun: true}, options)
+	assert.Contains(t, buf.String(), `"name": "spam"`)
+	assert.Equal(t, 0, exitCode)
+
+	// failed actions
+	buf.Reset()
+	err = rulesApplyCmd.Flags().Set("dry-run", "false")
+	assert.Nil(t, err)
+	rootCmd.SetArgs([]string{"rules", "apply"})
+	commandApplyRules = func(_ context.Context, _ command.RulesOptions) (*command.RulesResult, error) {
+		return &command.RulesResult{Matched: 1, Failed: 1, Rules: []command.RuleReport{}}, nil
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, buf.String(), `"failed": 1`)
+
+	// error
+	buf.Reset()
+	exitCode = 0
+	commandApplyRules = func(_ context.Context, _ command.RulesOptions) (*command.RulesResult, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
@@ -5,13 +5,21 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var commandSave = command.Save
+var (
+	commandSave = command.Save
+	commandEdit = command.Edit
+)
 
 // saveCmd represents the save command
 var saveCmd = &cobra.Command{
 	Use:   "save messageID",
 	Short: "Save a draft email",
-	Args:  cobra.ExactArgs(1),
+	Long: `Save a draft email.
+
+With --edit, the draft is opened in $VISUAL or $EDITOR as a header block followed by the plain-text body,
+and the other content flags are ignored. The HTML body of the draft is kept if the text is unchanged,
+and otherwise removed, leaving the edited text as the only body.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		messageID := args[0]
 
@@ -81,11 +89,71 @@ var saveCmd = &cobra.Command{
 			cmd.PrintErrln(err)
 			osExit(1)
 		}
+		attachments, err := cmd.Flags().GetStringArray("attach")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlines, err := cmd.Flags().GetStringArray("inline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		markdownFile, err := cmd.Flags().GetString("markdown")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		inlineCSS, err := cmd.Flags().GetBool("inline-css")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		edit, err := cmd.Flags().GetBool("edit")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
 
-		result, err := commandSave(command.SaveOptions{
-			APIID:    cmd.Flag("api-id").Value.String(),
-			Region:   cmd.Flag("region").Value.String(),
-			Endpoint: cmd.Flag("endpoint").Value.String(),
+		if edit {
+			result, err := commandEdit(ctx, command.EditOptions{
+				APIID:    client.APIID,
+				Region:   client.Region,
+				Endpoint: client.Endpoint,
+				Retries:  client.Retries,
+				Verbose:  verbose,
+
+				MessageID: messageID,
+				Send:      send,
+			})
+			if err != nil {
+				cmd.PrintErrln(err)
+				osExit(exitCode(err))
+				return
+			}
+
+			if err := printResult(cmd, result); err != nil {
+				cmd.PrintErrln(err)
+				osExit(1)
+			}
+			return
+		}
+
+		result, err := commandSave(ctx, command.SaveOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
 			Verbose:  verbose,
 
 			MessageID:    messageID,
@@ -100,16 +168,23 @@ var saveCmd = &cobra.Command{
 			HTML:         html,
 			GenerateText: generateText,
 			Send:         send,
+			Attachments:  attachments,
+			Inlines:      inlines,
+			Markdown:     markdownFile,
+			InlineCSS:    inlineCSS,
 
 			File: file,
 		})
 		if err != nil {
 			cmd.PrintErrln(err)
-			osExit(1)
+			osExit(exitCode(err))
 			return
 		}
 
-		cmd.Println(result)
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
 	},
 }
 
@@ -127,4 +202,9 @@ func init() {
 	saveCmd.Flags().String("generate-text", "", "Generate text from HTML (optional)")
 	saveCmd.Flags().String("file", "", "File")
 	saveCmd.Flags().Bool("send", false, "Send email immediately without using draft (optional)")
+	saveCmd.Flags().StringArray("attach", []string{}, "Attach a file, in the format of path[;type=...;name=...] (repeatable)")
+	saveCmd.Flags().StringArray("inline", []string{}, "Attach an inline file referenced by cid, in the format of cid=path[;type=...] (repeatable)")
+	saveCmd.Flags().String("markdown", "", "Markdown file rendered to both the text and HTML body")
+	saveCmd.Flags().Bool("inline-css", false, "Add inline styles to the HTML rendered from Markdown")
+	saveCmd.Flags().Bool("edit", false, "Edit the draft in $EDITOR")
 }
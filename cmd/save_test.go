@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +17,8 @@ func TestSave(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"save", "messageID"})
 
-	commandSave = func(_ command.SaveOptions) (string, error) {
-		return "result", nil
+	commandSave = func(_ context.Context, _ command.SaveOptions) (*email.Email, error) {
+		return &email.Email{MessageID: "message-id", Subject: "subject"}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,7 +26,7 @@ func TestSave(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "Save a draft email", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\",\n  \"subject\": \"subject\"\n}\n", buf.String())
 	assert.Equal(t, 0, exitCode)
 
 	// error
@@ -34,12 +36,40 @@ func TestSave(t *testing.T) {
 	assert.NotNil(t, err)
 
 	buf.Reset()
-	commandSave = func(_ command.SaveOptions) (string, error) {
-		return "result", errors.New("error")
+	commandSave = func(_ context.Context, _ command.SaveOptions) (*email.Email, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"save", "messageID"})
 	_, err = rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, 1, exitCode)
 	assert.Equal(t, "error\n", buf.String())
+
+	// edit
+	buf.Reset()
+	exitCode = 0
+	var options command.EditOptions
+	commandEdit = func(_ context.Context, o command.EditOptions) (*email.Email, error) {
+		options = o
+		return &email.Email{MessageID: "messageID"}, nil
+	}
+	rootCmd.SetArgs([]string{"save", "messageID", "--edit"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, command.EditOptions{Retries: 2, MessageID: "messageID"}, options)
+	assert.Equal(t, "{\n  \"messageID\": \"messageID\",\n  \"subject\": \"\"\n}\n", buf.String())
+
+	buf.Reset()
+	commandEdit = func(_ context.Context, _ command.EditOptions) (*email.Email, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+
+	// reset the flag, since flag values persist between executions
+	rootCmd.SetArgs([]string{"save", "messageID", "--edit=false"})
+	_, _ = rootCmd.ExecuteC()
 }
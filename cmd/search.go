@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandSearch = command.Search
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search emails",
+	Long: `Search emails by words of their subject, addresses, attachment names and body.
+
+Searches use a local index kept in the offline cache. Unless --offline is set, the metadata of the queried type
+and dates (the last 12 months without a start date) is first brought up to date with an incremental sync.
+Bodies aren't fetched, so they are only searched for emails synced with their bodies by the sync command.
+Every word of the query must match; hits are ranked by relevance, and the snippet highlights the matched words with **.
+
+The query may also filter by from:, to:, type:, has:attachment, since: and until: (inclusive dates),
+and after: and before: (exclusive dates), such as 'report from:alice since:2025-01-01'. Flags override them.`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		since, err := dateFlag(cmd, "since")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		until, err := dateFlag(cmd, "until")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+		if !until.IsZero() {
+			// include the whole day
+			until = until.AddDate(0, 0, 1)
+		}
+		hasAttachment, err := cmd.Flags().GetBool("has-attachment")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandSearch(ctx, command.SearchOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Query:         strings.Join(args, " "),
+			From:          cmd.Flag("from").Value.String(),
+			To:            cmd.Flag("to").Value.String(),
+			Since:         since,
+			Until:         until,
+			HasAttachment: hasAttachment,
+			Type:          cmd.Flag("type").Value.String(),
+			Limit:         limit,
+
+			Offline: offline,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+// dateFlag parses the flag as a date in the local time zone, returning the zero time if the flag is empty
+func dateFlag(cmd *cobra.Command, name string) (time.Time, error) {
+	value := cmd.Flag(name).Value.String()
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: expected YYYY-MM-DD", name, value)
+	}
+	return t, nil
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().String("from", "", "Only emails from an address containing this text")
+	searchCmd.Flags().String("to", "", "Only emails to, cc or bcc an address containing this text")
+	searchCmd.Flags().String("since", "", "Only emails on or after this date (YYYY-MM-DD)")
+	searchCmd.Flags().String("until", "", "Only emails on or before this date (YYYY-MM-DD)")
+	searchCmd.Flags().Bool("has-attachment", false, "Only emails with attachments")
+	searchCmd.Flags().String("type", "", "Only emails of this type: inbox, sent or draft")
+	searchCmd.Flags().Int("limit", command.DefaultSearchLimit, "Maximum number of results, or -1 for all")
+	searchCmd.Flags().Bool("offline", false, "Search the offline cache without syncing it first")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() {
+		time.Local = local
+	}()
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{
+		"search", "quarterly", "report", "--from", "alice", "--since", "2025-01-01", "--until", "2025-01-31",
+		"--has-attachment", "--type", "inbox", "--offline",
+	})
+
+	var options command.SearchOptions
+	commandSearch = func(_ context.Context, o command.SearchOptions) (*search.Result, error) {
+		options = o
+		return &search.Result{Count: 1, Items: []search.Hit{
+			{Email: email.Email{MessageID: "id-1", Subject: "Report"}, Score: 1, Snippet: "**report**"},
+		}}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Search emails", c.Short)
+	assert.Equal(t, command.SearchOptions{
+		Retries:       2,
+		Query:         "quarterly report",
+		From:          "alice",
+		Since:         time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Until:         time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		HasAttachment: true,
+		Type:          "inbox",
+		Limit:         command.DefaultSearchLimit,
+		Offline:       true,
+	}, options)
+	assert.Contains(t, buf.String(), `"snippet": "**report**"`)
+	assert.Equal(t, 0, exitCode)
+
+	// invalid date
+	buf.Reset()
+	rootCmd.SetArgs([]string{"search", "report", "--since", "yesterday", "--until", ""})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "invalid --since \"yesterday\": expected YYYY-MM-DD\n", buf.String())
+
+	// the index hasn't been built
+	buf.Reset()
+	rootCmd.SetArgs([]string{"search", "report", "--since", "", "--from", "", "--has-attachment=false", "--type", "", "--offline=false"})
+	commandSearch = func(_ context.Context, _ command.SearchOptions) (*search.Result, error) {
+		return nil, cache.ErrNotCached
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, exitCode)
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/harryzcy/mailbox-cli/internal/command"
 	"github.com/spf13/cobra"
 )
@@ -9,36 +11,68 @@ var commandSend = command.Send
 
 // sendCmd represents the send command
 var sendCmd = &cobra.Command{
-	Use:   "send messageID",
+	Use:   "send messageID...",
 	Short: "Send an email",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		messageID := args[0]
+	Long: `Send emails by message ID.
 
+With several message IDs, or - to read them newline-delimited from stdin, the emails are processed concurrently
+and a summary reports the status of each ID. The exit code is 1 if any of them failed.`,
+	Args: messageIDArgs,
+	Run: func(cmd *cobra.Command, args []string) {
 		verbose, err := cmd.Flags().GetBool("verbose")
 		if err != nil {
 			cmd.PrintErrln(err)
 			osExit(1)
 		}
 
-		result, err := commandSend(command.SendOptions{
-			APIID:    cmd.Flag("api-id").Value.String(),
-			Region:   cmd.Flag("region").Value.String(),
-			Endpoint: cmd.Flag("endpoint").Value.String(),
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		options := command.SendOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
 			Verbose:  verbose,
+		}
+		messageIDs, err := bulkMessageIDs(ctx, cmd, args, "Send", client, verbose)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+		if messageIDs != nil {
+			runBulk(ctx, cmd, messageIDs, func(ctx context.Context, messageID string) (any, error) {
+				options := options
+				options.MessageID = messageID
+				return commandSend(ctx, options)
+			})
+			return
+		}
 
-			MessageID: messageID,
-		})
+		options.MessageID = args[0]
+		result, err := commandSend(ctx, options)
 		if err != nil {
 			cmd.PrintErrln(err)
-			osExit(1)
+			osExit(exitCode(err))
 			return
 		}
 
-		cmd.Println(result)
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(sendCmd)
+	addBulkFlags(sendCmd)
 }
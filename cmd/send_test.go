@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +17,8 @@ func TestSend(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"send", "message-id"})
 
-	commandSend = func(_ command.SendOptions) (string, error) {
-		return "result", nil
+	commandSend = func(_ context.Context, _ command.SendOptions) (*email.ActionResult, error) {
+		return &email.ActionResult{MessageID: "message-id"}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,7 +26,7 @@ func TestSend(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "Send an email", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\"\n}\n", buf.String())
 
 	// error
 	buf.Reset()
@@ -33,8 +35,8 @@ func TestSend(t *testing.T) {
 	assert.NotNil(t, err)
 
 	buf.Reset()
-	commandSend = func(_ command.SendOptions) (string, error) {
-		return "result", errors.New("error")
+	commandSend = func(_ context.Context, _ command.SendOptions) (*email.ActionResult, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"send", "message-id"})
 	_, err = rootCmd.ExecuteC()
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/cobra"
+)
+
+var commandSync = command.Sync
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror emails into the offline cache",
+	Long: `Mirror emails into the offline cache, which list, get and search serve with --offline.
+
+Emails are synced by year/month partitions, newest first. Past months are only listed once after they end,
+and the other months are listed until reaching an email that is already cached and unchanged.
+Use --full to list every partition to the end, refreshing changed emails and removing deleted ones.
+Fetching the bodies marks inbox emails as read, so unread emails are marked unread again afterwards.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		types, err := cmd.Flags().GetStringArray("type")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		months, err := cmd.Flags().GetInt("months")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		full, err := cmd.Flags().GetBool("full")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		noBodies, err := cmd.Flags().GetBool("no-bodies")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandSync(ctx, command.SyncOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Types:    types,
+			Months:   months,
+			Full:     full,
+			NoBodies: noBodies,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringArray("type", []string{}, "Type of emails to sync: inbox, sent or draft (repeatable, default all)")
+	syncCmd.Flags().Int("months", command.DefaultSyncMonths, "Number of months to sync, including the current month")
+	syncCmd.Flags().Bool("full", false, "List every partition to the end, refreshing changed emails and removing deleted ones")
+	syncCmd.Flags().Bool("no-bodies", false, "Only sync the metadata returned by list")
+}
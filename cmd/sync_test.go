@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSync(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"sync", "--type", "inbox", "--months", "3", "--no-bodies"})
+
+	var options command.SyncOptions
+	commandSync = func(_ context.Context, o command.SyncOptions) (*command.SyncResult, error) {
+		options = o
+		return &command.SyncResult{
+			Added:      1,
+			Partitions: []command.SyncedPartition{{Type: "inbox", Year: "2025", Month: "01", Added: 1}},
+		}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Mirror emails into the offline cache", c.Short)
+	assert.Equal(t, command.SyncOptions{Retries: 2, Types: []string{"inbox"}, Months: 3, NoBodies: true}, options)
+	assert.Contains(t, buf.String(), `"added": 1`)
+	assert.Equal(t, 0, exitCode)
+
+	// error
+	buf.Reset()
+	commandSync = func(_ context.Context, _ command.SyncOptions) (*command.SyncResult, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/thread"
+	"github.com/spf13/cobra"
+)
+
+var commandThread = command.Thread
+
+// threadCmd represents the thread command
+var threadCmd = &cobra.Command{
+	Use:   "thread messageID",
+	Short: "Show the conversation of an email",
+	Long: `Show the conversation of an email, reconstructed from the Message-ID, In-Reply-To and References headers
+of the emails in its backend thread and of the cached emails with the same subject.
+
+The tree view nests replies below the email they reply to; the transcript view lists the emails chronologically
+with their bodies. With --offline, only the offline cache is used and emails are linked by their thread ID.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		messageID := args[0]
+
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		offline, err := cmd.Flags().GetBool("offline")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		result, err := commandThread(ctx, command.ThreadOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			MessageID: messageID,
+			View:      cmd.Flag("view").Value.String(),
+
+			Offline: offline,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+			return
+		}
+
+		if err := printResult(cmd, result); err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(threadCmd)
+	threadCmd.Flags().String("view", thread.ViewTree, "How to render the thread: tree or transcript")
+	threadCmd.Flags().Bool("offline", false, "Only use the offline cache populated by sync")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/thread"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThread(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"thread", "message-id", "--view", "transcript", "--offline"})
+
+	var options command.ThreadOptions
+	commandThread = func(_ context.Context, o command.ThreadOptions) (*thread.Result, error) {
+		options = o
+		return &thread.Result{Subject: "Hello", View: o.View, Count: 1, Items: []thread.Item{
+			{Email: email.Email{MessageID: "message-id", Subject: "Hello"}},
+		}}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Show the conversation of an email", c.Short)
+	assert.Equal(t, command.ThreadOptions{Retries: 2, MessageID: "message-id", View: "transcript", Offline: true}, options)
+	assert.Contains(t, buf.String(), `"view": "transcript"`)
+	assert.Equal(t, 0, exitCode)
+
+	// error
+	buf.Reset()
+	rootCmd.SetArgs([]string{"thread", "message-id", "--view", "tree", "--offline=false"})
+	commandThread = func(_ context.Context, _ command.ThreadOptions) (*thread.Result, error) {
+		return nil, errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +21,8 @@ func TestTrash(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"trash", "message-id"})
 
-	commandTrash = func(_ command.TrashOptions) (string, error) {
-		return "result", nil
+	commandTrash = func(_ context.Context, _ command.TrashOptions) (*email.ActionResult, error) {
+		return &email.ActionResult{MessageID: "message-id"}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,7 +30,7 @@ func TestTrash(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "Trash an email", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\"\n}\n", buf.String())
 
 	// error
 	buf.Reset()
@@ -33,8 +39,8 @@ func TestTrash(t *testing.T) {
 	assert.NotNil(t, err)
 
 	buf.Reset()
-	commandTrash = func(_ command.TrashOptions) (string, error) {
-		return "result", errors.New("error")
+	commandTrash = func(_ context.Context, _ command.TrashOptions) (*email.ActionResult, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"trash", "message-id"})
 	_, err = rootCmd.ExecuteC()
@@ -42,3 +48,138 @@ func TestTrash(t *testing.T) {
 	assert.Equal(t, 1, exitCode)
 	assert.Equal(t, "error\n", buf.String())
 }
+
+func TestTrash_Bulk(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetIn(strings.NewReader("id-2\nid-3\n"))
+	rootCmd.SetArgs([]string{"trash", "id-1", "-", "--concurrency", "2", "-o", "table"})
+
+	var mu sync.Mutex
+	var trashed []string
+	commandTrash = func(_ context.Context, o command.TrashOptions) (*email.ActionResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		trashed = append(trashed, o.MessageID)
+		if o.MessageID == "id-2" {
+			return nil, errors.New("email not found")
+		}
+		return &email.ActionResult{MessageID: o.MessageID}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	_, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"id-1", "id-2", "id-3"}, trashed)
+	assert.Equal(t, "ID    STATUS  ERROR\n"+
+		"id-1  ok      \n"+
+		"id-2  failed  email not found\n"+
+		"id-3  ok      \n", buf.String())
+	assert.Equal(t, 1, exitCode)
+
+	buf.Reset()
+	exitCode = 0
+	rootCmd.SetArgs([]string{"trash", "id-1", "id-3", "-o", "json"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), `"succeeded": 2`)
+	assert.Equal(t, 0, exitCode)
+}
+
+func TestTrash_Where(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetIn(strings.NewReader("y\n"))
+	rootCmd.SetArgs([]string{"trash", "--where", "from:alerts@x.com before:2025-01-01", "--type", "inbox", "--months", "3", "-o", "json"})
+	t.Cleanup(func() {
+		for name, value := range map[string]string{"where": "", "type": "", "months": "12", "yes": "false"} {
+			err := trashCmd.Flags().Set(name, value)
+			assert.Nil(t, err)
+		}
+	})
+
+	var options command.SelectOptions
+	selected := &email.ListResult{}
+	for i := range 12 {
+		selected.Items = append(selected.Items, email.Email{MessageID: fmt.Sprintf("id-%02d", i), Subject: "Alert"})
+	}
+	commandSelect = func(_ context.Context, o command.SelectOptions) (*email.ListResult, error) {
+		options = o
+		return selected, nil
+	}
+	var mu sync.Mutex
+	var trashed []string
+	commandTrash = func(_ context.Context, o command.TrashOptions) (*email.ActionResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		trashed = append(trashed, o.MessageID)
+		return &email.ActionResult{MessageID: o.MessageID}, nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	_, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, command.SelectOptions{Retries: 2, Where: "from:alerts@x.com before:2025-01-01", Type: "inbox", Months: 3}, options)
+	assert.Len(t, trashed, 12)
+	assert.Contains(t, buf.String(), "Selected 12 emails:\n")
+	assert.Contains(t, buf.String(), "id-09\n... and 2 more\n")
+	assert.Contains(t, buf.String(), "Trash 12 emails? [y/N] ")
+	assert.Contains(t, buf.String(), `"succeeded": 12`)
+	assert.Equal(t, 0, exitCode)
+
+	// declined, or without an interactive stdin
+	for _, stdin := range []string{"n\n", ""} {
+		buf.Reset()
+		trashed = nil
+		rootCmd.SetIn(strings.NewReader(stdin))
+		_, err = rootCmd.ExecuteC()
+		assert.Nil(t, err)
+		assert.Empty(t, trashed)
+		assert.Contains(t, buf.String(), "aborted, pass --yes to confirm without a prompt\n")
+		assert.Equal(t, 1, exitCode)
+	}
+
+	// --yes, or a selection below the threshold, skips the confirmation
+	buf.Reset()
+	exitCode = 0
+	rootCmd.SetArgs([]string{"trash", "--where", "from:alerts@x.com", "--yes"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Len(t, trashed, 12)
+	assert.NotContains(t, buf.String(), "[y/N]")
+	assert.Equal(t, 0, exitCode)
+
+	buf.Reset()
+	trashed = nil
+	selected.Items = selected.Items[:1]
+	rootCmd.SetArgs([]string{"trash", "--where", "from:alerts@x.com", "--yes=false"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id-00"}, trashed)
+	assert.NotContains(t, buf.String(), "[y/N]")
+
+	buf.Reset()
+	trashed = nil
+	selected.Items = nil
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Empty(t, trashed)
+	assert.Contains(t, buf.String(), "No emails selected\n")
+
+	// errors
+	buf.Reset()
+	commandSelect = func(_ context.Context, _ command.SelectOptions) (*email.ListResult, error) {
+		return nil, fmt.Errorf("%w: type:draft conflicts with --type inbox", search.ErrInvalidQuery)
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, exitCodeValidation, exitCode)
+
+	rootCmd.SetArgs([]string{"trash", "id-1", "--where", "from:alerts@x.com"})
+	_, err = rootCmd.ExecuteC()
+	assert.EqualError(t, err, "message IDs can't be combined with --where")
+}
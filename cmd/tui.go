@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var commandTUI = command.TUI
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Triage the mailbox in a full-screen interface",
+	Long: `Triage the mailbox in a full-screen interface, with a folder pane listing each type by month,
+a message list loading more emails as you scroll, and a reader pane.
+
+Keys: tab switches panes, j/k or arrows move, enter opens, t trashes, u untrashes, d deletes,
+r/R saves a reply (to all) as a draft, s sends a draft, and q quits.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		types, err := cmd.Flags().GetStringArray("type")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		months, err := cmd.Flags().GetInt("months")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		err = commandTUI(ctx, command.TUIOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+
+			Types:  types,
+			Months: months,
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().StringArray("type", []string{}, "Type of emails in the folder pane: inbox, sent or draft (repeatable, default all)")
+	tuiCmd.Flags().Int("months", tui.DefaultMonths, "Number of months of each type in the folder pane")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTUI(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"tui", "--type", "inbox", "--months", "3"})
+
+	var options command.TUIOptions
+	commandTUI = func(_ context.Context, o command.TUIOptions) error {
+		options = o
+		return nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Triage the mailbox in a full-screen interface", c.Short)
+	assert.Equal(t, command.TUIOptions{Retries: 2, Types: []string{"inbox"}, Months: 3}, options)
+	assert.Equal(t, 0, exitCode)
+
+	// error
+	commandTUI = func(_ context.Context, _ command.TUIOptions) error {
+		return errors.New("error")
+	}
+	tuiCmd.Flags().Lookup("type").Value.(pflag.SliceValue).Replace(nil)
+	rootCmd.SetArgs([]string{"tui"})
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,8 +17,8 @@ func TestUntrash(t *testing.T) {
 	rootCmd.SetErr(buf)
 	rootCmd.SetArgs([]string{"untrash", "message-id"})
 
-	commandUntrash = func(_ command.UntrashOptions) (string, error) {
-		return "result", nil
+	commandUntrash = func(_ context.Context, _ command.UntrashOptions) (*email.ActionResult, error) {
+		return &email.ActionResult{MessageID: "message-id"}, nil
 	}
 	var exitCode int
 	osExit = func(code int) { exitCode = code }
@@ -24,7 +26,7 @@ func TestUntrash(t *testing.T) {
 	c, err := rootCmd.ExecuteC()
 	assert.Nil(t, err)
 	assert.Equal(t, "Untrash an email", c.Short)
-	assert.Equal(t, "result\n", buf.String())
+	assert.Equal(t, "{\n  \"messageID\": \"message-id\"\n}\n", buf.String())
 
 	// error
 	buf.Reset()
@@ -32,9 +34,15 @@ func TestUntrash(t *testing.T) {
 	_, err = rootCmd.ExecuteC()
 	assert.NotNil(t, err)
 
+	// trashed emails aren't listed, so they can't be selected
 	buf.Reset()
-	commandUntrash = func(_ command.UntrashOptions) (string, error) {
-		return "result", errors.New("error")
+	rootCmd.SetArgs([]string{"untrash", "--where", "from:alerts@example.com"})
+	_, err = rootCmd.ExecuteC()
+	assert.ErrorContains(t, err, "unknown flag: --where")
+
+	buf.Reset()
+	commandUntrash = func(_ context.Context, _ command.UntrashOptions) (*email.ActionResult, error) {
+		return nil, errors.New("error")
 	}
 	rootCmd.SetArgs([]string{"untrash", "message-id"})
 	_, err = rootCmd.ExecuteC()
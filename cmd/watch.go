@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/spf13/cobra"
+)
+
+var commandWatch = command.Watch
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch for new emails",
+	Long: `Watch for new emails, polling the newest emails of a type and printing each new one.
+
+For each new email, --hook runs a shell command with the email JSON on stdin and MAILBOX_MESSAGE_ID,
+MAILBOX_TYPE and MAILBOX_SUBJECT in the environment, and --webhook POSTs the email JSON to a URL.
+The output of the hook goes to stderr, keeping stdout for the new emails. New emails stay unread.
+The newest email seen is recorded next to the offline cache, so restarting watch delivers the emails received
+in between. The first run only records the newest email. Use --once to poll once, such as from cron.`,
+	Args: cobra.ExactArgs(0),
+	Run: func(cmd *cobra.Command, _ []string) {
+		verbose, err := cmd.Flags().GetBool("verbose")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		emailType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		hook, err := cmd.Flags().GetString("hook")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		webhook, err := cmd.Flags().GetString("webhook")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+		once, err := cmd.Flags().GetBool("once")
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+		}
+
+		client, err := clientConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(1)
+			return
+		}
+
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		err = commandWatch(ctx, command.WatchOptions{
+			APIID:    client.APIID,
+			Region:   client.Region,
+			Endpoint: client.Endpoint,
+			Retries:  client.Retries,
+			Verbose:  verbose,
+
+			Type:     emailType,
+			Interval: interval,
+			Hook:     hook,
+			Webhook:  webhook,
+			Once:     once,
+			OnEmail: func(e email.Email) {
+				if err := printResult(cmd, &e); err != nil {
+					cmd.PrintErrln(err)
+				}
+			},
+			OnError: func(err error) {
+				cmd.PrintErrln(err)
+			},
+		})
+		if err != nil {
+			cmd.PrintErrln(err)
+			osExit(exitCode(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().String("type", email.EmailTypeInbox, "Type of emails to watch: inbox, sent or draft")
+	watchCmd.Flags().Duration("interval", command.DefaultWatchInterval, "Time between polls")
+	watchCmd.Flags().String("hook", "", "Shell command run for each new email, with the email JSON on stdin")
+	watchCmd.Flags().String("webhook", "", "URL the email JSON is POSTed to for each new email")
+	watchCmd.Flags().Bool("once", false, "Poll once and exit")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/command"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"watch", "--interval", "1m", "--hook", "cat", "--webhook", "https://example.com/hook", "--once"})
+
+	var options command.WatchOptions
+	commandWatch = func(_ context.Context, o command.WatchOptions) error {
+		o.OnEmail(email.Email{MessageID: "new-id", Subject: "Hello"})
+		o.OnError(errors.New("hook failed"))
+		o.OnEmail, o.OnError = nil, nil
+		options = o
+		return nil
+	}
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	c, err := rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, "Watch for new emails", c.Short)
+	assert.Equal(t, command.WatchOptions{
+		Retries:  2,
+		Type:     email.EmailTypeInbox,
+		Interval: time.Minute,
+		Hook:     "cat",
+		Webhook:  "https://example.com/hook",
+		Once:     true,
+	}, options)
+	assert.Contains(t, buf.String(), `"messageID": "new-id"`)
+	assert.Contains(t, buf.String(), "hook failed\n")
+	assert.Equal(t, 0, exitCode)
+
+	// error
+	buf.Reset()
+	commandWatch = func(_ context.Context, _ command.WatchOptions) error {
+		return errors.New("error")
+	}
+	_, err = rootCmd.ExecuteC()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "error\n", buf.String())
+}
@@ -0,0 +1,241 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+)
+
+// The supported archive formats
+const (
+	FormatMbox    = "mbox"
+	FormatMaildir = "maildir"
+	FormatEML     = "eml"
+)
+
+var ErrInvalidFormat = errors.New("invalid format")
+
+// Writer stores raw RFC 5322 messages in an archive
+type Writer interface {
+	Write(e email.Email, raw []byte) error
+	Close() error
+}
+
+// NewWriter creates a writer of the format at dest.
+// For mbox, dest is a file that is appended to; otherwise it is a directory.
+func NewWriter(format, dest string) (Writer, error) {
+	switch format {
+	case FormatMbox:
+		return NewMbox(dest)
+	case FormatMaildir:
+		return NewMaildir(dest)
+	case FormatEML:
+		return NewEML(dest)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, format)
+}
+
+// ProgressPath returns the location of the progress file of an export to dest
+func ProgressPath(format, dest string) string {
+	if format == FormatMbox {
+		return dest + ".progress"
+	}
+	return filepath.Join(dest, ".mailbox-cli-progress")
+}
+
+// Mbox writes messages to a single file in the mboxrd format
+type Mbox struct {
+	file *os.File
+	size int64
+}
+
+func NewMbox(path string) (*Mbox, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	return &Mbox{file: file, size: info.Size()}, nil
+}
+
+// Size returns the size of the file, including the messages written
+func (m *Mbox) Size() int64 {
+	return m.size
+}
+
+// Truncate removes everything after size from the file, such as a partially written message
+func (m *Mbox) Truncate(size int64) error {
+	if size > m.size {
+		return fmt.Errorf("mbox is %d bytes, shorter than the %d bytes recorded as exported", m.size, size)
+	}
+	if size == m.size {
+		return nil
+	}
+	if err := m.file.Truncate(size); err != nil {
+		return err
+	}
+	m.size = size
+	return m.file.Sync()
+}
+
+func (m *Mbox) Write(e email.Email, raw []byte) error {
+	sender := "MAILER-DAEMON"
+	if len(e.From) > 0 {
+		sender = envelopeAddress(e.From[0])
+	}
+	date := e.Time()
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From %s %s\n", sender, date.UTC().Format(time.ANSIC))
+	writeMboxBody(buf, raw)
+	buf.WriteString("\n")
+
+	n, err := m.file.Write(buf.Bytes())
+	m.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return m.file.Sync()
+}
+
+func (m *Mbox) Close() error {
+	return m.file.Close()
+}
+
+// writeMboxBody writes the message with LF line endings,
+// quoting lines that start with "From " (including already quoted ones) as in mboxrd.
+func writeMboxBody(w io.Writer, raw []byte) {
+	raw = bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 64*1024), len(raw)+1)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+			_, _ = w.Write([]byte(">"))
+		}
+		_, _ = w.Write(line)
+		_, _ = w.Write([]byte("\n"))
+	}
+}
+
+func envelopeAddress(address string) string {
+	if start := strings.LastIndex(address, "<"); start != -1 {
+		if end := strings.Index(address[start:], ">"); end != -1 {
+			address = address[start+1 : start+end]
+		}
+	}
+	address = strings.TrimSpace(address)
+	if address == "" || strings.ContainsAny(address, " \t") {
+		return "MAILER-DAEMON"
+	}
+	return address
+}
+
+// Maildir writes each message to its own file in a Maildir
+type Maildir struct {
+	dir string
+}
+
+func NewMaildir(dir string) (*Maildir, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &Maildir{dir: dir}, nil
+}
+
+func (m *Maildir) Write(e email.Email, raw []byte) error {
+	date := e.Time()
+	if date.IsZero() {
+		date = time.Now()
+	}
+	hostname, _ := os.Hostname()
+	hostname = strings.NewReplacer("/", `\057`, ":", `\072`).Replace(hostname)
+	name := fmt.Sprintf("%d.%s.%s", date.Unix(), safeName(e.MessageID), hostname)
+
+	// unread inbox emails go to new, everything else is marked as seen
+	dest := filepath.Join(m.dir, "cur", name+":2,S")
+	if e.Unread != nil && *e.Unread {
+		dest = filepath.Join(m.dir, "new", name)
+	}
+
+	tmp := filepath.Join(m.dir, "tmp", name)
+	if err := writeFileSync(tmp, raw); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func (m *Maildir) Close() error {
+	return nil
+}
+
+// EML writes each message to a .eml file named after its message ID
+type EML struct {
+	dir string
+}
+
+func NewEML(dir string) (*EML, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &EML{dir: dir}, nil
+}
+
+func (x *EML) Write(e email.Email, raw []byte) error {
+	return writeFileSync(filepath.Join(x.dir, safeName(e.MessageID)+".eml"), raw)
+}
+
+func (x *EML) Close() error {
+	return nil
+}
+
+// safeName makes the message ID usable as a filename
+func safeName(messageID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, messageID)
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "message"
+	}
+	return name
+}
+
+func writeFileSync(path string, data []byte) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Sync()
+}
@@ -0,0 +1,98 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEmail = email.Email{
+	MessageID:    "message-id",
+	From:         []string{"Alice <alice@example.com>"},
+	TimeReceived: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestNewWriter(t *testing.T) {
+	dir := t.TempDir()
+	for _, format := range []string{FormatMbox, FormatMaildir, FormatEML} {
+		writer, err := NewWriter(format, filepath.Join(dir, format))
+		assert.Nil(t, err)
+		assert.Nil(t, writer.Close())
+	}
+
+	_, err := NewWriter("pst", dir)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestProgressPath(t *testing.T) {
+	assert.Equal(t, "out/inbox.mbox.progress", ProgressPath(FormatMbox, "out/inbox.mbox"))
+	assert.Equal(t, filepath.Join("out", ".mailbox-cli-progress"), ProgressPath(FormatMaildir, "out"))
+}
+
+func TestMbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive", "inbox.mbox")
+	mbox, err := NewMbox(path)
+	assert.Nil(t, err)
+
+	err = mbox.Write(testEmail, []byte("Subject: one\r\n\r\nFrom here\r\n>From there\r\nbody\r\n"))
+	assert.Nil(t, err)
+	err = mbox.Write(email.Email{From: []string{"invalid address"}, TimeReceived: testEmail.TimeReceived}, []byte("Subject: two\n\nbody"))
+	assert.Nil(t, err)
+	assert.Nil(t, mbox.Close())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "From alice@example.com Thu Jan  2 03:04:05 2025\n"+
+		"Subject: one\n\n>From here\n>>From there\nbody\n\n"+
+		"From MAILER-DAEMON Thu Jan  2 03:04:05 2025\n"+
+		"Subject: two\n\nbody\n\n", string(data))
+}
+
+func TestMaildir(t *testing.T) {
+	dir := t.TempDir()
+	maildir, err := NewMaildir(dir)
+	assert.Nil(t, err)
+
+	err = maildir.Write(testEmail, []byte("Subject: read\r\n\r\n"))
+	assert.Nil(t, err)
+	unread := true
+	err = maildir.Write(email.Email{MessageID: "unread/id", Unread: &unread}, []byte("Subject: unread\r\n\r\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, maildir.Close())
+
+	cur, err := os.ReadDir(filepath.Join(dir, "cur"))
+	assert.Nil(t, err)
+	assert.Len(t, cur, 1)
+	assert.Regexp(t, `^1735787045\.message-id\..*:2,S$`, cur[0].Name())
+
+	entries, err := os.ReadDir(filepath.Join(dir, "new"))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Name(), ".unread_id.")
+
+	entries, err = os.ReadDir(filepath.Join(dir, "tmp"))
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestEML(t *testing.T) {
+	dir := t.TempDir()
+	eml, err := NewEML(dir)
+	assert.Nil(t, err)
+
+	err = eml.Write(testEmail, []byte("Subject: one\r\n\r\n"))
+	assert.Nil(t, err)
+	err = eml.Write(email.Email{MessageID: "../escape"}, []byte("Subject: two\r\n\r\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, eml.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "message-id.eml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Subject: one\r\n\r\n", string(data))
+	_, err = os.Stat(filepath.Join(dir, "_escape.eml"))
+	assert.Nil(t, err)
+}
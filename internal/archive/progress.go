@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Progress records the message IDs that have been exported, so that an interrupted export can resume.
+// The file contains one message ID per line and is only appended to.
+// For mbox, each ID is followed by a tab and the size of the mbox after the message.
+type Progress struct {
+	file *os.File
+	done map[string]bool
+	size int64 // size of the mbox after the last recorded message, -1 if not recorded
+}
+
+// OpenProgress loads the progress file at path, creating it if needed
+func OpenProgress(path string) (*Progress, error) {
+	done := map[string]bool{}
+	size := int64(-1)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		id, value, hasSize := strings.Cut(scanner.Text(), "\t")
+		if hasSize {
+			parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("%s: invalid size %q", path, value)
+			}
+			size = parsed
+		}
+		if id = strings.TrimSpace(id); id != "" {
+			done[id] = true
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Progress{file: file, done: done, size: size}, nil
+}
+
+// Done reports whether the message has already been exported
+func (p *Progress) Done(messageID string) bool {
+	return p.done[messageID]
+}
+
+// Len returns the number of exported messages
+func (p *Progress) Len() int {
+	return len(p.done)
+}
+
+// Resume prepares the writer to continue the export.
+// An mbox is truncated to the end of the last recorded message, dropping a message whose write was interrupted
+// or that was written without being recorded, so that it's exported again once and in full.
+// The other formats write each message to its own file, which needs no preparation.
+func (p *Progress) Resume(w Writer) error {
+	mbox, ok := w.(*Mbox)
+	if !ok {
+		return nil
+	}
+	if p.size < 0 {
+		if p.Len() > 0 {
+			// the sizes weren't recorded, so the end of the last message is unknown
+			return nil
+		}
+		// a new export starts at the current end of the file, which may already hold other messages
+		return p.record("", mbox.Size())
+	}
+	return mbox.Truncate(p.size)
+}
+
+// Add records the message as exported by the writer, once it has been written in full
+func (p *Progress) Add(w Writer, messageID string) error {
+	size := int64(-1)
+	if mbox, ok := w.(*Mbox); ok {
+		size = mbox.Size()
+	}
+	if err := p.record(messageID, size); err != nil {
+		return err
+	}
+	if messageID != "" {
+		p.done[messageID] = true
+	}
+	return nil
+}
+
+// record appends a line for the message, with the size of the mbox if it isn't negative
+func (p *Progress) record(messageID string, size int64) error {
+	line := messageID + "\n"
+	if size >= 0 {
+		line = messageID + "\t" + strconv.FormatInt(size, 10) + "\n"
+	}
+	if _, err := p.file.WriteString(line); err != nil {
+		return err
+	}
+	if size >= 0 {
+		p.size = size
+	}
+	return p.file.Sync()
+}
+
+func (p *Progress) Close() error {
+	return p.file.Close()
+}
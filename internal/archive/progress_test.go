@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "export")
+	path := filepath.Join(dir, ".mailbox-cli-progress")
+	writer, err := NewEML(dir)
+	assert.Nil(t, err)
+
+	progress, err := OpenProgress(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, progress.Len())
+	assert.Nil(t, progress.Resume(writer))
+	assert.Nil(t, progress.Add(writer, "id-1"))
+	assert.Nil(t, progress.Add(writer, "id-2"))
+	assert.True(t, progress.Done("id-1"))
+	assert.Nil(t, progress.Close())
+
+	progress, err = OpenProgress(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, progress.Len())
+	assert.True(t, progress.Done("id-2"))
+	assert.False(t, progress.Done("id-3"))
+	assert.Nil(t, progress.Close())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "id-1\nid-2\n", string(data))
+
+	_, err = OpenProgress(t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func TestProgress_Mbox(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "inbox.mbox")
+	err := os.WriteFile(path, []byte("From old\n\n"), 0o644)
+	assert.Nil(t, err)
+
+	mbox, err := NewMbox(path)
+	assert.Nil(t, err)
+	progress, err := OpenProgress(ProgressPath(FormatMbox, path))
+	assert.Nil(t, err)
+	assert.Nil(t, progress.Resume(mbox))
+	assert.Nil(t, mbox.Write(testEmail, []byte("Subject: one\n\nbody")))
+	assert.Nil(t, progress.Add(mbox, "id-1"))
+	complete := mbox.Size()
+
+	// a message written without being recorded, followed by one interrupted while being written
+	assert.Nil(t, mbox.Write(testEmail, []byte("Subject: two\n\nbody")))
+	_, err = mbox.file.WriteString("From alice@example.com Thu Jan  2 03:04:05 2025\nSubj")
+	assert.Nil(t, err)
+	assert.Nil(t, mbox.Close())
+	assert.Nil(t, progress.Close())
+
+	data, err := os.ReadFile(ProgressPath(FormatMbox, path))
+	assert.Nil(t, err)
+	assert.Equal(t, "\t10\nid-1\t"+strconv.FormatInt(complete, 10)+"\n", string(data))
+
+	// resuming truncates the mbox to the end of the last recorded message
+	mbox, err = NewMbox(path)
+	assert.Nil(t, err)
+	progress, err = OpenProgress(ProgressPath(FormatMbox, path))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, progress.Len())
+	assert.Nil(t, progress.Resume(mbox))
+	assert.Equal(t, complete, mbox.Size())
+	assert.Nil(t, mbox.Write(testEmail, []byte("Subject: two\n\nbody")))
+	assert.Nil(t, progress.Add(mbox, "id-2"))
+	assert.Nil(t, mbox.Close())
+	assert.Nil(t, progress.Close())
+
+	data, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "From old\n\n"+
+		"From alice@example.com Thu Jan  2 03:04:05 2025\nSubject: one\n\nbody\n\n"+
+		"From alice@example.com Thu Jan  2 03:04:05 2025\nSubject: two\n\nbody\n\n", string(data))
+
+	// an mbox shorter than recorded isn't the one exported to
+	err = os.WriteFile(path, nil, 0o644)
+	assert.Nil(t, err)
+	mbox, err = NewMbox(path)
+	assert.Nil(t, err)
+	progress, err = OpenProgress(ProgressPath(FormatMbox, path))
+	assert.Nil(t, err)
+	assert.ErrorContains(t, progress.Resume(mbox), "shorter than")
+	assert.Nil(t, mbox.Close())
+	assert.Nil(t, progress.Close())
+
+	err = os.WriteFile(ProgressPath(FormatMbox, path), []byte("id-1\tx\n"), 0o644)
+	assert.Nil(t, err)
+	_, err = OpenProgress(ProgressPath(FormatMbox, path))
+	assert.ErrorContains(t, err, `invalid size "x"`)
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ReadMbox iterates over the messages of an mbox file.
+// Lines quoted with ">From " are unquoted as in mboxrd, and the separator lines are dropped.
+func ReadMbox(r io.Reader) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		reader := bufio.NewReader(r)
+		var msg *bytes.Buffer
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				yield(nil, err)
+				return
+			}
+
+			if bytes.HasPrefix(line, []byte("From ")) {
+				if msg != nil && !yield(trimMboxMessage(msg.Bytes()), nil) {
+					return
+				}
+				msg = &bytes.Buffer{}
+			} else if msg != nil {
+				if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+					line = line[1:]
+				}
+				msg.Write(line)
+			}
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+		}
+		if msg != nil {
+			yield(trimMboxMessage(msg.Bytes()), nil)
+		}
+	}
+}
+
+// trimMboxMessage removes the blank line that separates the message from the next one
+func trimMboxMessage(msg []byte) []byte {
+	if bytes.HasSuffix(msg, []byte("\r\n\r\n")) {
+		return msg[:len(msg)-2]
+	}
+	if bytes.HasSuffix(msg, []byte("\n\n")) {
+		return msg[:len(msg)-1]
+	}
+	return msg
+}
+
+// EMLFiles returns the .eml files at path, which is either a single file or a directory
+func EMLFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".eml") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
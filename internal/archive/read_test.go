@@ -0,0 +1,63 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadMbox(t *testing.T) {
+	mbox := "From a@example.com Thu Jan  2 03:04:05 2025\n" +
+		"Subject: one\n\n>From here\n>>From there\nbody\n\n" +
+		"From MAILER-DAEMON Thu Jan  2 03:04:05 2025\n" +
+		"Subject: two\n\nbody"
+
+	var messages []string
+	for msg, err := range ReadMbox(strings.NewReader(mbox)) {
+		assert.Nil(t, err)
+		messages = append(messages, string(msg))
+	}
+	assert.Equal(t, []string{
+		"Subject: one\n\nFrom here\n>From there\nbody\n",
+		"Subject: two\n\nbody",
+	}, messages)
+
+	// round trip with the writer
+	path := filepath.Join(t.TempDir(), "inbox.mbox")
+	writer, err := NewMbox(path)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Write(testEmail, []byte("Subject: one\n\nFrom here\n")))
+	assert.Nil(t, writer.Close())
+
+	file, err := os.Open(path)
+	assert.Nil(t, err)
+	defer file.Close()
+	messages = nil
+	for msg, err := range ReadMbox(file) {
+		assert.Nil(t, err)
+		messages = append(messages, string(msg))
+	}
+	assert.Equal(t, []string{"Subject: one\n\nFrom here\n"}, messages)
+}
+
+func TestEMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.eml", "a.EML", "notes.txt"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "sub.eml"), 0o700))
+
+	files, err := EMLFiles(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.EML"), filepath.Join(dir, "b.eml")}, files)
+
+	files, err = EMLFiles(filepath.Join(dir, "notes.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "notes.txt")}, files)
+
+	_, err = EMLFiles(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,145 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Stdin is the argument standing for the message IDs read from stdin
+const Stdin = "-"
+
+// The statuses of Item
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// MessageIDs returns the message IDs of the arguments, reading them newline-delimited from stdin in place of Stdin.
+// Blank lines and duplicates are dropped.
+func MessageIDs(args []string, stdin io.Reader) ([]string, error) {
+	ids := []string{}
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, arg := range args {
+		if arg != Stdin {
+			add(arg)
+			continue
+		}
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			add(scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+type Options struct {
+	MessageIDs  []string
+	Concurrency int // maximum number of concurrent operations, 1 if not positive
+}
+
+// Item is the outcome of the operation on a message ID
+type Item struct {
+	MessageID string `json:"messageID"`
+	Status    string `json:"status"`
+	Result    any    `json:"result,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Result struct {
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Items     []Item `json:"items"`
+}
+
+// ListItems returns the items, which the ndjson output writes one per line
+func (r *Result) ListItems() []any {
+	items := make([]any, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = item
+	}
+	return items
+}
+
+func (r *Result) TableHeader() []string {
+	return []string{"ID", "STATUS", "ERROR"}
+}
+
+func (r *Result) TableRows() [][]any {
+	rows := make([][]any, len(r.Items))
+	for i, item := range r.Items {
+		rows[i] = []any{item.MessageID, item.Status, item.Error}
+	}
+	return rows
+}
+
+// Run runs the operation on each message ID, such as trashing or getting the email, reporting the items in the order of the IDs.
+// A failed operation is reported in the result without stopping the others.
+func Run(ctx context.Context, options Options, operation func(ctx context.Context, messageID string) (any, error)) *Result {
+	result := &Result{Items: make([]Item, len(options.MessageIDs))}
+	_ = Each(len(options.MessageIDs), options.Concurrency, func(i int) error {
+		item := &result.Items[i]
+		item.MessageID = options.MessageIDs[i]
+		value, err := operation(ctx, item.MessageID)
+		if err != nil {
+			item.Status = StatusFailed
+			item.Error = err.Error()
+			return nil
+		}
+		item.Status = StatusOK
+		item.Result = value
+		return nil
+	})
+
+	for _, item := range result.Items {
+		if item.Status == StatusOK {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// Each calls fn with each index below n, up to concurrency calls at a time (1 if not positive),
+// and returns the error of the first failed index once all the calls are done.
+// The calls run concurrently, so fn should only write to the state of its own index.
+func Each(n, concurrency int, fn func(i int) error) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	errs := make([]error, n)
+	semaphore := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i := range n {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
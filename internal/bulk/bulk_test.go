@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageIDs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		stdin    string
+		expected []string
+	}{
+		{args: []string{"id-1"}, expected: []string{"id-1"}},
+		{args: []string{"id-1", "id-2", "id-1"}, expected: []string{"id-1", "id-2"}},
+		{args: []string{"-"}, stdin: "id-1\n\n  id-2  \r\nid-3", expected: []string{"id-1", "id-2", "id-3"}},
+		{args: []string{"id-0", "-"}, stdin: "id-1\nid-0\n", expected: []string{"id-0", "id-1"}},
+		{args: []string{"-"}, stdin: "\n", expected: []string{}},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ids, err := MessageIDs(test.args, strings.NewReader(test.stdin))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, ids)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	var running, peak atomic.Int32
+	result := Run(context.Background(), Options{MessageIDs: []string{"a", "b", "c", "d", "e"}, Concurrency: 2},
+		func(_ context.Context, messageID string) (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			if messageID == "c" {
+				return nil, errors.New("not found")
+			}
+			return strings.ToUpper(messageID), nil
+		})
+
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+	assert.Equal(t, 4, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Len(t, result.Items, 5)
+	assert.Equal(t, Item{MessageID: "a", Status: StatusOK, Result: "A"}, result.Items[0])
+	assert.Equal(t, Item{MessageID: "c", Status: StatusFailed, Error: "not found"}, result.Items[2])
+	assert.Equal(t, "e", result.Items[4].MessageID)
+
+	result = Run(context.Background(), Options{}, func(context.Context, string) (any, error) { return nil, nil })
+	assert.Equal(t, &Result{Items: []Item{}}, result)
+}
+
+func TestEach(t *testing.T) {
+	var calls atomic.Int32
+	done := make([]bool, 5)
+	err := Each(len(done), 0, func(i int) error {
+		calls.Add(1)
+		done[i] = true
+		if i >= 2 {
+			return errors.New("failed " + strconv.Itoa(i))
+		}
+		return nil
+	})
+	// every index is called, and the error is the one of the first failed index
+	assert.EqualError(t, err, "failed 2")
+	assert.Equal(t, int32(5), calls.Load())
+	assert.Equal(t, []bool{true, true, true, true, true}, done)
+
+	assert.Nil(t, Each(0, 4, func(int) error { return errors.New("unexpected") }))
+}
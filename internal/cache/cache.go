@@ -0,0 +1,322 @@
+package cache
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ErrNotCached = errors.New("not found in the offline cache")
+
+var (
+	bucketEmails     = []byte("emails")
+	bucketPartitions = []byte("partitions")
+)
+
+// lockTimeout limits how long to wait for another process using the cache, such as a running sync
+const lockTimeout = 5 * time.Second
+
+// Store is the offline cache of emails, backed by a bbolt database
+type Store struct {
+	db *bolt.DB
+}
+
+// Path returns the location of the cache for the API, which is a file in $XDG_CACHE_HOME/mailbox-cli.
+// Each API has its own cache, so that switching profiles never mixes emails of different mailboxes.
+func Path(apiID, region, endpoint string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mailbox-cli", apiKey(apiID, region, endpoint)+".db"), nil
+}
+
+// StatePath returns the location of a small state file of the API next to its cache, such as the last email seen by watch
+func StatePath(apiID, region, endpoint, name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mailbox-cli", apiKey(apiID, region, endpoint)+"-"+name+".json"), nil
+}
+
+// apiKey identifies the API in file names
+func apiKey(apiID, region, endpoint string) string {
+	sum := sha256.Sum256([]byte(apiID + "\n" + region + "\n" + endpoint))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Open opens the cache at path, creating it if needed
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open cache %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketEmails, bucketPartitions} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return ensureIndex(tx)
+	})
+	if err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens an existing cache at path for reading, which may happen concurrently with other readers
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no cache exists yet, run sync first", ErrNotCached)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("open cache %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Partition identifies the emails of a type that the API lists for a year and month
+type Partition struct {
+	Type  string
+	Year  int
+	Month time.Month
+}
+
+// PartitionOf returns the partition of the type containing the time
+func PartitionOf(emailType string, t time.Time) Partition {
+	return Partition{Type: emailType, Year: t.Year(), Month: t.Month()}
+}
+
+// YearString returns the year in the format of the list API
+func (p Partition) YearString() string {
+	return strconv.Itoa(p.Year)
+}
+
+// MonthString returns the month in the format of the list API
+func (p Partition) MonthString() string {
+	return fmt.Sprintf("%02d", int(p.Month))
+}
+
+// Previous returns the partition of the same type for the previous month
+func (p Partition) Previous() Partition {
+	t := time.Date(p.Year, p.Month, 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	return PartitionOf(p.Type, t)
+}
+
+// End returns the start of the next month, after which no email is added to the partition
+func (p Partition) End() time.Time {
+	return time.Date(p.Year, p.Month, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+}
+
+func (p Partition) String() string {
+	return fmt.Sprintf("%s/%04d-%02d", p.Type, p.Year, int(p.Month))
+}
+
+// PartitionState records the last sync of a partition
+type PartitionState struct {
+	SyncedAt time.Time `json:"syncedAt"`
+	// Complete is set once the partition has been listed after the month ended, so it doesn't need to be listed again
+	Complete bool `json:"complete"`
+	// NoBodies is set if the partition was listed without fetching the bodies, so a sync with bodies lists it again
+	NoBodies bool `json:"noBodies,omitempty"`
+}
+
+// Entry is a cached email along with the partition it was listed in
+type Entry struct {
+	Email     email.Email `json:"email"`
+	Partition string      `json:"partition"`
+	HasBody   bool        `json:"hasBody"` // whether the text and HTML were fetched
+}
+
+// PartitionState returns the state of the partition, and whether it has been synced before
+func (s *Store) PartitionState(p Partition) (PartitionState, bool, error) {
+	var state PartitionState
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketPartitions).Get([]byte(p.String()))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &state)
+	})
+	return state, found, err
+}
+
+// SetPartitionState records the state of the partition
+func (s *Store) SetPartitionState(p Partition, state PartitionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPartitions).Put([]byte(p.String()), data)
+	})
+}
+
+// Entry returns the cached email with the message ID
+func (s *Store) Entry(messageID string) (Entry, bool, error) {
+	var entry Entry
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketEmails).Get([]byte(messageID))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &entry)
+	})
+	return entry, found, err
+}
+
+// Put stores and indexes the email, replacing the cached email with the same message ID
+func (s *Store) Put(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketEmails).Put([]byte(entry.Email.MessageID), data); err != nil {
+			return err
+		}
+		return indexEmail(tx, entry.Email)
+	})
+}
+
+// Prune removes the cached emails of the partition that aren't kept, returning the number of removed emails
+func (s *Store) Prune(p Partition, keep map[string]bool) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketEmails)
+		var ids [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var entry Entry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if entry.Partition == p.String() && !keep[string(k)] {
+				ids = append(ids, slices.Clone(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := bucket.Delete(id); err != nil {
+				return err
+			}
+			if err := unindexEmail(tx, string(id)); err != nil {
+				return err
+			}
+		}
+		removed = len(ids)
+		return nil
+	})
+	return removed, err
+}
+
+// Entries returns all cached emails matching the filter
+func (s *Store) Entries(filter func(Entry) bool) ([]Entry, error) {
+	var entries []Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEmails).ForEach(func(_, v []byte) error {
+			var entry Entry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if filter == nil || filter(entry) {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Get returns the cached email, which must have been synced with its body
+func (s *Store) Get(messageID string) (*email.Email, error) {
+	entry, found, err := s.Entry(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrNotCached, messageID)
+	}
+	if !entry.HasBody {
+		return nil, fmt.Errorf("%w: %s: only the metadata was synced", ErrNotCached, messageID)
+	}
+	return &entry.Email, nil
+}
+
+// List returns the cached emails like the list API, without pagination.
+// Unlike the API, the year and month are optional, so that all synced emails of a type can be listed at once.
+func (s *Store) List(options email.ListOptions) (*email.ListResult, error) {
+	if options.Order != "" && options.Order != email.OrderAsc && options.Order != email.OrderDesc {
+		return nil, errors.New("invalid order")
+	}
+
+	entries, err := s.Entries(func(entry Entry) bool {
+		if options.Type != "" && entry.Email.Type != options.Type {
+			return false
+		}
+		var year, month string
+		if len(entry.Partition) >= 7 {
+			// the partition ends with YYYY-MM
+			year, month = entry.Partition[len(entry.Partition)-7:len(entry.Partition)-3], entry.Partition[len(entry.Partition)-2:]
+		}
+		if options.Year != "" && options.Year != year {
+			return false
+		}
+		return options.Month == "" || monthEqual(options.Month, month)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]email.Email, len(entries))
+	for i, entry := range entries {
+		item := entry.Email
+		// the list API only returns the metadata
+		item.Text, item.HTML = "", ""
+		items[i] = item
+	}
+	slices.SortStableFunc(items, func(a, b email.Email) int {
+		if options.Order == email.OrderAsc {
+			return cmp.Compare(a.Time().UnixNano(), b.Time().UnixNano())
+		}
+		return cmp.Compare(b.Time().UnixNano(), a.Time().UnixNano())
+	})
+	return &email.ListResult{Count: len(items), Items: items}, nil
+}
+
+// monthEqual compares months regardless of zero padding, such as 1 and 01
+func monthEqual(a, b string) bool {
+	x, errX := strconv.Atoi(a)
+	y, errY := strconv.Atoi(b)
+	if errX != nil || errY != nil {
+		return a == b
+	}
+	return x == y
+}
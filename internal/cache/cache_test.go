@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/xdg")
+	path, err := Path("api-id", "us-east-1", "")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("/xdg", "mailbox-cli"), filepath.Dir(path))
+
+	other, err := Path("other-id", "us-east-1", "")
+	assert.Nil(t, err)
+	assert.NotEqual(t, path, other)
+
+	state, err := StatePath("api-id", "us-east-1", "", "watch")
+	assert.Nil(t, err)
+	assert.Equal(t, strings.TrimSuffix(path, ".db")+"-watch.json", state)
+}
+
+func TestPartition(t *testing.T) {
+	p := PartitionOf(email.EmailTypeInbox, time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "inbox/2025-01", p.String())
+	assert.Equal(t, "2025", p.YearString())
+	assert.Equal(t, "01", p.MonthString())
+	assert.Equal(t, "inbox/2024-12", p.Previous().String())
+	assert.Equal(t, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), p.End())
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mailbox-cli", "cache.db")
+
+	_, err := OpenReadOnly(path)
+	assert.ErrorIs(t, err, ErrNotCached)
+
+	store, err := Open(path)
+	assert.Nil(t, err)
+
+	january := Partition{Type: email.EmailTypeInbox, Year: 2025, Month: time.January}
+	_, found, err := store.PartitionState(january)
+	assert.Nil(t, err)
+	assert.False(t, found)
+	syncedAt := time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC)
+	err = store.SetPartitionState(january, PartitionState{SyncedAt: syncedAt, Complete: true})
+	assert.Nil(t, err)
+	state, found, err := store.PartitionState(january)
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, PartitionState{SyncedAt: syncedAt, Complete: true}, state)
+
+	for _, entry := range []Entry{
+		{
+			Email: email.Email{
+				MessageID: "1", Type: email.EmailTypeInbox, Subject: "first", Text: "text",
+				TimeReceived: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Partition: january.String(),
+			HasBody:   true,
+		},
+		{
+			Email: email.Email{
+				MessageID: "2", Type: email.EmailTypeInbox, Subject: "second",
+				TimeReceived: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+			},
+			Partition: january.String(),
+		},
+		{
+			Email: email.Email{
+				MessageID: "3", Type: email.EmailTypeSent, Subject: "third",
+				TimeSent: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Partition: "sent/2025-02",
+			HasBody:   true,
+		},
+	} {
+		err = store.Put(entry)
+		assert.Nil(t, err)
+	}
+	err = store.Close()
+	assert.Nil(t, err)
+
+	store, err = OpenReadOnly(path)
+	assert.Nil(t, err)
+
+	result, err := store.List(email.ListOptions{Type: email.EmailTypeInbox, Year: "2025", Month: "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, "2", result.Items[0].MessageID)
+	assert.Equal(t, "1", result.Items[1].MessageID)
+	assert.Empty(t, result.Items[1].Text)
+
+	result, err = store.List(email.ListOptions{Order: email.OrderAsc})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Count)
+	assert.Equal(t, "1", result.Items[0].MessageID)
+
+	result, err = store.List(email.ListOptions{Type: email.EmailTypeSent, Month: "01"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+
+	_, err = store.List(email.ListOptions{Order: "invalid"})
+	assert.NotNil(t, err)
+
+	got, err := store.Get("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "text", got.Text)
+	_, err = store.Get("2")
+	assert.ErrorIs(t, err, ErrNotCached)
+	_, err = store.Get("4")
+	assert.ErrorIs(t, err, ErrNotCached)
+	err = store.Close()
+	assert.Nil(t, err)
+
+	store, err = Open(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	removed, err := store.Prune(january, map[string]bool{"2": true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, removed)
+	entries, err := store.Entries(nil)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+}
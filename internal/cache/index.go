@@ -0,0 +1,249 @@
+package cache
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The inverted index of the cached emails, maintained along with the emails bucket:
+// postings maps "term\x00messageID" to the term frequency, documents maps a message ID to its indexed terms and length,
+// and meta holds the totals needed for ranking.
+var (
+	bucketPostings  = []byte("postings")
+	bucketDocuments = []byte("documents")
+	bucketMeta      = []byte("meta")
+)
+
+var (
+	metaVersion = []byte("version")
+	metaDocs    = []byte("docs")
+	metaLength  = []byte("length")
+)
+
+// indexVersion changes whenever the way emails are indexed changes, so that older indexes are rebuilt
+const indexVersion = 1
+
+// document records what was indexed for an email, so that it can be removed from the index
+type document struct {
+	Length int      `json:"length"`
+	Terms  []string `json:"terms"`
+}
+
+func postingKey(term, messageID string) []byte {
+	return []byte(term + "\x00" + messageID)
+}
+
+func getUint(bucket *bolt.Bucket, key []byte) uint64 {
+	value, _ := binary.Uvarint(bucket.Get(key))
+	return value
+}
+
+func putUint(bucket *bolt.Bucket, key []byte, value uint64) error {
+	return bucket.Put(key, binary.AppendUvarint(nil, value))
+}
+
+// addInt adds delta to the counter, which never goes below zero
+func addInt(bucket *bolt.Bucket, key []byte, delta int) error {
+	value := int(getUint(bucket, key)) + delta
+	return putUint(bucket, key, uint64(max(value, 0)))
+}
+
+// indexEmail adds the email to the index, replacing what was indexed for the same message ID
+func indexEmail(tx *bolt.Tx, e email.Email) error {
+	if err := unindexEmail(tx, e.MessageID); err != nil {
+		return err
+	}
+
+	terms, length := search.DocumentTerms(e)
+	postings := tx.Bucket(bucketPostings)
+	doc := document{Length: length, Terms: make([]string, 0, len(terms))}
+	for term, tf := range terms {
+		if err := putUint(postings, postingKey(term, e.MessageID), uint64(tf)); err != nil {
+			return err
+		}
+		doc.Terms = append(doc.Terms, term)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketDocuments).Put([]byte(e.MessageID), data); err != nil {
+		return err
+	}
+
+	meta := tx.Bucket(bucketMeta)
+	if err := addInt(meta, metaDocs, 1); err != nil {
+		return err
+	}
+	return addInt(meta, metaLength, length)
+}
+
+// unindexEmail removes the email from the index, if it was indexed
+func unindexEmail(tx *bolt.Tx, messageID string) error {
+	documents := tx.Bucket(bucketDocuments)
+	data := documents.Get([]byte(messageID))
+	if data == nil {
+		return nil
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	postings := tx.Bucket(bucketPostings)
+	for _, term := range doc.Terms {
+		if err := postings.Delete(postingKey(term, messageID)); err != nil {
+			return err
+		}
+	}
+	if err := documents.Delete([]byte(messageID)); err != nil {
+		return err
+	}
+
+	meta := tx.Bucket(bucketMeta)
+	if err := addInt(meta, metaDocs, -1); err != nil {
+		return err
+	}
+	return addInt(meta, metaLength, -doc.Length)
+}
+
+// ensureIndex rebuilds the index from the cached emails if it is missing or outdated
+func ensureIndex(tx *bolt.Tx) error {
+	if meta := tx.Bucket(bucketMeta); meta != nil && getUint(meta, metaVersion) == indexVersion {
+		return nil
+	}
+
+	for _, name := range [][]byte{bucketPostings, bucketDocuments, bucketMeta} {
+		if tx.Bucket(name) != nil {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	err := tx.Bucket(bucketEmails).ForEach(func(_, v []byte) error {
+		var entry Entry
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return err
+		}
+		return indexEmail(tx, entry.Email)
+	})
+	if err != nil {
+		return err
+	}
+	return putUint(tx.Bucket(bucketMeta), metaVersion, indexVersion)
+}
+
+// Search finds the cached emails containing all terms of the query and passing its filters, ranked by BM25.
+// Without terms, the matching emails are returned newest first.
+// At most limit hits are returned, unless limit is zero.
+func (s *Store) Search(query search.Query, limit int) (*search.Result, error) {
+	terms := slices.Compact(slices.Sorted(slices.Values(query.Terms)))
+
+	hits := []search.Hit{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(bucketMeta)
+		if meta == nil || getUint(meta, metaVersion) != indexVersion {
+			return fmt.Errorf("%w: the search index hasn't been built yet, run sync first", ErrNotCached)
+		}
+		emails := tx.Bucket(bucketEmails)
+
+		if len(terms) == 0 {
+			return emails.ForEach(func(_, v []byte) error {
+				var entry Entry
+				if err := json.Unmarshal(v, &entry); err != nil {
+					return err
+				}
+				if query.Match(entry.Email) {
+					hits = append(hits, newHit(entry.Email, 0, nil))
+				}
+				return nil
+			})
+		}
+
+		// the frequency of each term by message ID, keeping only the emails containing every term
+		frequencies := map[string][]int{}
+		dfs := make([]int, len(terms))
+		for i, term := range terms {
+			prefix := []byte(term + "\x00")
+			matched := map[string][]int{}
+			c := tx.Bucket(bucketPostings).Cursor()
+			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				dfs[i]++
+				id := string(k[len(prefix):])
+				tf, _ := binary.Uvarint(v)
+				if i == 0 {
+					matched[id] = []int{int(tf)}
+				} else if previous, ok := frequencies[id]; ok {
+					matched[id] = append(previous, int(tf))
+				}
+			}
+			frequencies = matched
+			if len(frequencies) == 0 {
+				return nil
+			}
+		}
+
+		docs := int(getUint(meta, metaDocs))
+		avgLength := 0.0
+		if docs > 0 {
+			avgLength = float64(getUint(meta, metaLength)) / float64(docs)
+		}
+		documents := tx.Bucket(bucketDocuments)
+		for id, tfs := range frequencies {
+			var entry Entry
+			if err := json.Unmarshal(emails.Get([]byte(id)), &entry); err != nil {
+				return err
+			}
+			if !query.Match(entry.Email) {
+				continue
+			}
+			var doc document
+			if err := json.Unmarshal(documents.Get([]byte(id)), &doc); err != nil {
+				return err
+			}
+			score := 0.0
+			for i, tf := range tfs {
+				score += search.BM25(tf, dfs[i], docs, float64(doc.Length), avgLength)
+			}
+			hits = append(hits, newHit(entry.Email, score, terms))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(hits, func(a, b search.Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Time().UnixNano(), a.Time().UnixNano())
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return &search.Result{Count: len(hits), Items: hits}, nil
+}
+
+// newHit returns the hit of the email, with a snippet of the body or subject highlighting the terms
+func newHit(e email.Email, score float64, terms []string) search.Hit {
+	text := search.Body(e)
+	if text == "" {
+		text = e.Subject
+	}
+	snippet := search.Snippet(text, terms)
+	// like the list API, hits only carry the metadata
+	e.Text, e.HTML = "", ""
+	return search.Hit{Email: e, Score: score, Snippet: snippet}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_Search(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	store, err := Open(path)
+	assert.Nil(t, err)
+
+	january := Partition{Type: email.EmailTypeInbox, Year: 2025, Month: time.January}
+	for _, e := range []email.Email{
+		{
+			MessageID: "1", Type: email.EmailTypeInbox, Subject: "Quarterly report",
+			Text:         "Please find the report attached",
+			TimeReceived: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			MessageID: "2", Type: email.EmailTypeInbox, Subject: "Lunch",
+			Text:         "Shall we discuss the report over lunch?",
+			TimeReceived: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			MessageID: "3", Type: email.EmailTypeInbox, Subject: "Weekend",
+			HTML:         "<p>Nothing to do with work</p>",
+			TimeReceived: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC),
+		},
+	} {
+		err = store.Put(Entry{Email: e, Partition: january.String(), HasBody: true})
+		assert.Nil(t, err)
+	}
+
+	// the email with the term in its subject ranks first
+	result, err := store.Search(search.Query{Terms: []string{"report"}}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, "1", result.Items[0].MessageID)
+	assert.Equal(t, "Please find the **report** attached", result.Items[0].Snippet)
+	assert.Empty(t, result.Items[0].Text)
+
+	// every term must match
+	result, err = store.Search(search.Query{Terms: []string{"report", "lunch"}}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "2", result.Items[0].MessageID)
+
+	// without terms, the emails passing the filters are listed newest first
+	result, err = store.Search(search.Query{Since: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)}, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "3", result.Items[0].MessageID)
+
+	// replacing and pruning emails updates the index
+	err = store.Put(Entry{Email: email.Email{MessageID: "2", Type: email.EmailTypeInbox, Subject: "Dinner"}, Partition: january.String()})
+	assert.Nil(t, err)
+	result, err = store.Search(search.Query{Terms: []string{"lunch"}}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+	_, err = store.Prune(january, map[string]bool{"2": true, "3": true})
+	assert.Nil(t, err)
+	result, err = store.Search(search.Query{Terms: []string{"report"}}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+
+	// a missing index is rebuilt when opening the cache
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(bucketMeta)
+	})
+	assert.Nil(t, err)
+	_, err = store.Search(search.Query{Terms: []string{"work"}}, 0)
+	assert.ErrorIs(t, err, ErrNotCached)
+	err = store.Close()
+	assert.Nil(t, err)
+
+	store, err = Open(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	result, err = store.Search(search.Query{Terms: []string{"work"}}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "Nothing to do with **work**", result.Items[0].Snippet)
+}
@@ -1,27 +1,49 @@
 package command
 
-import "github.com/harryzcy/mailbox-cli/internal/email"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/harryzcy/mailbox-cli/internal/archive"
+	"github.com/harryzcy/mailbox-cli/internal/bulk"
+	"github.com/harryzcy/mailbox-cli/internal/compose"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/markdown"
+	"github.com/harryzcy/mailbox-cli/internal/message"
+	"github.com/harryzcy/mailbox-cli/internal/template"
+)
 
 type GetOptions struct {
 	// client options
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
 	MessageID string
+	Offline   bool // serve from the offline cache without making requests
 }
 
-func Get(options GetOptions) (string, error) {
+func Get(ctx context.Context, options GetOptions) (*email.Email, error) {
+	if options.Offline {
+		return getOffline(options)
+	}
+
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Get(email.GetOptions{
+	result, err := client.Get(ctx, email.GetOptions{
 		MessageID: options.MessageID,
 	})
 
@@ -33,6 +55,7 @@ type ListOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
@@ -41,25 +64,52 @@ type ListOptions struct {
 	Month      string
 	Order      string // asc or desc (default)
 	NextCursor string
+
+	// pagination options
+	All   bool // follow cursors until all emails are listed
+	Limit int  // stop after this many emails, following cursors if needed
+
+	Offline bool // serve from the offline cache without making requests
 }
 
-func List(options ListOptions) (string, error) {
+func List(ctx context.Context, options ListOptions) (*email.ListResult, error) {
+	if options.Offline {
+		return listOffline(options)
+	}
+
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.List(email.ListOptions{
+	listOptions := email.ListOptions{
 		Type:       options.Type,
 		Year:       options.Year,
 		Month:      options.Month,
 		Order:      options.Order,
 		NextCursor: options.NextCursor,
-	})
+	}
+	if !options.All && options.Limit <= 0 {
+		return client.List(ctx, listOptions)
+	}
 
-	return result, err
+	// the aggregated result spans multiple pages, so it has no next cursor
+	result := &email.ListResult{Items: []email.Email{}}
+	for item, err := range client.ListAll(ctx, listOptions) {
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, item)
+		if options.Limit > 0 && len(result.Items) >= options.Limit {
+			break
+		}
+	}
+	result.Count = len(result.Items)
+
+	return result, nil
 }
 
 type TrashOptions struct {
@@ -67,21 +117,23 @@ type TrashOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
 	MessageID string
 }
 
-func Trash(options TrashOptions) (string, error) {
+func Trash(ctx context.Context, options TrashOptions) (*email.ActionResult, error) {
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Trash(email.TrashOptions{
+	result, err := client.Trash(ctx, email.TrashOptions{
 		MessageID: options.MessageID,
 	})
 
@@ -93,21 +145,23 @@ type UntrashOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
 	MessageID string
 }
 
-func Untrash(options UntrashOptions) (string, error) {
+func Untrash(ctx context.Context, options UntrashOptions) (*email.ActionResult, error) {
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Untrash(email.UntrashOptions{
+	result, err := client.Untrash(ctx, email.UntrashOptions{
 		MessageID: options.MessageID,
 	})
 
@@ -119,21 +173,23 @@ type DeleteOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
 	MessageID string
 }
 
-func Delete(options DeleteOptions) (string, error) {
+func Delete(ctx context.Context, options DeleteOptions) (*email.ActionResult, error) {
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Delete(email.DeleteOptions{
+	result, err := client.Delete(ctx, email.DeleteOptions{
 		MessageID: options.MessageID,
 	})
 
@@ -145,6 +201,7 @@ type CreateOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
@@ -159,18 +216,53 @@ type CreateOptions struct {
 	GenerateText string
 	Send         bool
 
+	Attachments []string // path[;type=...;name=...]
+	Inlines     []string // cid=path[;type=...;name=...]
+
+	Markdown  string // path of a Markdown file rendered to both the text and HTML body
+	InlineCSS bool   // add inline styles to the rendered HTML
+
+	Template string   // path of a YAML template; the other options override its fields
+	Vars     []string // template variables in the format of key=value
+
 	File string
 }
 
-func Create(options CreateOptions) (string, error) {
+func Create(ctx context.Context, options CreateOptions) (*email.Email, error) {
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Create(email.CreateOptions{
+	attachments, inlines, err := parseAttachments(options.Attachments, options.Inlines)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Markdown != "" {
+		if options.Text != "" || options.HTML != "" {
+			return nil, errMarkdownConflict
+		}
+		options.HTML, options.Text, err = renderMarkdown(options.Markdown, options.InlineCSS)
+		if err != nil {
+			return nil, err
+		}
+		options.GenerateText = email.GenerateTextOff
+	}
+
+	if options.Template != "" {
+		if err := applyTemplate(&options); err != nil {
+			return nil, err
+		}
+	}
+	if err := expandContacts(&options.From, &options.To, &options.Cc, &options.Bcc, &options.ReplyTo); err != nil {
+		return nil, err
+	}
+
+	result, err := client.Create(ctx, email.CreateOptions{
 		Subject:      options.Subject,
 		From:         options.From,
 		To:           options.To,
@@ -181,6 +273,8 @@ func Create(options CreateOptions) (string, error) {
 		HTML:         options.HTML,
 		GenerateText: options.GenerateText,
 		Send:         options.Send,
+		Attachments:  attachments,
+		Inlines:      inlines,
 		File:         options.File,
 	})
 
@@ -192,6 +286,7 @@ type SaveOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
@@ -208,18 +303,44 @@ type SaveOptions struct {
 	GenerateText string
 	Send         bool
 
+	Attachments []string // path[;type=...;name=...]
+	Inlines     []string // cid=path[;type=...;name=...]
+
+	Markdown  string // path of a Markdown file rendered to both the text and HTML body
+	InlineCSS bool   // add inline styles to the rendered HTML
+
 	File string
 }
 
-func Save(options SaveOptions) (string, error) {
+func Save(ctx context.Context, options SaveOptions) (*email.Email, error) {
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Save(email.SaveOptions{
+	attachments, inlines, err := parseAttachments(options.Attachments, options.Inlines)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Markdown != "" {
+		if options.Body != "" || options.Text != "" || options.HTML != "" {
+			return nil, errMarkdownConflict
+		}
+		options.HTML, options.Text, err = renderMarkdown(options.Markdown, options.InlineCSS)
+		if err != nil {
+			return nil, err
+		}
+		options.GenerateText = email.GenerateTextOff
+	}
+	if err := expandContacts(&options.From, &options.To, &options.Cc, &options.Bcc, &options.ReplyTo); err != nil {
+		return nil, err
+	}
+
+	result, err := client.Save(ctx, email.SaveOptions{
 		MessageID:    options.MessageID,
 		Subject:      options.Subject,
 		From:         options.From,
@@ -232,6 +353,8 @@ func Save(options SaveOptions) (string, error) {
 		HTML:         options.HTML,
 		GenerateText: options.GenerateText,
 		Send:         options.Send,
+		Attachments:  attachments,
+		Inlines:      inlines,
 		File:         options.File,
 	})
 
@@ -243,23 +366,962 @@ type SendOptions struct {
 	APIID    string
 	Region   string
 	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	MessageID string
+}
+
+func Send(ctx context.Context, options SendOptions) (*email.ActionResult, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	result, err := client.Send(ctx, email.SendOptions{
+		MessageID: options.MessageID,
+	})
+
+	return result, err
+}
+
+// parseAttachments parses the attachment and inline specs from the command line
+func parseAttachments(attachmentSpecs, inlineSpecs []string) (attachments, inlines []email.FileAttachment, err error) {
+	for _, spec := range attachmentSpecs {
+		attachment, err := email.ParseAttachment(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	for _, spec := range inlineSpecs {
+		inline, err := email.ParseInline(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		inlines = append(inlines, inline)
+	}
+	return attachments, inlines, nil
+}
+
+var errMarkdownConflict = errors.New("markdown can't be combined with body, text or html")
+
+// renderMarkdown reads the Markdown file and renders it to the HTML and text body
+func renderMarkdown(path string, inlineCSS bool) (string, string, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	return markdown.Render(source, markdown.Options{InlineCSS: inlineCSS})
+}
+
+// applyTemplate fills in the fields of the options that aren't set from the executed template
+func applyTemplate(options *CreateOptions) error {
+	tmpl, err := template.Load(options.Template)
+	if err != nil {
+		return err
+	}
+	vars, err := template.ParseVars(options.Vars)
+	if err != nil {
+		return err
+	}
+	rendered, err := tmpl.Execute(vars)
+	if err != nil {
+		return err
+	}
+
+	if options.Subject == "" {
+		options.Subject = rendered.Subject
+	}
+	for _, field := range []struct {
+		dest     *[]string
+		rendered []string
+	}{
+		{&options.From, rendered.From},
+		{&options.To, rendered.To},
+		{&options.Cc, rendered.Cc},
+		{&options.Bcc, rendered.Bcc},
+		{&options.ReplyTo, rendered.ReplyTo},
+	} {
+		if len(*field.dest) == 0 {
+			*field.dest = field.rendered
+		}
+	}
+	if options.Text == "" && options.HTML == "" {
+		options.Text = rendered.Text
+		options.HTML = rendered.HTML
+		if rendered.GenerateText != "" {
+			options.GenerateText = rendered.GenerateText
+		}
+	}
+	return nil
+}
+
+// retryPolicy returns the policy for the number of retries, or nil to disable retries
+func retryPolicy(retries int) *email.RetryPolicy {
+	if retries <= 0 {
+		return nil
+	}
+	return email.NewRetryPolicy(retries)
+}
+
+type ListAttachmentsOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
 	Verbose  bool
 
 	// request options
 	MessageID string
 }
 
-func Send(options SendOptions) (string, error) {
+func ListAttachments(ctx context.Context, options ListAttachmentsOptions) (*email.AttachmentsResult, error) {
 	client := email.Client{
-		APIID:    options.APIID,
-		Region:   options.Region,
-		Endpoint: options.Endpoint,
-		Verbose:  options.Verbose,
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
 	}
 
-	result, err := client.Send(email.SendOptions{
+	result, err := client.ListAttachments(ctx, email.ListAttachmentsOptions{
 		MessageID: options.MessageID,
 	})
 
 	return result, err
 }
+
+type DownloadAttachmentsOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	MessageID string
+	Name      string // filename or content ID of the file to download
+	All       bool   // download all attachments and inline files
+	Dir       string // output directory, defaults to the current directory
+}
+
+// DownloadedFile describes a file saved by DownloadAttachments
+type DownloadedFile struct {
+	ContentID string `json:"contentID"`
+	Filename  string `json:"filename"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+}
+
+type DownloadAttachmentsResult struct {
+	MessageID string           `json:"messageID"`
+	Files     []DownloadedFile `json:"files"`
+}
+
+func DownloadAttachments(ctx context.Context, options DownloadAttachmentsOptions) (*DownloadAttachmentsResult, error) {
+	if options.Name == "" && !options.All {
+		return nil, errors.New("either name or all is required")
+	}
+
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	list, err := client.ListAttachments(ctx, email.ListAttachmentsOptions{
+		MessageID: options.MessageID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type selected struct {
+		attachment email.Attachment
+		inline     bool
+	}
+	var files []selected
+	for _, attachment := range list.Attachments {
+		if options.All || attachment.Filename == options.Name || attachment.ContentID == options.Name {
+			files = append(files, selected{attachment: attachment})
+		}
+	}
+	for _, inline := range list.Inlines {
+		if options.All || inline.Filename == options.Name || inline.ContentID == options.Name {
+			files = append(files, selected{attachment: inline, inline: true})
+		}
+	}
+	if len(files) == 0 && !options.All {
+		return nil, fmt.Errorf("no attachment named %q", options.Name)
+	}
+
+	dir := options.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	result := &DownloadAttachmentsResult{MessageID: options.MessageID, Files: []DownloadedFile{}}
+	for _, file := range files {
+		downloaded, err := downloadAttachment(ctx, &client, dir, email.DownloadAttachmentOptions{
+			MessageID: options.MessageID,
+			ContentID: file.attachment.ContentID,
+			Inline:    file.inline,
+		}, file.attachment.Filename)
+		if err != nil {
+			return result, err
+		}
+		result.Files = append(result.Files, *downloaded)
+	}
+
+	return result, nil
+}
+
+func downloadAttachment(ctx context.Context, client *email.Client, dir string, options email.DownloadAttachmentOptions, filename string) (_ *DownloadedFile, err error) {
+	if filename == "" {
+		filename = options.ContentID
+	}
+	file, err := createUniqueFile(dir, sanitizeFilename(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+		if err != nil {
+			// don't leave partial downloads behind
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	size, err := client.DownloadAttachment(ctx, options, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DownloadedFile{
+		ContentID: options.ContentID,
+		Filename:  filename,
+		Path:      file.Name(),
+		Size:      size,
+	}, nil
+}
+
+type ExportOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Type  string
+	Year  string
+	Month string
+
+	Format string // mbox, maildir or eml
+	Dest   string // file for mbox, directory otherwise
+
+	// Progress is called after each exported email, if set
+	Progress func(e email.Email)
+}
+
+type ExportResult struct {
+	Format   string `json:"format"`
+	Dest     string `json:"dest"`
+	Exported int    `json:"exported"`
+	Skipped  int    `json:"skipped"` // exported by a previous run
+}
+
+// Export writes the emails to an archive. Exported message IDs are recorded in a progress file
+// next to the archive, so that running the same export again continues where it stopped.
+func Export(ctx context.Context, options ExportOptions) (*ExportResult, error) {
+	if options.Dest == "" {
+		return nil, errors.New("invalid destination")
+	}
+
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	writer, err := archive.NewWriter(options.Format, options.Dest)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := archive.OpenProgress(archive.ProgressPath(options.Format, options.Dest))
+	if err != nil {
+		return nil, errors.Join(err, writer.Close())
+	}
+	if err := progress.Resume(writer); err != nil {
+		return nil, errors.Join(err, progress.Close(), writer.Close())
+	}
+
+	result := &ExportResult{Format: options.Format, Dest: options.Dest}
+	err = exportEmails(ctx, &client, options, writer, progress, result)
+	err = errors.Join(err, progress.Close(), writer.Close())
+	return result, err
+}
+
+func exportEmails(ctx context.Context, client *email.Client, options ExportOptions, writer archive.Writer, progress *archive.Progress, result *ExportResult) error {
+	listOptions := email.ListOptions{
+		Type:  options.Type,
+		Year:  options.Year,
+		Month: options.Month,
+		Order: email.OrderAsc,
+	}
+	for item, err := range client.ListAll(ctx, listOptions) {
+		if err != nil {
+			return err
+		}
+		if progress.Done(item.MessageID) {
+			result.Skipped++
+			continue
+		}
+
+		raw, err := rawMessage(ctx, client, item)
+		if err != nil {
+			return fmt.Errorf("export %s: %w", item.MessageID, err)
+		}
+		if err := writer.Write(item, raw); err != nil {
+			return fmt.Errorf("export %s: %w", item.MessageID, err)
+		}
+		if err := progress.Add(writer, item.MessageID); err != nil {
+			return err
+		}
+
+		result.Exported++
+		if options.Progress != nil {
+			options.Progress(item)
+		}
+	}
+	return nil
+}
+
+// rawMessage returns the original MIME message of the email.
+// If the backend doesn't provide it, the message is composed from the email content.
+func rawMessage(ctx context.Context, client *email.Client, item email.Email) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	_, err := client.GetRaw(ctx, email.GetRawOptions{MessageID: item.MessageID}, buf)
+	if err == nil {
+		return buf.Bytes(), nil
+	}
+	var apiErr *email.APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		return nil, err
+	}
+
+	full, err := client.Get(ctx, email.GetOptions{MessageID: item.MessageID})
+	if err != nil {
+		return nil, err
+	}
+	return message.Build(*full)
+}
+
+type ImportOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Format string // eml or mbox
+	Path   string // .eml file or directory for eml, file for mbox
+}
+
+type ImportedMessage struct {
+	Source    string `json:"source"` // file path, with the message number for mbox
+	Subject   string `json:"subject,omitempty"`
+	MessageID string `json:"messageID,omitempty"` // ID of the created draft
+	Error     string `json:"error,omitempty"`
+}
+
+type ImportResult struct {
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Messages []ImportedMessage `json:"messages"`
+}
+
+// Import creates a draft for each message in the eml files or mbox file.
+// A message that can't be parsed or created is reported in the result without stopping the import.
+func Import(ctx context.Context, options ImportOptions) (*ImportResult, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	result := &ImportResult{Messages: []ImportedMessage{}}
+	importMessage := func(source string, raw []byte) error {
+		imported := createDraft(ctx, &client, source, raw)
+		if imported.Error != "" {
+			result.Failed++
+		} else {
+			result.Imported++
+		}
+		result.Messages = append(result.Messages, imported)
+		// stop early instead of reporting every remaining message as canceled
+		return ctx.Err()
+	}
+
+	var err error
+	switch options.Format {
+	case archive.FormatEML:
+		err = importEML(options.Path, importMessage)
+	case archive.FormatMbox:
+		err = importMbox(options.Path, importMessage)
+	default:
+		return nil, fmt.Errorf("%w: %s", archive.ErrInvalidFormat, options.Format)
+	}
+	return result, err
+}
+
+func importEML(path string, importMessage func(source string, raw []byte) error) error {
+	files, err := archive.EMLFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := importMessage(file, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importMbox(path string, importMessage func(source string, raw []byte) error) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	i := 0
+	for raw, err := range archive.ReadMbox(file) {
+		if err != nil {
+			return err
+		}
+		i++
+		if err := importMessage(fmt.Sprintf("%s#%d", path, i), raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createDraft(ctx context.Context, client *email.Client, source string, raw []byte) ImportedMessage {
+	imported := ImportedMessage{Source: source}
+	createOptions, err := message.Parse(bytes.NewReader(raw))
+	if err != nil {
+		imported.Error = err.Error()
+		return imported
+	}
+	imported.Subject = createOptions.Subject
+
+	created, err := client.Create(ctx, createOptions)
+	if err != nil {
+		imported.Error = err.Error()
+		return imported
+	}
+	imported.MessageID = created.MessageID
+	return imported
+}
+
+type ReplyOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	MessageID    string
+	All          bool
+	From         []string
+	Text         string
+	HTML         string
+	GenerateText string
+	Send         bool
+
+	Attachments []string // path[;type=...;name=...]
+	Inlines     []string // cid=path[;type=...;name=...]
+}
+
+// Reply creates a draft replying to an email, or sends it immediately if Send is set
+func Reply(ctx context.Context, options ReplyOptions) (*email.Email, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	attachments, inlines, err := parseAttachments(options.Attachments, options.Inlines)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expandContacts(&options.From); err != nil {
+		return nil, err
+	}
+
+	original, err := client.Get(ctx, email.GetOptions{MessageID: options.MessageID})
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := threadHeaders(ctx, &client, original.MessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	createOptions := message.Reply(*original, message.ReplyOptions{
+		All:    options.All,
+		From:   options.From,
+		Text:   options.Text,
+		HTML:   options.HTML,
+		Thread: headers,
+	})
+	createOptions.GenerateText = options.GenerateText
+	createOptions.Send = options.Send
+	createOptions.Attachments = attachments
+	createOptions.Inlines = inlines
+
+	return client.Create(ctx, createOptions)
+}
+
+type ForwardOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	MessageID    string
+	From         []string
+	To           []string
+	Cc           []string
+	Bcc          []string
+	Text         string
+	HTML         string
+	GenerateText string
+	Send         bool
+
+	Attachments []string // path[;type=...;name=...]
+	Inlines     []string // cid=path[;type=...;name=...]
+}
+
+// Forward creates a draft forwarding an email with its attachments, or sends it immediately if Send is set
+func Forward(ctx context.Context, options ForwardOptions) (*email.Email, error) {
+	if len(options.To) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	attachments, inlines, err := parseAttachments(options.Attachments, options.Inlines)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expandContacts(&options.From, &options.To, &options.Cc, &options.Bcc); err != nil {
+		return nil, err
+	}
+
+	original, err := client.Get(ctx, email.GetOptions{MessageID: options.MessageID})
+	if err != nil {
+		return nil, err
+	}
+
+	createOptions, err := forwardDraft(ctx, &client, original, options)
+	if err != nil {
+		return nil, err
+	}
+	createOptions.Attachments = append(createOptions.Attachments, attachments...)
+	createOptions.Inlines = append(createOptions.Inlines, inlines...)
+
+	return client.Create(ctx, createOptions)
+}
+
+// forwardDraft builds the draft forwarding the original email with its attachments,
+// from the content and recipients of the options
+func forwardDraft(ctx context.Context, client *email.Client, original *email.Email, options ForwardOptions) (email.CreateOptions, error) {
+	attachments, err := downloadAll(ctx, client, original.MessageID, original.Attachments, false)
+	if err != nil {
+		return email.CreateOptions{}, err
+	}
+	inlines, err := downloadAll(ctx, client, original.MessageID, original.Inlines, true)
+	if err != nil {
+		return email.CreateOptions{}, err
+	}
+
+	headers, err := threadHeaders(ctx, client, original.MessageID)
+	if err != nil {
+		return email.CreateOptions{}, err
+	}
+
+	createOptions := message.Forward(*original, message.ForwardOptions{
+		From:   options.From,
+		To:     options.To,
+		Cc:     options.Cc,
+		Bcc:    options.Bcc,
+		Text:   options.Text,
+		HTML:   options.HTML,
+		Thread: headers,
+	})
+	createOptions.GenerateText = options.GenerateText
+	createOptions.Send = options.Send
+	createOptions.Attachments = attachments
+	createOptions.Inlines = inlines
+	return createOptions, nil
+}
+
+// downloadAll downloads the content of the attachments of an email into memory
+func downloadAll(ctx context.Context, client *email.Client, messageID string, attachments []email.Attachment, inline bool) ([]email.FileAttachment, error) {
+	files := make([]email.FileAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		buf := &bytes.Buffer{}
+		_, err := client.DownloadAttachment(ctx, email.DownloadAttachmentOptions{
+			MessageID: messageID,
+			ContentID: attachment.ContentID,
+			Inline:    inline,
+		}, buf)
+		if err != nil {
+			return nil, fmt.Errorf("download %s: %w", attachment.Filename, err)
+		}
+
+		file := email.FileAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     buf.Bytes(),
+		}
+		if inline {
+			file.ContentID = attachment.ContentID
+		}
+		if file.Filename == "" {
+			file.Filename = attachment.ContentID
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+type ComposeOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Editor  string
+	Subject string
+	From    []string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo []string
+	Send    bool
+
+	Markdown  bool // render the body as Markdown
+	InlineCSS bool // add inline styles to the rendered HTML
+}
+
+// Compose opens the editor with a draft prefilled from the options and creates it
+func Compose(ctx context.Context, options ComposeOptions) (*email.Email, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	if err := expandContacts(&options.From, &options.To, &options.Cc, &options.Bcc, &options.ReplyTo); err != nil {
+		return nil, err
+	}
+	initial := compose.Format(compose.Draft{
+		From:    options.From,
+		To:      options.To,
+		Cc:      options.Cc,
+		Bcc:     options.Bcc,
+		ReplyTo: options.ReplyTo,
+		Subject: options.Subject,
+	})
+	return editDraft(options.Editor, initial, func(draft compose.Draft) (*email.Email, error) {
+		text, html, generateText, err := draftBody(draft, options.Markdown, options.InlineCSS)
+		if err != nil {
+			return nil, err
+		}
+		return client.Create(ctx, email.CreateOptions{
+			Subject:      draft.Subject,
+			From:         draft.From,
+			To:           draft.To,
+			Cc:           draft.Cc,
+			Bcc:          draft.Bcc,
+			ReplyTo:      draft.ReplyTo,
+			Text:         text,
+			HTML:         html,
+			GenerateText: generateText,
+			Send:         options.Send,
+		})
+	})
+}
+
+type EditOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	MessageID string
+	Editor    string
+	Send      bool
+}
+
+// Edit opens an existing draft in the editor and saves the changes.
+// Its HTML body is kept if the text is unchanged, and otherwise replaced by the edited text, like a composed draft.
+func Edit(ctx context.Context, options EditOptions) (*email.Email, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	original, err := client.Get(ctx, email.GetOptions{MessageID: options.MessageID})
+	if err != nil {
+		return nil, err
+	}
+
+	initial := compose.Format(compose.Draft{
+		From:    original.From,
+		To:      original.To,
+		Cc:      original.Cc,
+		Bcc:     original.Bcc,
+		ReplyTo: original.ReplyTo,
+		Subject: original.Subject,
+		Body:    original.Text,
+	})
+	return editDraft(options.Editor, initial, func(draft compose.Draft) (*email.Email, error) {
+		// the HTML body can't be edited, so it's kept unless the text is changed
+		text, html, generateText := original.Text, original.HTML, email.GenerateTextOff
+		if !sameBody(draft.Body, original.Text) {
+			var err error
+			if text, html, generateText, err = draftBody(draft, false, false); err != nil {
+				return nil, err
+			}
+		}
+		return client.Save(ctx, email.SaveOptions{
+			MessageID:    options.MessageID,
+			Subject:      draft.Subject,
+			From:         draft.From,
+			To:           draft.To,
+			Cc:           draft.Cc,
+			Bcc:          draft.Bcc,
+			ReplyTo:      draft.ReplyTo,
+			Text:         text,
+			HTML:         html,
+			GenerateText: generateText,
+			Send:         options.Send,
+		})
+	})
+}
+
+// sameBody reports whether the edited body is the original one, ignoring line endings and trailing newlines added by editors
+func sameBody(edited, original string) bool {
+	normalize := func(body string) string {
+		return strings.TrimRight(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	}
+	return normalize(edited) == normalize(original)
+}
+
+// draftBody returns the text and HTML body of a composed draft, rendering Markdown if enabled
+func draftBody(draft compose.Draft, isMarkdown, inlineCSS bool) (text, html, generateText string, err error) {
+	if !isMarkdown {
+		return draft.Body, "", email.GenerateTextAuto, nil
+	}
+	html, text, err = markdown.Render([]byte(draft.Body), markdown.Options{InlineCSS: inlineCSS})
+	return text, html, email.GenerateTextOff, err
+}
+
+// editDraft lets the user edit the draft and submits it.
+// If the draft is invalid or can't be submitted, the edited file is kept so that nothing is lost.
+func editDraft(editor string, initial []byte, submit func(compose.Draft) (*email.Email, error)) (*email.Email, error) {
+	if editor == "" {
+		editor = compose.Editor()
+	}
+	path, content, err := compose.Edit(editor, initial)
+	if err != nil {
+		if path != "" {
+			_ = os.Remove(path)
+		}
+		return nil, err
+	}
+
+	draft, err := compose.Parse(content)
+	if errors.Is(err, compose.ErrEmptyDraft) {
+		return nil, errors.Join(err, os.Remove(path))
+	}
+	if err == nil {
+		// like the address flags, the headers may refer to contacts
+		err = expandContacts(&draft.From, &draft.To, &draft.Cc, &draft.Bcc, &draft.ReplyTo)
+	}
+	if err == nil {
+		var result *email.Email
+		result, err = submit(draft)
+		if err == nil {
+			return result, os.Remove(path)
+		}
+	}
+	return nil, fmt.Errorf("%w\ndraft kept at %s", err, path)
+}
+
+type MergeOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Template    string // path of a YAML template
+	Data        string // path of a CSV file with a header row naming the variables
+	Send        bool
+	DryRun      bool // render the emails without creating them
+	Concurrency int  // maximum number of concurrent requests
+}
+
+// MergedRow is the outcome of a data row. The text and HTML are only included in a dry run.
+type MergedRow struct {
+	Row       int      `json:"row"` // 1 for the first row after the header
+	To        []string `json:"to,omitempty"`
+	Subject   string   `json:"subject,omitempty"`
+	Text      string   `json:"text,omitempty"`
+	HTML      string   `json:"html,omitempty"`
+	MessageID string   `json:"messageID,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type MergeResult struct {
+	Created int         `json:"created"`
+	Failed  int         `json:"failed"`
+	Rows    []MergedRow `json:"rows"`
+}
+
+// DefaultConcurrency is the number of concurrent requests of Merge and bulk operations if not specified
+const DefaultConcurrency = 4
+
+// Merge creates one email per data row from the template.
+// A row that can't be rendered or created is reported in the result without stopping the merge.
+func Merge(ctx context.Context, options MergeOptions) (*MergeResult, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	tmpl, err := template.Load(options.Template)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(options.Data)
+	if err != nil {
+		return nil, err
+	}
+	data, err := template.ReadData(file)
+	if err = errors.Join(err, file.Close()); err != nil {
+		return nil, err
+	}
+
+	result := &MergeResult{Rows: make([]MergedRow, len(data))}
+	messages := make([]*email.CreateOptions, len(data))
+	for i, vars := range data {
+		row := &result.Rows[i]
+		row.Row = i + 1
+
+		rendered, err := tmpl.Execute(vars)
+		if err != nil {
+			row.Error = err.Error()
+			continue
+		}
+		rendered.Send = options.Send
+		row.To = rendered.To
+		row.Subject = rendered.Subject
+		if options.DryRun {
+			row.Text = rendered.Text
+			row.HTML = rendered.HTML
+			continue
+		}
+		messages[i] = &rendered
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	_ = bulk.Each(len(messages), concurrency, func(i int) error {
+		if messages[i] == nil {
+			return nil
+		}
+		client := client
+		created, err := client.Create(ctx, *messages[i])
+		if err != nil {
+			result.Rows[i].Error = err.Error()
+			return nil
+		}
+		result.Rows[i].MessageID = created.MessageID
+		return nil
+	})
+
+	for _, row := range result.Rows {
+		if row.Error != "" {
+			result.Failed++
+		} else if row.MessageID != "" {
+			result.Created++
+		}
+	}
+	return result, nil
+}
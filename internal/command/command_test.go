@@ -1,12 +1,26 @@
 package command
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/harryzcy/mailbox-cli/internal/archive"
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/compose"
+	"github.com/harryzcy/mailbox-cli/internal/contacts"
 	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,12 +35,12 @@ func setupTestServer(t *testing.T, handlerFunc http.HandlerFunc) *httptest.Serve
 func TestGet(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Get(GetOptions{
+	_, err := Get(context.Background(), GetOptions{
 		APIID:     "",
 		Region:    "",
 		Endpoint:  ts.URL,
@@ -41,12 +55,12 @@ func TestGet(t *testing.T) {
 func TestList(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := List(ListOptions{
+	_, err := List(context.Background(), ListOptions{
 		APIID:    "",
 		Region:   "",
 		Endpoint: ts.URL,
@@ -58,15 +72,53 @@ func TestList(t *testing.T) {
 	assert.True(t, received, "Expected request to be received by the test server")
 }
 
+func TestList_Pagination(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if r.URL.Query().Get("next_cursor") == "" {
+			_, err = fmt.Fprintln(w, `{"count": 2, "items": [{"messageID": "1"}, {"messageID": "2"}], "nextCursor": "cursor"}`)
+		} else {
+			_, err = fmt.Fprintln(w, `{"count": 1, "items": [{"messageID": "3"}]}`)
+		}
+		assert.Nil(t, err)
+	})
+
+	result, err := List(context.Background(), ListOptions{
+		Endpoint: ts.URL,
+		Type:     "inbox",
+		All:      true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Count)
+	assert.Len(t, result.Items, 3)
+	assert.Empty(t, result.NextCursor)
+
+	result, err = List(context.Background(), ListOptions{
+		Endpoint: ts.URL,
+		Type:     "inbox",
+		Limit:    1,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "1", result.Items[0].MessageID)
+
+	_, err = List(context.Background(), ListOptions{
+		Endpoint: ts.URL,
+		Type:     "invalid",
+		All:      true,
+	})
+	assert.Equal(t, errors.New("invalid type"), err)
+}
+
 func TestTrash(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Trash(TrashOptions{
+	_, err := Trash(context.Background(), TrashOptions{
 		APIID:     "",
 		Region:    "",
 		Endpoint:  ts.URL,
@@ -81,12 +133,12 @@ func TestTrash(t *testing.T) {
 func TestUntrash(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Untrash(UntrashOptions{
+	_, err := Untrash(context.Background(), UntrashOptions{
 		APIID:     "",
 		Region:    "",
 		Endpoint:  ts.URL,
@@ -101,12 +153,12 @@ func TestUntrash(t *testing.T) {
 func TestDelete(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Delete(DeleteOptions{
+	_, err := Delete(context.Background(), DeleteOptions{
 		APIID:     "",
 		Region:    "",
 		Endpoint:  ts.URL,
@@ -121,22 +173,22 @@ func TestDelete(t *testing.T) {
 func TestCreate(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Create(CreateOptions{
+	_, err := Create(context.Background(), CreateOptions{
 		APIID:        "",
 		Region:       "",
 		Endpoint:     ts.URL,
 		Verbose:      false,
 		Subject:      "subject",
-		From:         []string{"from"},
-		To:           []string{"to"},
-		Cc:           []string{"cc"},
-		Bcc:          []string{"bcc"},
-		ReplyTo:      []string{"replyTo"},
+		From:         []string{"from@example.com"},
+		To:           []string{"to@example.com"},
+		Cc:           []string{"cc@example.com"},
+		Bcc:          []string{"bcc@example.com"},
+		ReplyTo:      []string{"reply-to@example.com"},
 		Text:         "text",
 		HTML:         "html",
 		GenerateText: email.GenerateTextAuto,
@@ -146,26 +198,233 @@ func TestCreate(t *testing.T) {
 	assert.True(t, received, "Expected request to be received by the test server")
 }
 
+func TestCreate_Markdown(t *testing.T) {
+	var created email.CreateOptions
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&created)
+		assert.Nil(t, err)
+		_, err = fmt.Fprintln(w, `{"messageID": "messageID"}`)
+		assert.Nil(t, err)
+	})
+
+	path := filepath.Join(t.TempDir(), "notice.md")
+	err := os.WriteFile(path, []byte("# Notice\n\nHello *world*\n"), 0o600)
+	assert.Nil(t, err)
+
+	_, err = Create(context.Background(), CreateOptions{
+		Endpoint: ts.URL,
+		Subject:  "subject",
+		Markdown: path,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "<h1>Notice</h1>\n<p>Hello <em>world</em></p>\n", created.HTML)
+	assert.Equal(t, "Notice\n======\n\nHello world\n", created.Text)
+	assert.Equal(t, email.GenerateTextOff, created.GenerateText)
+
+	_, err = Create(context.Background(), CreateOptions{Endpoint: ts.URL, Markdown: path, Text: "text"})
+	assert.Equal(t, errMarkdownConflict, err)
+	_, err = Save(context.Background(), SaveOptions{Endpoint: ts.URL, MessageID: "messageID", Markdown: path, Body: "body"})
+	assert.Equal(t, errMarkdownConflict, err)
+	_, err = Create(context.Background(), CreateOptions{Endpoint: ts.URL, Markdown: filepath.Join(t.TempDir(), "missing.md")})
+	assert.NotNil(t, err)
+}
+
+func TestCreate_Template(t *testing.T) {
+	var created email.CreateOptions
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&created)
+		assert.Nil(t, err)
+		_, err = fmt.Fprintln(w, `{"messageID": "messageID"}`)
+		assert.Nil(t, err)
+	})
+
+	path := filepath.Join(t.TempDir(), "welcome.yaml")
+	err := os.WriteFile(path, []byte("subject: Welcome {{.name}}\n"+
+		"from: [team@example.com]\n"+
+		"to: ['{{.email}}']\n"+
+		"text: Hi {{.name}}\n"+
+		"html: <p>Hi {{.name}}</p>\n"), 0o600)
+	assert.Nil(t, err)
+
+	_, err = Create(context.Background(), CreateOptions{
+		Endpoint: ts.URL,
+		Template: path,
+		Vars:     []string{"name=Tom", "email=tom@example.com"},
+		From:     []string{"override@example.com"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Welcome Tom", created.Subject)
+	assert.Equal(t, []string{"override@example.com"}, created.From)
+	assert.Equal(t, []string{"tom@example.com"}, created.To)
+	assert.Equal(t, "Hi Tom", created.Text)
+	assert.Equal(t, "<p>Hi Tom</p>", created.HTML)
+
+	_, err = Create(context.Background(), CreateOptions{Endpoint: ts.URL, Template: path, Vars: []string{"name=Tom"}})
+	assert.NotNil(t, err)
+	_, err = Create(context.Background(), CreateOptions{Endpoint: ts.URL, Template: path, Vars: []string{"name"}})
+	assert.NotNil(t, err)
+}
+
+func TestCreate_Contacts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := contacts.Path()
+	assert.Nil(t, err)
+	book := &contacts.Book{Contacts: []contacts.Contact{
+		{Name: "Alice", Email: "alice@example.com", Alias: "alice", Groups: []string{"oncall"}},
+		{Email: "bob@example.com", Groups: []string{"oncall"}},
+	}}
+	err = book.Save(path)
+	assert.Nil(t, err)
+
+	var created email.CreateOptions
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&created)
+		assert.Nil(t, err)
+		_, err = fmt.Fprintln(w, `{"messageID": "messageID"}`)
+		assert.Nil(t, err)
+	})
+
+	_, err = Create(context.Background(), CreateOptions{
+		Endpoint: ts.URL,
+		Subject:  "subject",
+		From:     []string{"me@example.com"},
+		To:       []string{"@oncall"},
+		Cc:       []string{"@alice", "carol@example.com"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Alice <alice@example.com>", "bob@example.com"}, created.To)
+	assert.Equal(t, []string{"carol@example.com"}, created.Cc)
+
+	_, err = Create(context.Background(), CreateOptions{Endpoint: ts.URL, To: []string{"@unknown"}})
+	assert.ErrorIs(t, err, contacts.ErrUnknownAlias)
+}
+
+func TestHarvestContacts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := contacts.Path()
+	assert.Nil(t, err)
+	book := &contacts.Book{Contacts: []contacts.Contact{{Name: "Alice Smith", Email: "alice@example.com", Alias: "alice"}}}
+	err = book.Save(path)
+	assert.Nil(t, err)
+
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sent", r.URL.Query().Get("type"))
+		assert.Equal(t, "2025", r.URL.Query().Get("year"))
+		_, err := fmt.Fprintln(w, `{"count": 2, "items": [
+			{"messageID": "1", "to": ["Alice <alice@example.com>", "Bob <bob@example.com>"]},
+			{"messageID": "2", "to": ["bob@example.com"], "cc": ["carol@example.com", "invalid"]}
+		]}`)
+		assert.Nil(t, err)
+	})
+
+	result, err := HarvestContacts(context.Background(), HarvestContactsOptions{
+		Endpoint: ts.URL,
+		Year:     "2025",
+		Group:    "sent",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Added)
+	assert.Equal(t, []contacts.Contact{
+		{Name: "Bob", Email: "bob@example.com", Groups: []string{"sent"}},
+		{Email: "carol@example.com", Groups: []string{"sent"}},
+	}, result.Contacts)
+
+	book, err = contacts.Load(path)
+	assert.Nil(t, err)
+	assert.Len(t, book.Contacts, 3)
+	assert.Equal(t, contacts.Contact{Name: "Alice Smith", Email: "alice@example.com", Alias: "alice"}, book.Contacts[0])
+}
+
+func TestMerge(t *testing.T) {
+	mu := sync.Mutex{}
+	var subjects []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var created email.CreateOptions
+		err := json.NewDecoder(r.Body).Decode(&created)
+		assert.Nil(t, err)
+		if created.To[0] == "fail@example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err = fmt.Fprintln(w, `{"message": "invalid recipient"}`)
+			assert.Nil(t, err)
+			return
+		}
+
+		mu.Lock()
+		subjects = append(subjects, created.Subject)
+		mu.Unlock()
+		_, err = fmt.Fprintf(w, `{"messageID": "id-%s"}`+"\n", strings.Split(created.To[0], "@")[0])
+		assert.Nil(t, err)
+	})
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "template.yaml")
+	err := os.WriteFile(templatePath, []byte("subject: Hello {{.name}}\nto: ['{{.email}}']\ntext: Hi {{.name}}\n"), 0o600)
+	assert.Nil(t, err)
+	dataPath := filepath.Join(dir, "data.csv")
+	err = os.WriteFile(dataPath, []byte("name,email\nTom,tom@example.com\nJane,jane@example.com\nBad,fail@example.com\n"), 0o600)
+	assert.Nil(t, err)
+
+	result, err := Merge(context.Background(), MergeOptions{
+		Endpoint:    ts.URL,
+		Template:    templatePath,
+		Data:        dataPath,
+		Concurrency: 2,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Failed)
+	assert.ElementsMatch(t, []string{"Hello Tom", "Hello Jane"}, subjects)
+	assert.Equal(t, MergedRow{Row: 1, To: []string{"tom@example.com"}, Subject: "Hello Tom", MessageID: "id-tom"}, result.Rows[0])
+	assert.Equal(t, "id-jane", result.Rows[1].MessageID)
+	assert.Equal(t, 3, result.Rows[2].Row)
+	assert.NotEmpty(t, result.Rows[2].Error)
+
+	// dry run
+	subjects = nil
+	result, err = Merge(context.Background(), MergeOptions{
+		Endpoint: ts.URL,
+		Template: templatePath,
+		Data:     dataPath,
+		DryRun:   true,
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, subjects)
+	assert.Equal(t, 0, result.Created)
+	assert.Equal(t, 0, result.Failed)
+	assert.Equal(t, MergedRow{Row: 2, To: []string{"jane@example.com"}, Subject: "Hello Jane", Text: "Hi Jane"}, result.Rows[1])
+
+	// undefined variable
+	err = os.WriteFile(dataPath, []byte("name\nTom\n"), 0o600)
+	assert.Nil(t, err)
+	result, err = Merge(context.Background(), MergeOptions{Endpoint: ts.URL, Template: templatePath, Data: dataPath})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Contains(t, result.Rows[0].Error, "email")
+
+	_, err = Merge(context.Background(), MergeOptions{Endpoint: ts.URL, Template: templatePath, Data: filepath.Join(dir, "missing.csv")})
+	assert.NotNil(t, err)
+}
+
 func TestSave(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Save(SaveOptions{
+	_, err := Save(context.Background(), SaveOptions{
 		MessageID:    "messageID",
 		APIID:        "",
 		Region:       "",
 		Endpoint:     ts.URL,
 		Verbose:      false,
 		Subject:      "subject",
-		From:         []string{"from"},
-		To:           []string{"to"},
-		Cc:           []string{"cc"},
-		Bcc:          []string{"bcc"},
-		ReplyTo:      []string{"replyTo"},
+		From:         []string{"from@example.com"},
+		To:           []string{"to@example.com"},
+		Cc:           []string{"cc@example.com"},
+		Bcc:          []string{"bcc@example.com"},
+		ReplyTo:      []string{"reply-to@example.com"},
 		Body:         "body",
 		Text:         "text",
 		HTML:         "html",
@@ -179,12 +438,12 @@ func TestSave(t *testing.T) {
 func TestSend(t *testing.T) {
 	received := false
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		_, err := fmt.Fprintln(w, "Request received")
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID"}`)
 		assert.Nil(t, err)
 		received = true
 	})
 
-	_, err := Send(SendOptions{
+	_, err := Send(context.Background(), SendOptions{
 		APIID:     "",
 		Region:    "",
 		Endpoint:  ts.URL,
@@ -195,3 +454,976 @@ func TestSend(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, received, "Expected request to be received by the test server")
 }
+
+func TestRetryPolicy(t *testing.T) {
+	assert.Nil(t, retryPolicy(0))
+	assert.Equal(t, email.NewRetryPolicy(3), retryPolicy(3))
+}
+
+func TestParseAttachments(t *testing.T) {
+	attachments, inlines, err := parseAttachments([]string{"a.pdf;name=b.pdf"}, []string{"logo=logo.png"})
+	assert.Nil(t, err)
+	assert.Equal(t, []email.FileAttachment{{Path: "a.pdf", Filename: "b.pdf"}}, attachments)
+	assert.Equal(t, []email.FileAttachment{{Path: "logo.png", ContentID: "logo"}}, inlines)
+
+	_, _, err = parseAttachments([]string{""}, nil)
+	assert.ErrorIs(t, err, email.ErrInvalidAttachment)
+	_, _, err = parseAttachments(nil, []string{"logo.png"})
+	assert.ErrorIs(t, err, email.ErrInvalidAttachment)
+}
+
+func TestListAttachments(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := fmt.Fprintln(w, `{"messageID": "messageID", "attachments": [{"contentID": "a1", "filename": "a.pdf"}]}`)
+		assert.Nil(t, err)
+	})
+
+	result, err := ListAttachments(context.Background(), ListAttachmentsOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []email.Attachment{{ContentID: "a1", Filename: "a.pdf"}}, result.Attachments)
+}
+
+func TestDownloadAttachments(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/emails/messageID":
+			_, err = fmt.Fprintln(w, `{
+				"messageID": "messageID",
+				"attachments": [
+					{"contentID": "a1", "filename": "../report.pdf"},
+					{"contentID": "a2", "filename": "report.pdf"}
+				],
+				"inlines": [{"contentID": "logo"}]
+			}`)
+		case "/emails/messageID/attachments/a1", "/emails/messageID/attachments/a2":
+			_, err = fmt.Fprint(w, "pdf")
+		case "/emails/messageID/inlines/logo":
+			_, err = fmt.Fprint(w, "png")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		assert.Nil(t, err)
+	})
+	dir := t.TempDir()
+
+	result, err := DownloadAttachments(context.Background(), DownloadAttachmentsOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+		All:       true,
+		Dir:       dir,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []DownloadedFile{
+		{ContentID: "a1", Filename: "../report.pdf", Path: filepath.Join(dir, "_report.pdf"), Size: 3},
+		{ContentID: "a2", Filename: "report.pdf", Path: filepath.Join(dir, "report.pdf"), Size: 3},
+		{ContentID: "logo", Filename: "logo", Path: filepath.Join(dir, "logo"), Size: 3},
+	}, result.Files)
+
+	result, err = DownloadAttachments(context.Background(), DownloadAttachmentsOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+		Name:      "report.pdf",
+		Dir:       dir,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []DownloadedFile{
+		{ContentID: "a2", Filename: "report.pdf", Path: filepath.Join(dir, "report (1).pdf"), Size: 3},
+	}, result.Files)
+
+	_, err = DownloadAttachments(context.Background(), DownloadAttachmentsOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+		Name:      "missing.pdf",
+		Dir:       dir,
+	})
+	assert.Equal(t, errors.New(`no attachment named "missing.pdf"`), err)
+
+	_, err = DownloadAttachments(context.Background(), DownloadAttachmentsOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+	})
+	assert.Equal(t, errors.New("either name or all is required"), err)
+}
+
+func TestExport(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/emails":
+			_, err = fmt.Fprintln(w, `{"count": 2, "items": [{"messageID": "id-1"}, {"messageID": "id-2"}]}`)
+		case "/emails/id-1/raw":
+			_, err = fmt.Fprint(w, "Subject: raw\r\n\r\nbody\r\n")
+		case "/emails/id-2":
+			_, err = fmt.Fprintln(w, `{"messageID": "id-2", "subject": "built", "text": "body"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		assert.Nil(t, err)
+	})
+	dir := t.TempDir()
+
+	var exported []string
+	options := ExportOptions{
+		Endpoint: ts.URL,
+		Type:     email.EmailTypeInbox,
+		Format:   "eml",
+		Dest:     dir,
+		Progress: func(e email.Email) {
+			exported = append(exported, e.MessageID)
+		},
+	}
+	result, err := Export(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, &ExportResult{Format: "eml", Dest: dir, Exported: 2}, result)
+	assert.Equal(t, []string{"id-1", "id-2"}, exported)
+
+	data, err := os.ReadFile(filepath.Join(dir, "id-1.eml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Subject: raw\r\n\r\nbody\r\n", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "id-2.eml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "Subject: built\r\n")
+
+	// resume
+	result, err = Export(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, &ExportResult{Format: "eml", Dest: dir, Skipped: 2}, result)
+
+	_, err = Export(context.Background(), ExportOptions{Format: "eml"})
+	assert.Equal(t, errors.New("invalid destination"), err)
+}
+
+func TestImport(t *testing.T) {
+	var subjects []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Subject string `json:"subject"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		assert.Nil(t, err)
+		subjects = append(subjects, body.Subject)
+		if body.Subject == "rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err = fmt.Fprintf(w, `{"messageID": "draft-%d"}`, len(subjects))
+		assert.Nil(t, err)
+	})
+
+	dir := t.TempDir()
+	mbox := filepath.Join(dir, "templates.mbox")
+	err := os.WriteFile(mbox, []byte("From a@example.com Thu Jan  2 03:04:05 2025\n"+
+		"Subject: one\n\nbody\n\n"+
+		"From a@example.com Thu Jan  2 03:04:05 2025\n"+
+		"To: a@\n\nbody\n\n"+
+		"From a@example.com Thu Jan  2 03:04:05 2025\n"+
+		"Subject: rejected\n\nbody\n"), 0o600)
+	assert.Nil(t, err)
+
+	result, err := Import(context.Background(), ImportOptions{Endpoint: ts.URL, Format: "mbox", Path: mbox})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, ImportedMessage{Source: mbox + "#1", Subject: "one", MessageID: "draft-1"}, result.Messages[0])
+	assert.Equal(t, mbox+"#2", result.Messages[1].Source)
+	assert.Contains(t, result.Messages[1].Error, "invalid message")
+	assert.Equal(t, "rejected", result.Messages[2].Subject)
+	assert.NotEmpty(t, result.Messages[2].Error)
+	assert.Equal(t, []string{"one", "rejected"}, subjects)
+
+	eml := filepath.Join(dir, "template.eml")
+	err = os.WriteFile(eml, []byte("Subject: eml\r\n\r\nbody\r\n"), 0o600)
+	assert.Nil(t, err)
+	result, err = Import(context.Background(), ImportOptions{Endpoint: ts.URL, Format: "eml", Path: dir})
+	assert.Nil(t, err)
+	assert.Equal(t, &ImportResult{
+		Imported: 1,
+		Messages: []ImportedMessage{{Source: eml, Subject: "eml", MessageID: "draft-3"}},
+	}, result)
+
+	_, err = Import(context.Background(), ImportOptions{Format: "pst", Path: dir})
+	assert.ErrorIs(t, err, archive.ErrInvalidFormat)
+	_, err = Import(context.Background(), ImportOptions{Format: "mbox", Path: filepath.Join(dir, "missing")})
+	assert.NotNil(t, err)
+}
+
+func TestReply(t *testing.T) {
+	var created map[string]any
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/emails/messageID":
+			_, err = fmt.Fprintln(w, `{"messageID": "messageID", "type": "inbox", "subject": "Hello", "from": ["a@example.com"], "to": ["me@example.com"], "text": "Hi"}`)
+		case r.URL.Path == "/emails/messageID/raw":
+			_, err = fmt.Fprint(w, "Message-ID: <b@example.com>\r\nReferences: <a@example.com>\r\nSubject: Hello\r\n\r\nHi\r\n")
+		case r.Method == http.MethodPost && r.URL.Path == "/emails":
+			err = json.NewDecoder(r.Body).Decode(&created)
+			assert.Nil(t, err)
+			_, err = fmt.Fprintln(w, `{"messageID": "replyID"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		assert.Nil(t, err)
+	})
+
+	result, err := Reply(context.Background(), ReplyOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+		Text:      "Thanks",
+		Send:      true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "replyID", result.MessageID)
+	assert.Equal(t, "Re: Hello", created["subject"])
+	assert.Equal(t, []any{"a@example.com"}, created["to"])
+	assert.Equal(t, []any{"me@example.com"}, created["from"])
+	assert.Equal(t, "b@example.com", created["inReplyTo"])
+	assert.Equal(t, []any{"a@example.com", "b@example.com"}, created["references"])
+	assert.Equal(t, true, created["send"])
+
+	// like forward, the sender may be an alias of the address book
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := contacts.Path()
+	assert.Nil(t, err)
+	book := &contacts.Book{Contacts: []contacts.Contact{{Name: "Me", Email: "me@example.com", Alias: "me"}}}
+	err = book.Save(path)
+	assert.Nil(t, err)
+	_, err = Reply(context.Background(), ReplyOptions{Endpoint: ts.URL, MessageID: "messageID", From: []string{"@me"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []any{"Me <me@example.com>"}, created["from"])
+	_, err = Reply(context.Background(), ReplyOptions{Endpoint: ts.URL, MessageID: "messageID", From: []string{"@unknown"}})
+	assert.ErrorIs(t, err, contacts.ErrUnknownAlias)
+
+	_, err = Reply(context.Background(), ReplyOptions{Endpoint: ts.URL, MessageID: "missing"})
+	assert.NotNil(t, err)
+}
+
+func TestForward(t *testing.T) {
+	var created email.CreateOptions
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/emails/messageID":
+			_, err = fmt.Fprintln(w, `{
+				"messageID": "messageID",
+				"subject": "Report",
+				"to": ["me@example.com"],
+				"text": "See attached",
+				"attachments": [{"contentID": "a1", "filename": "report.pdf", "contentType": "application/pdf"}],
+				"inlines": [{"contentID": "logo", "contentType": "image/png"}]
+			}`)
+		case r.URL.Path == "/emails/messageID/raw":
+			_, err = fmt.Fprint(w, "Message-ID: <report@example.com>\r\nSubject: Report\r\n\r\nSee attached\r\n")
+		case r.URL.Path == "/emails/messageID/attachments/a1":
+			_, err = fmt.Fprint(w, "pdf")
+		case r.URL.Path == "/emails/messageID/inlines/logo":
+			_, err = fmt.Fprint(w, "png")
+		case r.Method == http.MethodPost && r.URL.Path == "/emails":
+			err = json.NewDecoder(r.Body).Decode(&created)
+			assert.Nil(t, err)
+			_, err = fmt.Fprintln(w, `{"messageID": "forwardID"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		assert.Nil(t, err)
+	})
+
+	result, err := Forward(context.Background(), ForwardOptions{
+		Endpoint:  ts.URL,
+		MessageID: "messageID",
+		To:        []string{"b@example.com"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "forwardID", result.MessageID)
+	assert.Equal(t, "Fwd: Report", created.Subject)
+	assert.Equal(t, []string{"me@example.com"}, created.From)
+	assert.Equal(t, []string{"b@example.com"}, created.To)
+	assert.Equal(t, []string{"report@example.com"}, created.References)
+	assert.Equal(t, []email.FileAttachment{
+		{Filename: "report.pdf", ContentType: "application/pdf", Content: []byte("pdf")},
+	}, created.Attachments)
+	assert.Equal(t, []email.FileAttachment{
+		{Filename: "logo", ContentType: "image/png", ContentID: "logo", Content: []byte("png")},
+	}, created.Inlines)
+
+	_, err = Forward(context.Background(), ForwardOptions{Endpoint: ts.URL, MessageID: "messageID"})
+	assert.Equal(t, errors.New("at least one recipient is required"), err)
+}
+
+// writeEditor creates an editor script that replaces the file with the content
+func writeEditor(t *testing.T, content string) string {
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content")
+	err := os.WriteFile(contentPath, []byte(content), 0o600)
+	assert.Nil(t, err)
+	editor := filepath.Join(dir, "editor.sh")
+	err = os.WriteFile(editor, []byte("#!/bin/sh\ncp "+contentPath+" \"$1\"\n"), 0o700)
+	assert.Nil(t, err)
+	return editor
+}
+
+func TestCompose(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	var created email.CreateOptions
+	status := http.StatusOK
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&created)
+		assert.Nil(t, err)
+		w.WriteHeader(status)
+		_, err = fmt.Fprintln(w, `{"messageID": "draftID"}`)
+		assert.Nil(t, err)
+	})
+
+	editor := writeEditor(t, "From: a@example.com\nTo: b@example.com\nSubject: Hello\n\nHi\n")
+	result, err := Compose(context.Background(), ComposeOptions{Endpoint: ts.URL, Editor: editor})
+	assert.Nil(t, err)
+	assert.Equal(t, "draftID", result.MessageID)
+	assert.Equal(t, "Hello", created.Subject)
+	assert.Equal(t, []string{"b@example.com"}, created.To)
+	assert.Equal(t, "Hi\n", created.Text)
+	files, _ := filepath.Glob(filepath.Join(os.TempDir(), "mailbox-cli-draft-*"))
+	assert.Empty(t, files)
+
+	// the draft is kept when the API call fails
+	status = http.StatusBadRequest
+	_, err = Compose(context.Background(), ComposeOptions{Endpoint: ts.URL, Editor: editor})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "draft kept at ")
+	files, _ = filepath.Glob(filepath.Join(os.TempDir(), "mailbox-cli-draft-*"))
+	assert.Len(t, files, 1)
+
+	// markdown
+	status = http.StatusOK
+	editor = writeEditor(t, "To: b@example.com\nSubject: Hello\n\n**Hi**\n")
+	_, err = Compose(context.Background(), ComposeOptions{Endpoint: ts.URL, Editor: editor, Markdown: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "<p><strong>Hi</strong></p>\n", created.HTML)
+	assert.Equal(t, "Hi\n", created.Text)
+
+	_, err = Compose(context.Background(), ComposeOptions{Endpoint: ts.URL, Editor: writeEditor(t, "")})
+	assert.ErrorIs(t, err, compose.ErrEmptyDraft)
+	files, _ = filepath.Glob(filepath.Join(os.TempDir(), "mailbox-cli-draft-*"))
+	assert.Len(t, files, 1)
+
+	// the headers may refer to contacts like the address flags, and an unknown one keeps the draft
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := contacts.Path()
+	assert.Nil(t, err)
+	book := &contacts.Book{Contacts: []contacts.Contact{{Name: "Alice", Email: "alice@example.com", Groups: []string{"oncall"}}}}
+	err = book.Save(path)
+	assert.Nil(t, err)
+	editor = writeEditor(t, "To: @oncall, b@example.com\nSubject: Hello\n\nHi\n")
+	_, err = Compose(context.Background(), ComposeOptions{Endpoint: ts.URL, Editor: editor})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Alice <alice@example.com>", "b@example.com"}, created.To)
+	_, err = Compose(context.Background(), ComposeOptions{Endpoint: ts.URL, Editor: writeEditor(t, "To: @unknown\n\nHi\n")})
+	assert.ErrorIs(t, err, contacts.ErrUnknownAlias)
+	assert.Contains(t, err.Error(), "draft kept at ")
+}
+
+func TestEdit(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	var saved email.SaveOptions
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			_, err = fmt.Fprintln(w, `{"messageID": "draftID", "subject": "Old", "from": ["a@example.com"], "to": ["b@example.com"], "text": "old", "html": "<p>old</p>"}`)
+		case http.MethodPut:
+			err = json.NewDecoder(r.Body).Decode(&saved)
+			assert.Nil(t, err)
+			_, err = fmt.Fprintln(w, `{"messageID": "draftID"}`)
+		}
+		assert.Nil(t, err)
+	})
+
+	editor := writeEditor(t, "From: a@example.com\nTo: b@example.com\nSubject: New\n\nnew\n")
+	result, err := Edit(context.Background(), EditOptions{Endpoint: ts.URL, MessageID: "draftID", Editor: editor})
+	assert.Nil(t, err)
+	assert.Equal(t, "draftID", result.MessageID)
+	assert.Equal(t, "New", saved.Subject)
+	assert.Equal(t, "new\n", saved.Text)
+	assert.Empty(t, saved.HTML)
+	assert.Equal(t, email.GenerateTextAuto, saved.GenerateText)
+
+	// the HTML body is kept when only the headers are edited
+	editor = writeEditor(t, "From: a@example.com\nTo: b@example.com, c@example.com\nSubject: Old\n\nold\n")
+	_, err = Edit(context.Background(), EditOptions{Endpoint: ts.URL, MessageID: "draftID", Editor: editor})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b@example.com", "c@example.com"}, saved.To)
+	assert.Equal(t, "old", saved.Text)
+	assert.Equal(t, "<p>old</p>", saved.HTML)
+	assert.Equal(t, email.GenerateTextOff, saved.GenerateText)
+
+	_, err = Edit(context.Background(), EditOptions{Endpoint: ts.URL, MessageID: "draftID", Editor: writeEditor(t, "To: invalid@\n\n")})
+	assert.ErrorIs(t, err, compose.ErrInvalidDraft)
+	assert.Contains(t, err.Error(), "draft kept at ")
+}
+
+func TestSync(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	unread := true
+	items := []email.Email{
+		{MessageID: "b", Subject: "b", TimeReceived: now.Add(-2 * time.Minute), Unread: &unread},
+		{MessageID: "a", Subject: "a", TimeReceived: now.Add(-3 * time.Minute)},
+	}
+	var listed, fetched []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/emails" {
+			query := r.URL.Query()
+			listed = append(listed, query.Get("year")+"-"+query.Get("month"))
+			result := email.ListResult{Items: []email.Email{}}
+			if query.Get("year") == cache.PartitionOf("", now).YearString() && query.Get("month") == cache.PartitionOf("", now).MonthString() {
+				result.Items = items
+			}
+			err := json.NewEncoder(w).Encode(result)
+			assert.Nil(t, err)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/emails/")
+		fetched = append(fetched, id)
+		err := json.NewEncoder(w).Encode(email.Email{MessageID: id, Subject: id, Text: "body of " + id, TimeReceived: now})
+		assert.Nil(t, err)
+	})
+	options := SyncOptions{Endpoint: ts.URL, Types: []string{email.EmailTypeInbox}, Months: 2}
+
+	_, err := List(context.Background(), ListOptions{Endpoint: ts.URL, Offline: true})
+	assert.ErrorIs(t, err, cache.ErrNotCached)
+
+	result, err := Sync(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Added)
+	assert.Len(t, result.Partitions, 2)
+	// fetching marks inbox emails as read, so unread ones are marked unread again
+	assert.Equal(t, []string{"b", "b/unread", "a"}, fetched)
+
+	// only the new email is fetched, and the complete previous month isn't listed again
+	items = append([]email.Email{{MessageID: "c", Subject: "c", TimeReceived: now.Add(-time.Minute)}}, items...)
+	listed, fetched = nil, nil
+	result, err = Sync(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, []string{"c"}, fetched)
+	assert.Len(t, listed, 1)
+	assert.True(t, result.Partitions[1].Skipped)
+
+	// a full sync removes deleted emails
+	items = items[:2]
+	result, err = Sync(context.Background(), SyncOptions{Endpoint: ts.URL, Types: []string{email.EmailTypeInbox}, Months: 1, Full: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Added)
+	assert.Equal(t, 1, result.Removed)
+
+	list, err := List(context.Background(), ListOptions{Endpoint: ts.URL, Type: email.EmailTypeInbox, Offline: true, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, list.Count)
+	assert.Empty(t, list.Items[0].Text)
+
+	got, err := Get(context.Background(), GetOptions{Endpoint: ts.URL, MessageID: "c", Offline: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "body of c", got.Text)
+	assert.Equal(t, email.EmailTypeInbox, got.Type)
+	got, err = Get(context.Background(), GetOptions{Endpoint: ts.URL, MessageID: "b", Offline: true})
+	assert.Nil(t, err)
+	assert.Equal(t, &unread, got.Unread)
+	_, err = Get(context.Background(), GetOptions{Endpoint: ts.URL, MessageID: "a", Offline: true})
+	assert.ErrorIs(t, err, cache.ErrNotCached)
+}
+
+func TestSearch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	bodies := map[string]string{
+		"a": "The quarterly report is attached",
+		"b": "Lunch on Friday?",
+	}
+	var listed, fetched []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/emails" {
+			query := r.URL.Query()
+			listed = append(listed, query.Get("type")+" "+query.Get("year")+"-"+query.Get("month"))
+			result := email.ListResult{Items: []email.Email{}}
+			if query.Get("type") == email.EmailTypeInbox && query.Get("month") == cache.PartitionOf("", now).MonthString() {
+				result.Items = []email.Email{
+					{MessageID: "b", Subject: "Lunch", TimeReceived: now.Add(-time.Minute)},
+					{MessageID: "a", Subject: "Report", TimeReceived: now.Add(-2 * time.Minute)},
+				}
+			}
+			err := json.NewEncoder(w).Encode(result)
+			assert.Nil(t, err)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/emails/")
+		fetched = append(fetched, id)
+		err := json.NewEncoder(w).Encode(email.Email{MessageID: id, Type: email.EmailTypeInbox, Text: bodies[id], TimeReceived: now})
+		assert.Nil(t, err)
+	})
+
+	_, err := Search(context.Background(), SearchOptions{Endpoint: ts.URL, Query: "report", Offline: true})
+	assert.ErrorIs(t, err, cache.ErrNotCached)
+
+	// searching only syncs the metadata of the queried type
+	result, err := Search(context.Background(), SearchOptions{Endpoint: ts.URL, Query: "Quarterly REPORT", Type: email.EmailTypeInbox})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+	assert.Len(t, listed, DefaultSyncMonths)
+	assert.Empty(t, fetched)
+	result, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Query: "report", Type: email.EmailTypeInbox})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+
+	// bodies are searched once synced
+	_, err = Sync(context.Background(), SyncOptions{Endpoint: ts.URL, Types: []string{email.EmailTypeInbox}, Months: 1})
+	assert.Nil(t, err)
+	result, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Query: "Quarterly REPORT", Type: email.EmailTypeInbox})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "a", result.Items[0].MessageID)
+	assert.Equal(t, "The **quarterly** **report** is attached", result.Items[0].Snippet)
+	assert.Empty(t, result.Items[0].Text)
+
+	// and only the months of the queried dates are synced
+	listed = nil
+	lastYear := now.AddDate(-1, 0, 0)
+	result, err = Search(context.Background(), SearchOptions{
+		Endpoint: ts.URL,
+		Type:     email.EmailTypeSent,
+		Since:    time.Date(lastYear.Year(), time.January, 1, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(lastYear.Year(), time.March, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+	year := strconv.Itoa(lastYear.Year())
+	assert.Equal(t, []string{"sent " + year + "-02", "sent " + year + "-01"}, listed)
+
+	// months synced without bodies are listed again by a sync with bodies
+	listed = nil
+	_, err = Sync(context.Background(), SyncOptions{
+		Endpoint: ts.URL,
+		Types:    []string{email.EmailTypeSent},
+		Months:   2,
+		Until:    time.Date(lastYear.Year(), time.March, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sent " + year + "-02", "sent " + year + "-01"}, listed)
+
+	result, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Offline: true, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+
+	result, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Offline: true, Limit: -1, Since: now.Add(time.Hour)})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+
+	// operators in the query, which the options override
+	tomorrow := now.Local().AddDate(0, 0, 1).Format(time.DateOnly)
+	result, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Offline: true, Query: "type:inbox since:" + tomorrow})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+	result, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Offline: true, Query: "since:" + tomorrow, Since: now.Add(-time.Hour)})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Count)
+
+	_, err = Search(context.Background(), SearchOptions{Endpoint: ts.URL, Offline: true, Query: "has:link"})
+	assert.ErrorIs(t, err, search.ErrInvalidQuery)
+}
+
+func TestThread(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	emails := map[string]email.Email{
+		"a": {MessageID: "a", ThreadID: "t", Subject: "Hello", Text: "Hi", TimeReceived: now.Add(-3 * time.Minute)},
+		"b": {MessageID: "b", ThreadID: "t", Subject: "Re: Hello", Text: "Hi back", TimeSent: now.Add(-2 * time.Minute)},
+		"c": {MessageID: "c", ThreadID: "t", Subject: "Re: Hello", Text: "Hi again", TimeReceived: now.Add(-time.Minute)},
+	}
+	raw := map[string]string{
+		"a": "Message-ID: <a@x>\r\n\r\n",
+		"b": "Message-ID: <b@x>\r\nIn-Reply-To: <a@x>\r\n\r\n",
+		"c": "Message-ID: <c@x>\r\nIn-Reply-To: <b@x>\r\nReferences: <a@x> <b@x>\r\n\r\n",
+	}
+	threadStatus := http.StatusOK
+	mu := sync.Mutex{}
+	inFlight, maxInFlight := 0, 0
+	// the backend marks an email as read when it's fetched, reporting its state from before
+	unread := map[string]bool{"a": true, "c": true}
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/threads/t" {
+			w.WriteHeader(threadStatus)
+			err := json.NewEncoder(w).Encode(email.Thread{ThreadID: "t", EmailIDs: []string{"a", "b", "c"}})
+			assert.Nil(t, err)
+			return
+		}
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/emails/"), "/raw"); ok {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+
+			_, err := w.Write([]byte(raw[id]))
+			assert.Nil(t, err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/emails/"), "/unread"); ok {
+			unread[id] = true
+			err := json.NewEncoder(w).Encode(email.ActionResult{MessageID: id})
+			assert.Nil(t, err)
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/emails/")
+		e := emails[id]
+		state := unread[id]
+		e.Unread = &state
+		unread[id] = false
+		err := json.NewEncoder(w).Encode(e)
+		assert.Nil(t, err)
+	})
+	keptUnread := map[string]bool{"a": true, "b": false, "c": true}
+
+	result, err := Thread(context.Background(), ThreadOptions{Endpoint: ts.URL, MessageID: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, "t", result.ThreadID)
+	assert.Equal(t, 3, result.Count)
+	assert.Equal(t, "c", result.Items[2].MessageID)
+	assert.Equal(t, 2, result.Items[2].Depth)
+	assert.Empty(t, result.Items[2].Text)
+	// the raw messages are read concurrently, within the limit
+	assert.Greater(t, maxInFlight, 1)
+	assert.LessOrEqual(t, maxInFlight, DefaultConcurrency)
+	// viewing the thread doesn't mark it as read
+	assert.Equal(t, keptUnread, unread)
+
+	result, err = Thread(context.Background(), ThreadOptions{Endpoint: ts.URL, MessageID: "c", View: "transcript"})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Count)
+	assert.Equal(t, "Hi back", result.Items[1].Text)
+	assert.Equal(t, keptUnread, unread)
+
+	// without a thread endpoint, only the email itself is known
+	threadStatus = http.StatusNotFound
+	result, err = Thread(context.Background(), ThreadOptions{Endpoint: ts.URL, MessageID: "c"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+
+	_, err = Thread(context.Background(), ThreadOptions{Endpoint: ts.URL, MessageID: "c", View: "invalid"})
+	assert.NotNil(t, err)
+
+	_, err = Thread(context.Background(), ThreadOptions{Endpoint: ts.URL, MessageID: "c", Offline: true})
+	assert.ErrorIs(t, err, cache.ErrNotCached)
+}
+
+func TestWatch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	items := []email.Email{
+		{MessageID: "b", Subject: "b", TimeReceived: now.Add(-2 * time.Minute)},
+		{MessageID: "a", Subject: "a", TimeReceived: now.Add(-3 * time.Minute)},
+	}
+	var fetched []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/emails" {
+			query := r.URL.Query()
+			result := email.ListResult{Items: []email.Email{}}
+			if query.Get("year") == cache.PartitionOf("", now).YearString() && query.Get("month") == cache.PartitionOf("", now).MonthString() {
+				result.Items = items
+			}
+			err := json.NewEncoder(w).Encode(result)
+			assert.Nil(t, err)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/emails/")
+		fetched = append(fetched, id)
+		err := json.NewEncoder(w).Encode(email.Email{MessageID: id, Subject: id, Text: "body of " + id})
+		assert.Nil(t, err)
+	})
+
+	var posted []email.Email
+	webhookStatus := http.StatusOK
+	webhook := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var e email.Email
+		err := json.NewDecoder(r.Body).Decode(&e)
+		assert.Nil(t, err)
+		posted = append(posted, e)
+		w.WriteHeader(webhookStatus)
+	})
+
+	dir := t.TempDir()
+	hook := fmt.Sprintf(`printf '%%s\n' "$MAILBOX_MESSAGE_ID" >> %s/ids; cat >> %s/payloads`, dir, dir)
+	var delivered []string
+	var errs []error
+	options := WatchOptions{
+		Endpoint: ts.URL,
+		Hook:     hook,
+		Webhook:  webhook.URL,
+		Once:     true,
+		OnEmail:  func(e email.Email) { delivered = append(delivered, e.MessageID) },
+		OnError:  func(err error) { errs = append(errs, err) },
+	}
+
+	// the first run only records the newest email
+	err := Watch(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Empty(t, delivered)
+	assert.Empty(t, fetched)
+
+	// new emails are delivered oldest first
+	unread := true
+	items = append([]email.Email{
+		{MessageID: "d", Subject: "d", TimeReceived: now.Add(-time.Minute), Unread: &unread},
+		{MessageID: "c", Subject: "c", TimeReceived: now.Add(-time.Minute)},
+	}, items...)
+	err = Watch(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"c", "d"}, delivered)
+	// the unread email is marked unread again after fetching it
+	assert.Equal(t, []string{"c", "d", "d/unread"}, fetched)
+	assert.Len(t, posted, 2)
+	assert.Equal(t, "body of c", posted[0].Text)
+	assert.Equal(t, &unread, posted[1].Unread)
+	ids, err := os.ReadFile(filepath.Join(dir, "ids"))
+	assert.Nil(t, err)
+	assert.Equal(t, "c\nd\n", string(ids))
+	payloads, err := os.ReadFile(filepath.Join(dir, "payloads"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(payloads), `"messageID":"d"`)
+
+	// nothing is delivered twice, and failed deliveries are reported without stopping
+	delivered = nil
+	err = Watch(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Empty(t, delivered)
+
+	items = append([]email.Email{{MessageID: "e", Subject: "e", TimeReceived: now}}, items...)
+	webhookStatus = http.StatusInternalServerError
+	err = Watch(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"e"}, delivered)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "500")
+
+	// watching stops when the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	options.Once = false
+	options.Interval = time.Hour
+	options.OnEmail = func(email.Email) {}
+	go cancel()
+	err = Watch(ctx, options)
+	assert.Nil(t, err)
+}
+
+func TestApplyRules(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	unread := true
+	items := []email.Email{
+		{MessageID: "new", Subject: "[GitHub] New", From: []string{"notifications@github.com"}, TimeReceived: now.Add(-time.Minute)},
+		{MessageID: "report", Subject: "Report", From: []string{"alice@example.com"}, TimeReceived: now.Add(-2 * time.Minute), Unread: &unread},
+		{MessageID: "fail", Subject: "Invoice", From: []string{"billing@example.com"}, TimeReceived: now.Add(-3 * time.Minute)},
+		{MessageID: "gone", Subject: "Gone", From: []string{"carol@example.com"}, TimeReceived: now.Add(-4 * time.Minute)},
+	}
+	var requests []string
+	var created []email.CreateOptions
+	// the backend marks an email as read when it's fetched
+	state := map[string]bool{"report": true}
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/emails" && r.Method == http.MethodGet {
+			query := r.URL.Query()
+			result := email.ListResult{Items: []email.Email{}}
+			if query.Get("type") == email.EmailTypeInbox && query.Get("year") == cache.PartitionOf("", now).YearString() && query.Get("month") == cache.PartitionOf("", now).MonthString() {
+				result.Items = items
+			}
+			err := json.NewEncoder(w).Encode(result)
+			assert.Nil(t, err)
+			return
+		}
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/emails/"), "/")
+		switch {
+		case r.Method == http.MethodGet && action == "" && id != "gone":
+			state[id] = false
+		case action == "read" || action == "unread":
+			state[id] = action == "unread"
+		}
+		switch {
+		case r.URL.Path == "/emails/fail/trash":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message":"boom"}`))
+		case r.URL.Path == "/emails/gone":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"email not found"}`))
+		case r.URL.Path == "/emails/report/attachments/a1":
+			_, err := fmt.Fprint(w, "pdf")
+			assert.Nil(t, err)
+		case r.Method == http.MethodPost && r.URL.Path == "/emails":
+			var options email.CreateOptions
+			err := json.NewDecoder(r.Body).Decode(&options)
+			assert.Nil(t, err)
+			created = append(created, options)
+			_, err = fmt.Fprintln(w, `{"messageID": "forward-id"}`)
+			assert.Nil(t, err)
+		case r.Method == http.MethodGet:
+			id := strings.TrimPrefix(r.URL.Path, "/emails/")
+			e := email.Email{MessageID: id, Subject: id}
+			if id == "report" {
+				e.Attachments = []email.Attachment{{ContentID: "a1", Filename: "report.pdf"}}
+			}
+			err := json.NewEncoder(w).Encode(e)
+			assert.Nil(t, err)
+		default:
+			err := json.NewEncoder(w).Encode(map[string]string{"messageID": "result-id"})
+			assert.Nil(t, err)
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(path, []byte(`rules:
+  - name: notifications
+    match: {from: notifications@github.com}
+    actions: {mark: read, trash: true}
+  - name: invoices
+    match: {subject: ^Invoice}
+    actions: {mark: unread, forward: {from: [me@example.com], to: [billing@example.com]}, trash: true}
+  - name: reports
+    match: {hasAttachment: true}
+    actions: {forward: {from: [me@example.com], to: [boss@example.com], send: true}}
+  - name: drafts
+    match: {type: draft}
+    actions: {delete: true}
+`), 0o600)
+	assert.Nil(t, err)
+	options := RulesOptions{Endpoint: ts.URL, File: path, Months: 1}
+
+	// a dry run only reports the matches, fetching emails to check attachments without changing their unread state
+	result, err := ApplyRules(context.Background(), RulesOptions{Endpoint: ts.URL, File: path, Months: 1, DryRun: true})
+	assert.Nil(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 3, result.Matched)
+	assert.Equal(t, 1, result.Failed)
+	assert.Len(t, result.Rules, 4)
+	assert.Equal(t, []RuleEmail{{MessageID: "new", Subject: "[GitHub] New", Actions: []string{"mark read", "trash"}}}, result.Rules[0].Emails)
+	assert.Equal(t, "report", result.Rules[2].Emails[0].MessageID)
+	assert.Equal(t, 0, result.Rules[3].Matched)
+	assert.Equal(t, []string{"GET /emails/report", "POST /emails/report/unread", "GET /emails/gone"}, requests)
+	assert.Equal(t, map[string]bool{"report": true}, state)
+	assert.Empty(t, created)
+
+	requests = nil
+	result, err = ApplyRules(context.Background(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Matched)
+	assert.Equal(t, 2, result.Failed)
+	// the email fetched to check attachments is forwarded without fetching it again,
+	// and the email marked unread is kept unread when it's fetched to forward
+	assert.Equal(t, []string{
+		"POST /emails/new/read", "POST /emails/new/trash",
+		"GET /emails/report", "POST /emails/report/unread", "GET /emails/report/attachments/a1", "GET /emails/report/raw", "POST /emails",
+		"POST /emails/fail/unread", "GET /emails/fail", "POST /emails/fail/unread", "GET /emails/fail/raw", "POST /emails", "POST /emails/fail/trash",
+		"GET /emails/gone",
+	}, requests)
+	assert.Equal(t, map[string]bool{"new": false, "report": true, "fail": true}, state)
+	if assert.Len(t, created, 2) {
+		assert.Equal(t, []string{"me@example.com"}, created[0].From)
+		assert.Equal(t, []string{"boss@example.com"}, created[0].To)
+		assert.True(t, created[0].Send)
+		assert.Equal(t, []string{"billing@example.com"}, created[1].To)
+		assert.False(t, created[1].Send)
+	}
+	assert.Equal(t, 1, result.Rules[1].Failed)
+	assert.Equal(t, []string{"mark unread", "forward"}, result.Rules[1].Emails[0].Actions)
+	assert.Contains(t, result.Rules[1].Emails[0].Error, "boom")
+
+	// an email that can't be fetched is reported on its own, without failing a rule or stopping the others
+	assert.Equal(t, 0, result.Rules[2].Failed)
+	assert.Len(t, result.Rules[2].Emails, 1)
+	assert.Equal(t, []RuleEmail{{MessageID: "gone", Subject: "Gone", Actions: []string{}, Error: "api error: 404 Not Found: email not found"}}, result.Errors)
+
+	_, err = ApplyRules(context.Background(), RulesOptions{Endpoint: ts.URL})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSelect(t *testing.T) {
+	now := time.Now().UTC()
+	unread := true
+	var listed []string
+	var fetched []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/emails" {
+			query := r.URL.Query()
+			listed = append(listed, query.Get("type")+" "+query.Get("year")+"-"+query.Get("month"))
+			result := email.ListResult{Items: []email.Email{}}
+			if query.Get("year") == cache.PartitionOf("", now).YearString() && query.Get("month") == cache.PartitionOf("", now).MonthString() {
+				result.Items = []email.Email{
+					{MessageID: "c", Subject: "Disk full", From: []string{"alerts@x.com"}, TimeReceived: now.Add(-time.Minute), Unread: &unread},
+					{MessageID: "b", Subject: "Lunch", From: []string{"bob@example.com"}, TimeReceived: now.Add(-2 * time.Minute)},
+					{MessageID: "a", Subject: "CPU high", From: []string{"alerts@x.com"}, TimeReceived: now.Add(-3 * time.Minute)},
+				}
+			}
+			err := json.NewEncoder(w).Encode(result)
+			assert.Nil(t, err)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/emails/")
+		fetched = append(fetched, id)
+		e := email.Email{MessageID: id}
+		if id == "a" {
+			e.Attachments = []email.Attachment{{Filename: "graph.png"}}
+		}
+		err := json.NewEncoder(w).Encode(e)
+		assert.Nil(t, err)
+	})
+
+	result, err := Select(context.Background(), SelectOptions{Endpoint: ts.URL, Where: "from:alerts@x.com", Months: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, "c", result.Items[0].MessageID)
+	assert.Equal(t, email.EmailTypeInbox, result.Items[0].Type)
+	assert.Len(t, listed, 2)
+	assert.Empty(t, fetched)
+
+	// words match the subject and addresses, and attachments are checked on the full email, keeping unread emails unread
+	result, err = Select(context.Background(), SelectOptions{Endpoint: ts.URL, Where: "alerts has:attachment", Months: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Count)
+	assert.Equal(t, "a", result.Items[0].MessageID)
+	assert.Equal(t, []string{"c", "c/unread", "a"}, fetched)
+
+	// the partitions listed follow the dates of the selector
+	listed = nil
+	lastYear := now.AddDate(-1, 0, 0)
+	result, err = Select(context.Background(), SelectOptions{
+		Endpoint: ts.URL,
+		Type:     email.EmailTypeSent,
+		Where:    fmt.Sprintf("type:sent since:%d-01-01 before:%d-03-01", lastYear.Year(), lastYear.Year()),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Count)
+	year := strconv.Itoa(lastYear.Year())
+	assert.Equal(t, []string{"sent " + year + "-02", "sent " + year + "-01"}, listed)
+
+	_, err = Select(context.Background(), SelectOptions{Endpoint: ts.URL, Where: "type:draft", Type: email.EmailTypeSent})
+	assert.ErrorIs(t, err, search.ErrInvalidQuery)
+	_, err = Select(context.Background(), SelectOptions{Endpoint: ts.URL, Where: "before:soon"})
+	assert.ErrorIs(t, err, search.ErrInvalidQuery)
+}
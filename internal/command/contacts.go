@@ -0,0 +1,116 @@
+package command
+
+import (
+	"context"
+	"slices"
+
+	"github.com/harryzcy/mailbox-cli/internal/contacts"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+)
+
+// expandContacts replaces references to aliases and groups of the address book, such as @oncall,
+// with the addresses of the contacts. The address book is only read if there is a reference.
+func expandContacts(fields ...*[]string) error {
+	hasReference := slices.ContainsFunc(fields, func(values *[]string) bool {
+		return slices.ContainsFunc(*values, contacts.IsReference)
+	})
+	if !hasReference {
+		return nil
+	}
+
+	path, err := contacts.Path()
+	if err != nil {
+		return err
+	}
+	book, err := contacts.Load(path)
+	if err != nil {
+		return err
+	}
+	for _, values := range fields {
+		if *values, err = book.Expand(*values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type HarvestContactsOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Year  string
+	Month string
+	Group string // optional group of the harvested contacts
+}
+
+type HarvestContactsResult struct {
+	Added    int                `json:"added"`
+	Contacts []contacts.Contact `json:"contacts"`
+}
+
+// HarvestContacts adds the recipients of sent emails to the address book.
+// Existing contacts are left unchanged, so that edited names and aliases are kept.
+func HarvestContacts(ctx context.Context, options HarvestContactsOptions) (*HarvestContactsResult, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	path, err := contacts.Path()
+	if err != nil {
+		return nil, err
+	}
+	book, err := contacts.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HarvestContactsResult{Contacts: []contacts.Contact{}}
+	for item, err := range client.ListAll(ctx, email.ListOptions{
+		Type:  email.EmailTypeSent,
+		Year:  options.Year,
+		Month: options.Month,
+	}) {
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range slices.Concat(item.To, item.Cc, item.Bcc) {
+			list, err := email.ParseAddresses(value)
+			if err != nil {
+				// addresses of sent emails were accepted by the API, so skip anything unexpected
+				continue
+			}
+			for _, address := range list {
+				if _, ok := book.Get(address.Address); ok {
+					continue
+				}
+				c := contacts.Contact{Name: address.Name, Email: address.Address}
+				if options.Group != "" {
+					c.Groups = []string{options.Group}
+				}
+				if _, err := book.Add(c); err != nil {
+					return nil, err
+				}
+				added, _ := book.Get(address.Address)
+				result.Contacts = append(result.Contacts, added)
+			}
+		}
+	}
+
+	result.Added = len(result.Contacts)
+	if result.Added > 0 {
+		if err := book.Save(path); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
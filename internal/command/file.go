@@ -0,0 +1,60 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxFilenameLength is the maximum length of a filename in bytes on most file systems
+const maxFilenameLength = 255
+
+// sanitizeFilename turns a filename from an external sender into a safe name within a directory.
+// Path separators, reserved and control characters are replaced, and leading dots are removed
+// so that the file can neither escape the directory nor become hidden.
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r), strings.ContainsRune(`/\<>:"|?*`, r):
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ". ")
+	name = strings.TrimRight(name, ". ")
+
+	if len(name) > maxFilenameLength {
+		ext := filepath.Ext(name)
+		if len(ext) > maxFilenameLength/2 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:maxFilenameLength-len(ext)], "") + ext
+	}
+
+	if name == "" {
+		return "attachment"
+	}
+	return name
+}
+
+// createUniqueFile creates a new file named name in dir. If the name is taken,
+// a counter is appended before the extension, e.g. "report (1).pdf".
+func createUniqueFile(dir, name string) (*os.File, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	candidate := name
+	for i := 1; ; i++ {
+		file, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, os.ErrExist) || i > 1000 {
+			return nil, err
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "invoice.pdf", expected: "invoice.pdf"},
+		{name: "../../etc/passwd", expected: "_.._etc_passwd"},
+		{name: `C:\Windows\system32.dll`, expected: "C__Windows_system32.dll"},
+		{name: ".hidden", expected: "hidden"},
+		{name: "  report.pdf. ", expected: "report.pdf"},
+		{name: "a\x00b\nc.txt", expected: "a_b_c.txt"},
+		{name: "who?*.txt", expected: "who__.txt"},
+		{name: "日本語.txt", expected: "日本語.txt"},
+		{name: "", expected: "attachment"},
+		{name: "..", expected: "attachment"},
+		{name: strings.Repeat("a", 300) + ".pdf", expected: strings.Repeat("a", 251) + ".pdf"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, sanitizeFilename(test.name))
+		})
+	}
+}
+
+func TestCreateUniqueFile(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, expected := range []string{"report.pdf", "report (1).pdf", "report (2).pdf"} {
+		file, err := createUniqueFile(dir, "report.pdf")
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(dir, expected), file.Name())
+		assert.Nil(t, file.Close())
+	}
+
+	_, err := createUniqueFile(filepath.Join(dir, "missing"), "report.pdf")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,250 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/notify"
+	"github.com/harryzcy/mailbox-cli/internal/rules"
+)
+
+// DefaultRulesMonths is the number of months rules are applied to if not specified
+const DefaultRulesMonths = 12
+
+type RulesOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	File   string // path of the rules file, rules.yaml in the configuration directory if empty
+	Months int    // number of months to list, including the current month
+	DryRun bool   // report the matching emails without applying the actions
+}
+
+// RuleEmail is an email matched by a rule
+type RuleEmail struct {
+	MessageID string   `json:"messageID"`
+	Subject   string   `json:"subject"`
+	Actions   []string `json:"actions"` // the actions applied, or to be applied in a dry run
+	Error     string   `json:"error,omitempty"`
+}
+
+// RuleReport reports the emails matched by a rule
+type RuleReport struct {
+	Name    string      `json:"name"`
+	Matched int         `json:"matched"`
+	Failed  int         `json:"failed"`
+	Emails  []RuleEmail `json:"emails"`
+}
+
+type RulesResult struct {
+	DryRun  bool         `json:"dryRun,omitempty"`
+	Matched int          `json:"matched"`
+	Failed  int          `json:"failed"`
+	Rules   []RuleReport `json:"rules"`
+	Errors  []RuleEmail  `json:"errors,omitempty"` // emails that couldn't be fetched to check the rules
+}
+
+// ApplyRules applies the rules to the emails listed from the current month back.
+// Each email is handled by the first rule it matches, and a failed action is reported without stopping the other emails,
+// as is an email that can't be fetched to check the rules.
+// Emails are fetched without changing their unread state, so a dry run leaves the mailbox as it was.
+func ApplyRules(ctx context.Context, options RulesOptions) (*RulesResult, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+	if options.File == "" {
+		path, err := rules.Path()
+		if err != nil {
+			return nil, err
+		}
+		options.File = path
+	}
+	if options.Months <= 0 {
+		options.Months = DefaultRulesMonths
+	}
+
+	list, err := rules.Load(options.File)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RulesResult{DryRun: options.DryRun, Rules: make([]RuleReport, len(list))}
+	for i, rule := range list {
+		result.Rules[i] = RuleReport{Name: rule.Name, Emails: []RuleEmail{}}
+	}
+
+	var types []string
+	for _, rule := range list {
+		if !slices.Contains(types, rule.Match.Type) {
+			types = append(types, rule.Match.Type)
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, emailType := range types {
+		// list everything before acting, so that trashing or deleting doesn't disturb the cursors
+		var items []email.Email
+		partition := cache.PartitionOf(emailType, now)
+		for range options.Months {
+			for item, err := range client.ListAll(ctx, email.ListOptions{
+				Type:  emailType,
+				Year:  partition.YearString(),
+				Month: partition.MonthString(),
+				Order: email.OrderDesc,
+			}) {
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			partition = partition.Previous()
+		}
+
+		for _, item := range items {
+			if item.Type == "" {
+				item.Type = emailType
+			}
+			i, full, err := matchRule(ctx, &client, list, item, now)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err != nil {
+				// the email couldn't be fetched to check the rules, which fails it without stopping the other emails
+				result.Errors = append(result.Errors, RuleEmail{MessageID: item.MessageID, Subject: item.Subject, Actions: []string{}, Error: err.Error()})
+				result.Failed++
+				continue
+			}
+			if i < 0 {
+				continue
+			}
+
+			rule := list[i]
+			matched := RuleEmail{MessageID: item.MessageID, Subject: item.Subject, Actions: rule.Actions.Names()}
+			if !options.DryRun {
+				matched.Actions, err = applyActions(ctx, &client, rule, item, full)
+			}
+			if err != nil {
+				matched.Error = err.Error()
+				result.Rules[i].Failed++
+				result.Failed++
+			}
+			result.Rules[i].Matched++
+			result.Rules[i].Emails = append(result.Rules[i].Emails, matched)
+			result.Matched++
+		}
+	}
+	return result, nil
+}
+
+// matchRule returns the index of the first rule matching the email, or -1 if none does,
+// along with the full email if it was fetched to match.
+// If the email can't be fetched, it returns -1 with the error.
+func matchRule(ctx context.Context, client *email.Client, list []rules.Rule, item email.Email, now time.Time) (int, *email.Email, error) {
+	var full *email.Email
+	for i, rule := range list {
+		if rule.Match.Type != item.Type {
+			continue
+		}
+		// check the other conditions on the listed metadata first, fetching the email only if needed
+		metadata := rule.Match
+		metadata.HasAttachment = nil
+		if !metadata.Matches(item, now) {
+			continue
+		}
+		if !rule.Match.NeedsBody() {
+			return i, full, nil
+		}
+		if full == nil {
+			var err error
+			if full, err = getKeepUnread(ctx, client, item); err != nil {
+				return -1, nil, err
+			}
+		}
+		if rule.Match.Matches(*full, now) {
+			return i, full, nil
+		}
+	}
+	return -1, full, nil
+}
+
+// applyActions applies the actions of the rule to the email, stopping at the first failure.
+// It returns the names of the actions applied.
+// The email is fetched keeping the unread state set by a mark action, if it wasn't fetched to match.
+func applyActions(ctx context.Context, client *email.Client, rule rules.Rule, item email.Email, full *email.Email) ([]string, error) {
+	applied := []string{}
+	actions := rule.Actions
+	for _, name := range actions.Names() {
+		var err error
+		switch name {
+		case "mark " + rules.MarkRead:
+			if _, err = client.Read(ctx, email.ReadOptions{MessageID: item.MessageID}); err == nil {
+				item.Unread = new(bool)
+			}
+		case "mark " + rules.MarkUnread:
+			if _, err = client.Unread(ctx, email.UnreadOptions{MessageID: item.MessageID}); err == nil {
+				unread := true
+				item.Unread = &unread
+			}
+		case "hook":
+			if full == nil {
+				if full, err = getKeepUnread(ctx, client, item); err != nil {
+					break
+				}
+			}
+			var payload []byte
+			if payload, err = json.Marshal(full); err != nil {
+				break
+			}
+			env := map[string]string{
+				"MAILBOX_MESSAGE_ID": item.MessageID,
+				"MAILBOX_TYPE":       item.Type,
+				"MAILBOX_SUBJECT":    item.Subject,
+				"MAILBOX_RULE":       rule.Name,
+			}
+			err = notify.RunHook(ctx, actions.Hook, payload, env, os.Stderr, os.Stderr)
+		case "forward":
+			if full == nil {
+				if full, err = getKeepUnread(ctx, client, item); err != nil {
+					break
+				}
+			}
+			forward := ForwardOptions{
+				From: actions.Forward.From,
+				To:   actions.Forward.To,
+				Text: actions.Forward.Text,
+				Send: actions.Forward.Send,
+			}
+			if err = expandContacts(&forward.From, &forward.To); err != nil {
+				break
+			}
+			var createOptions email.CreateOptions
+			if createOptions, err = forwardDraft(ctx, client, full, forward); err != nil {
+				break
+			}
+			_, err = client.Create(ctx, createOptions)
+		case "trash":
+			_, err = client.Trash(ctx, email.TrashOptions{MessageID: item.MessageID})
+		case "delete":
+			_, err = client.Delete(ctx, email.DeleteOptions{MessageID: item.MessageID})
+		}
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/search"
+)
+
+// DefaultSearchLimit is the number of hits returned if not specified
+const DefaultSearchLimit = 20
+
+type SearchOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Query         string    // words, every one of which must match, and operators such as from: (see search.ParseQuery)
+	From          string    // substring of a From address
+	To            string    // substring of a To, Cc or Bcc address
+	Since         time.Time // inclusive
+	Until         time.Time // exclusive
+	HasAttachment bool
+	Type          string
+	Limit         int // maximum number of hits, DefaultSearchLimit if zero, or all hits if negative
+
+	Offline bool // search the offline cache as is, without syncing it first
+}
+
+// Search finds emails in the local search index, which is part of the offline cache.
+//
+// Unless Offline is set, the metadata of the queried type and months is first brought up to date with an incremental sync,
+// back DefaultSyncMonths months unless the query has a start date. Bodies aren't fetched, so they are only searched
+// for the emails synced with their bodies by Sync.
+func Search(ctx context.Context, options SearchOptions) (*search.Result, error) {
+	query, err := searchQuery(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.Offline {
+		syncOptions := SyncOptions{
+			APIID:    options.APIID,
+			Region:   options.Region,
+			Endpoint: options.Endpoint,
+			Retries:  options.Retries,
+			Verbose:  options.Verbose,
+			Months:   DefaultSyncMonths,
+			NoBodies: true,
+			Until:    query.Until,
+		}
+		if query.Type != "" {
+			syncOptions.Types = []string{query.Type}
+		}
+		if !query.Since.IsZero() {
+			syncOptions.Months = monthsBetween(query.Since, query.Until)
+		}
+		if syncOptions.Months > 0 {
+			if _, err := Sync(ctx, syncOptions); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	} else if limit < 0 {
+		limit = 0
+	}
+	return searchOffline(query, limit, options)
+}
+
+// monthsBetween returns the number of months from the one containing since to the one before until, inclusive,
+// or to the current month if until is zero or later
+func monthsBetween(since, until time.Time) int {
+	end := time.Now().UTC()
+	if !until.IsZero() && until.Before(end) {
+		end = until.UTC().Add(-time.Nanosecond)
+	}
+	since = since.UTC()
+	return (end.Year()-since.Year())*12 + int(end.Month()) - int(since.Month()) + 1
+}
+
+// searchQuery parses the query text, letting the options override its operators
+func searchQuery(options SearchOptions) (search.Query, error) {
+	query, err := search.ParseQuery(options.Query, time.Local)
+	if err != nil {
+		return search.Query{}, err
+	}
+	if options.From != "" {
+		query.From = options.From
+	}
+	if options.To != "" {
+		query.To = options.To
+	}
+	if !options.Since.IsZero() {
+		query.Since = options.Since
+	}
+	if !options.Until.IsZero() {
+		query.Until = options.Until
+	}
+	if options.HasAttachment {
+		query.HasAttachment = true
+	}
+	if options.Type != "" {
+		query.Type = options.Type
+	}
+	return query, nil
+}
+
+func searchOffline(query search.Query, limit int, options SearchOptions) (result *search.Result, err error) {
+	store, err := openCache(options.APIID, options.Region, options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, store.Close())
+	}()
+
+	return store.Search(query, limit)
+}
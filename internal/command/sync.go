@@ -0,0 +1,282 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+)
+
+// DefaultSyncMonths is the number of months synced if not specified, including the current month
+const DefaultSyncMonths = 12
+
+type SyncOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Types    []string // email types to sync, all types if empty
+	Months   int      // number of months to sync, including the current month
+	Full     bool     // list every partition to the end, refreshing changed metadata and removing deleted emails
+	NoBodies bool     // only sync the metadata returned by the list API
+
+	Until time.Time // sync the months back from the one before this time, instead of from the current month
+}
+
+// SyncedPartition reports the changes of a partition
+type SyncedPartition struct {
+	Type    string `json:"type"`
+	Year    string `json:"year"`
+	Month   string `json:"month"`
+	Added   int    `json:"added"`
+	Updated int    `json:"updated"`
+	Removed int    `json:"removed"`
+	Skipped bool   `json:"skipped,omitempty"` // already complete
+}
+
+type SyncResult struct {
+	Added      int               `json:"added"`
+	Updated    int               `json:"updated"`
+	Removed    int               `json:"removed"`
+	Partitions []SyncedPartition `json:"partitions"`
+}
+
+// Sync mirrors emails into the offline cache, partition by partition.
+//
+// A partition of a past month is complete once it has been listed after the month ended, and isn't listed again,
+// unless it was listed without bodies and they are now synced.
+// Other partitions are listed newest first until reaching an email that is already cached and unchanged,
+// unless Full is set, in which case they are listed to the end and cached emails that are no longer listed are removed.
+// Fetching the bodies leaves unread emails unread.
+func Sync(ctx context.Context, options SyncOptions) (result *SyncResult, err error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	types := options.Types
+	if len(types) == 0 {
+		types = []string{email.EmailTypeInbox, email.EmailTypeSent, email.EmailTypeDraft}
+	}
+	months := options.Months
+	if months <= 0 {
+		months = DefaultSyncMonths
+	}
+
+	path, err := cache.Path(options.APIID, options.Region, options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	store, err := cache.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, store.Close())
+	}()
+
+	now := time.Now().UTC()
+	end := now
+	if !options.Until.IsZero() && options.Until.Before(end) {
+		// Until is exclusive, so the first partition is the one containing the instant before it
+		end = options.Until.UTC().Add(-time.Nanosecond)
+	}
+	result = &SyncResult{Partitions: []SyncedPartition{}}
+	for _, emailType := range types {
+		partition := cache.PartitionOf(emailType, end)
+		for range months {
+			synced, err := syncPartition(ctx, &client, store, partition, now, options)
+			if err != nil {
+				return nil, err
+			}
+			result.Partitions = append(result.Partitions, *synced)
+			result.Added += synced.Added
+			result.Updated += synced.Updated
+			result.Removed += synced.Removed
+
+			partition = partition.Previous()
+		}
+	}
+	return result, nil
+}
+
+func syncPartition(ctx context.Context, client *email.Client, store *cache.Store,
+	partition cache.Partition, now time.Time, options SyncOptions,
+) (*SyncedPartition, error) {
+	synced := &SyncedPartition{Type: partition.Type, Year: partition.YearString(), Month: partition.MonthString()}
+
+	state, found, err := store.PartitionState(partition)
+	if err != nil {
+		return nil, err
+	}
+	if found && state.Complete && !options.Full && (options.NoBodies || !state.NoBodies) {
+		synced.Skipped = true
+		return synced, nil
+	}
+
+	// stopping at the first unchanged email is only safe if the older emails have been synced before
+	incremental := found && !options.Full
+	listed := map[string]bool{}
+	listedAll := true
+	for item, err := range client.ListAll(ctx, email.ListOptions{
+		Type:  partition.Type,
+		Year:  partition.YearString(),
+		Month: partition.MonthString(),
+		Order: email.OrderDesc,
+	}) {
+		if err != nil {
+			return nil, err
+		}
+		listed[item.MessageID] = true
+
+		cached, exists, err := store.Entry(item.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		if exists && unchanged(cached, item, !options.NoBodies) {
+			if incremental {
+				listedAll = false
+				break
+			}
+			continue
+		}
+
+		entry := cache.Entry{Email: item, Partition: partition.String()}
+		if item.Type == "" {
+			entry.Email.Type = partition.Type
+		}
+		if !options.NoBodies {
+			full, err := getKeepUnread(ctx, client, item)
+			if err != nil {
+				return nil, err
+			}
+			entry.Email = *full
+			if entry.Email.Type == "" {
+				entry.Email.Type = partition.Type
+			}
+			entry.HasBody = true
+		} else if exists && cached.HasBody && cached.Email.TimeUpdated.Equal(item.TimeUpdated) {
+			// only flags such as unread changed, so the cached body is still current
+			entry.Email.Text, entry.Email.HTML = cached.Email.Text, cached.Email.HTML
+			entry.HasBody = true
+		}
+		if err := store.Put(entry); err != nil {
+			return nil, err
+		}
+		if exists {
+			synced.Updated++
+		} else {
+			synced.Added++
+		}
+	}
+
+	if listedAll {
+		removed, err := store.Prune(partition, listed)
+		if err != nil {
+			return nil, err
+		}
+		synced.Removed = removed
+	}
+
+	err = store.SetPartitionState(partition, cache.PartitionState{
+		SyncedAt: now,
+		Complete: !now.Before(partition.End()),
+		NoBodies: options.NoBodies,
+	})
+	return synced, err
+}
+
+// getKeepUnread fetches the full email of the listed one without changing its unread state.
+// The backend marks inbox emails as read when they are fetched, so an email listed as unread is marked unread again.
+// Without a listed state, such as for an email known only by its ID, the state the fetched email reports is kept,
+// since the backend reports it from before the fetch.
+func getKeepUnread(ctx context.Context, client *email.Client, item email.Email) (*email.Email, error) {
+	full, err := client.Get(ctx, email.GetOptions{MessageID: item.MessageID})
+	if err != nil {
+		return nil, err
+	}
+	unread := item.Unread
+	if unread == nil {
+		unread = full.Unread
+	}
+	if unread == nil || !*unread {
+		return full, nil
+	}
+	if _, err := client.Unread(ctx, email.UnreadOptions{MessageID: item.MessageID}); err != nil {
+		return nil, fmt.Errorf("restore unread state of %s: %w", item.MessageID, err)
+	}
+	full.Unread = unread
+	return full, nil
+}
+
+// unchanged reports whether the cached email is up to date with the listed metadata
+func unchanged(cached cache.Entry, item email.Email, needBody bool) bool {
+	if needBody && !cached.HasBody {
+		return false
+	}
+	if !cached.Email.TimeUpdated.Equal(item.TimeUpdated) {
+		return false
+	}
+	if (cached.Email.Unread == nil) != (item.Unread == nil) {
+		return false
+	}
+	return cached.Email.Unread == nil || *cached.Email.Unread == *item.Unread
+}
+
+// openCache opens the offline cache of the API for reading
+func openCache(apiID, region, endpoint string) (*cache.Store, error) {
+	path, err := cache.Path(apiID, region, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return cache.OpenReadOnly(path)
+}
+
+// listOffline serves List from the offline cache
+func listOffline(options ListOptions) (result *email.ListResult, err error) {
+	store, err := openCache(options.APIID, options.Region, options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, store.Close())
+	}()
+
+	result, err = store.List(email.ListOptions{
+		Type:  options.Type,
+		Year:  options.Year,
+		Month: options.Month,
+		Order: options.Order,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if options.Limit > 0 && len(result.Items) > options.Limit {
+		result.Items = result.Items[:options.Limit]
+		result.Count = len(result.Items)
+	}
+	return result, nil
+}
+
+// getOffline serves Get from the offline cache
+func getOffline(options GetOptions) (result *email.Email, err error) {
+	store, err := openCache(options.APIID, options.Region, options.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, store.Close())
+	}()
+
+	return store.Get(options.MessageID)
+}
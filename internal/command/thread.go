@@ -0,0 +1,220 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/harryzcy/mailbox-cli/internal/bulk"
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/message"
+	"github.com/harryzcy/mailbox-cli/internal/thread"
+)
+
+type ThreadOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	MessageID string
+	View      string // tree (default) or transcript
+
+	Offline bool // only use the offline cache, which links emails by their backend thread ID
+}
+
+// Thread reconstructs the conversation of an email.
+//
+// The candidates are the emails of the backend thread, if the backend groups the email into one,
+// and the cached emails sharing its thread ID or subject. Unless Offline is set,
+// the Message-ID, In-Reply-To and References headers of every candidate are read from the raw message to link replies,
+// up to DefaultConcurrency messages at a time. The emails fetched are kept unread.
+func Thread(ctx context.Context, options ThreadOptions) (*thread.Result, error) {
+	view := options.View
+	if view == "" {
+		view = thread.ViewTree
+	}
+	if view != thread.ViewTree && view != thread.ViewTranscript {
+		return nil, fmt.Errorf("invalid view %q: expected %s or %s", view, thread.ViewTree, thread.ViewTranscript)
+	}
+
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	var root *email.Email
+	var err error
+	if options.Offline {
+		root, err = getOffline(GetOptions{
+			APIID:     options.APIID,
+			Region:    options.Region,
+			Endpoint:  options.Endpoint,
+			MessageID: options.MessageID,
+		})
+	} else {
+		root, err = getKeepUnread(ctx, &client, email.Email{MessageID: options.MessageID})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if root.MessageID == "" {
+		root.MessageID = options.MessageID
+	}
+
+	candidates := []email.Email{*root}
+	seen := map[string]bool{root.MessageID: true}
+	add := func(e email.Email) {
+		if !seen[e.MessageID] {
+			seen[e.MessageID] = true
+			candidates = append(candidates, e)
+		}
+	}
+
+	if root.ThreadID != "" && !options.Offline {
+		emails, err := backendThread(ctx, &client, root.ThreadID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range emails {
+			add(e)
+		}
+	}
+
+	cached, err := cachedCandidates(options, *root)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range cached {
+		add(e)
+	}
+
+	messages := make([]thread.Message, len(candidates))
+	for i, e := range candidates {
+		messages[i].Email = e
+	}
+	if !options.Offline {
+		err := bulk.Each(len(messages), DefaultConcurrency, func(i int) error {
+			// each call uses its own copy of the client, since requests load the credentials into it
+			client := client
+			var err error
+			messages[i].Headers, err = threadHeaders(ctx, &client, messages[i].Email.MessageID)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := thread.Build(root.MessageID, messages, view)
+	err = bulk.Each(len(result.Items), DefaultConcurrency, func(i int) error {
+		item := &result.Items[i].Email
+		if view == thread.ViewTree {
+			// like list, the tree only shows the metadata
+			item.Text, item.HTML = "", ""
+			return nil
+		}
+		if item.Text != "" || item.HTML != "" || options.Offline {
+			return nil
+		}
+		client := client
+		full, err := getKeepUnread(ctx, &client, *item)
+		if err != nil {
+			return err
+		}
+		item.Text, item.HTML = full.Text, full.HTML
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// backendThread returns the emails of the backend thread,
+// or nothing if the backend doesn't have a thread endpoint
+func backendThread(ctx context.Context, client *email.Client, threadID string) ([]email.Email, error) {
+	t, err := client.GetThread(ctx, email.GetThreadOptions{ThreadID: threadID})
+	var apiErr *email.APIError
+	if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Emails) > 0 {
+		return t.Emails, nil
+	}
+
+	emails := make([]email.Email, len(t.EmailIDs))
+	err = bulk.Each(len(t.EmailIDs), DefaultConcurrency, func(i int) error {
+		client := *client
+		e, err := getKeepUnread(ctx, &client, email.Email{MessageID: t.EmailIDs[i]})
+		if err != nil {
+			return err
+		}
+		emails[i] = *e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// cachedCandidates returns the cached emails that may belong to the thread of the email,
+// or nothing if there is no offline cache
+func cachedCandidates(options ThreadOptions, root email.Email) (emails []email.Email, err error) {
+	store, err := openCache(options.APIID, options.Region, options.Endpoint)
+	if errors.Is(err, cache.ErrNotCached) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, store.Close())
+	}()
+
+	subject := thread.NormalizeSubject(root.Subject)
+	entries, err := store.Entries(func(entry cache.Entry) bool {
+		if root.ThreadID != "" && entry.Email.ThreadID == root.ThreadID {
+			return true
+		}
+		return subject != "" && thread.NormalizeSubject(entry.Email.Subject) == subject
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		emails = append(emails, entry.Email)
+	}
+	return emails, nil
+}
+
+// threadHeaders reads the threading headers of the raw message, which are empty if the raw message is unavailable
+func threadHeaders(ctx context.Context, client *email.Client, messageID string) (message.ThreadHeaders, error) {
+	buf := &bytes.Buffer{}
+	_, err := client.GetRaw(ctx, email.GetRawOptions{MessageID: messageID}, buf)
+	var apiErr *email.APIError
+	if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+		return message.ThreadHeaders{}, nil
+	}
+	if err != nil {
+		return message.ThreadHeaders{}, err
+	}
+	headers, err := message.ReadThreadHeaders(buf)
+	if err != nil {
+		// a malformed message can't be linked, but the rest of the thread still can
+		return message.ThreadHeaders{}, nil
+	}
+	return headers, nil
+}
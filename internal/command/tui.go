@@ -0,0 +1,47 @@
+package command
+
+import (
+	"context"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/tui"
+)
+
+// newScreen creates the terminal screen of the interface, replaced by a simulation screen in tests
+var newScreen = tcell.NewScreen
+
+type TUIOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+
+	// request options
+	Types  []string // email types of the folder pane, all types if empty
+	Months int      // number of months of each type
+}
+
+// TUI runs the full-screen interface until the user quits
+func TUI(ctx context.Context, options TUIOptions) error {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		// debug output would corrupt the screen
+		Verbose: false,
+	}
+
+	screen, err := newScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	return tui.New(ctx, screen, &client, tui.Options{Types: options.Types, Months: options.Months}).Run()
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+// quitScreen is a simulation screen where the user quits right away
+type quitScreen struct {
+	tcell.SimulationScreen
+}
+
+func (s quitScreen) Init() error {
+	if err := s.SimulationScreen.Init(); err != nil {
+		return err
+	}
+	s.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+	return nil
+}
+
+func TestTUI(t *testing.T) {
+	defer func() {
+		newScreen = tcell.NewScreen
+	}()
+
+	var listed []string
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		listed = append(listed, r.URL.Query().Get("type"))
+		err := json.NewEncoder(w).Encode(email.ListResult{Items: []email.Email{}})
+		assert.Nil(t, err)
+	})
+
+	newScreen = func() (tcell.Screen, error) {
+		return quitScreen{tcell.NewSimulationScreen("UTF-8")}, nil
+	}
+	err := TUI(context.Background(), TUIOptions{Endpoint: ts.URL, Types: []string{email.EmailTypeSent}, Months: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{email.EmailTypeSent}, listed)
+}
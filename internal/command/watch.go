@@ -0,0 +1,279 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/notify"
+)
+
+// DefaultWatchInterval is the time between polls if not specified
+const DefaultWatchInterval = 30 * time.Second
+
+// webhookTimeout limits how long a webhook may take to respond
+const webhookTimeout = 30 * time.Second
+
+type WatchOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Type     string        // inbox if empty
+	Interval time.Duration // DefaultWatchInterval if zero
+	Hook     string        // shell command run for each new email, with the email JSON on stdin
+	Webhook  string        // URL the email JSON is POSTed to for each new email
+	Once     bool          // poll once and return, such as from cron
+
+	// OnEmail is called for each new email, oldest first, if set
+	OnEmail func(e email.Email)
+	// OnError is called for errors that don't stop watching, such as a failed poll or hook, if set
+	OnError func(err error)
+}
+
+// watchCursor records the newest email seen of a type.
+// Emails listed at the same time as the newest one are told apart by their IDs.
+type watchCursor struct {
+	Time time.Time `json:"time"`
+	IDs  []string  `json:"ids"`
+}
+
+// Watch polls for new emails until the context is cancelled, delivering each new email to the handlers.
+//
+// The newest email seen is recorded in a state file next to the offline cache, so that restarting watch
+// delivers the emails received in between. The first run only records the newest email, without delivering older ones.
+// Unread emails are marked unread again after being fetched, since the backend marks them as read.
+func Watch(ctx context.Context, options WatchOptions) error {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+	if options.Type == "" {
+		options.Type = email.EmailTypeInbox
+	}
+	if options.Interval <= 0 {
+		options.Interval = DefaultWatchInterval
+	}
+	onError := options.OnError
+	if onError == nil {
+		onError = func(error) {}
+	}
+
+	path, err := cache.StatePath(options.APIID, options.Region, options.Endpoint, "watch")
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{Timeout: webhookTimeout}
+
+	for {
+		err := pollWatch(ctx, &client, httpClient, path, options, onError)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			var apiErr *email.APIError
+			if options.Once || errors.Is(err, email.ErrMissingCredentials) || (errors.As(err, &apiErr) && apiErr.IsAuth()) {
+				return err
+			}
+			onError(err)
+		}
+		if options.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+// pollWatch lists the emails newer than the cursor, delivering them and advancing the cursor one by one
+func pollWatch(ctx context.Context, client *email.Client, httpClient *http.Client, path string, options WatchOptions, onError func(error)) error {
+	cursors, err := loadWatchState(path)
+	if err != nil {
+		return err
+	}
+	cursor, found := cursors[options.Type]
+
+	now := time.Now().UTC()
+	if !found {
+		cursors[options.Type], err = newestCursor(ctx, client, options.Type, now)
+		if err != nil {
+			return err
+		}
+		return saveWatchState(path, cursors)
+	}
+
+	items, err := newEmails(ctx, client, options.Type, cursor, now)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		// delivery is at most once: a failed hook or webhook isn't retried, so that it can't block watching
+		for _, err := range deliver(ctx, client, httpClient, item, options) {
+			onError(err)
+		}
+
+		if t := item.Time(); t.After(cursor.Time) {
+			cursor = watchCursor{Time: t, IDs: []string{item.MessageID}}
+		} else {
+			cursor.IDs = append(cursor.IDs, item.MessageID)
+		}
+		cursors[options.Type] = cursor
+		if err := saveWatchState(path, cursors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newestCursor returns the cursor of the newest email of the current month, or of the current time if there is none
+func newestCursor(ctx context.Context, client *email.Client, emailType string, now time.Time) (watchCursor, error) {
+	partition := cache.PartitionOf(emailType, now)
+	result, err := client.List(ctx, email.ListOptions{
+		Type:  emailType,
+		Year:  partition.YearString(),
+		Month: partition.MonthString(),
+		Order: email.OrderDesc,
+	})
+	if err != nil {
+		return watchCursor{}, err
+	}
+	if len(result.Items) == 0 {
+		return watchCursor{Time: now, IDs: []string{}}, nil
+	}
+
+	cursor := watchCursor{Time: result.Items[0].Time(), IDs: []string{}}
+	for _, item := range result.Items {
+		if item.Time().Equal(cursor.Time) {
+			cursor.IDs = append(cursor.IDs, item.MessageID)
+		}
+	}
+	return cursor, nil
+}
+
+// newEmails lists the emails after the cursor, oldest first, from the current month back to the month of the cursor
+func newEmails(ctx context.Context, client *email.Client, emailType string, cursor watchCursor, now time.Time) ([]email.Email, error) {
+	var items []email.Email
+	last := cache.PartitionOf(emailType, cursor.Time.UTC())
+	partition := cache.PartitionOf(emailType, now)
+	// a cursor in the future, such as after a clock change, only needs the current month
+	for range DefaultSyncMonths {
+		reached := false
+		for item, err := range client.ListAll(ctx, email.ListOptions{
+			Type:  emailType,
+			Year:  partition.YearString(),
+			Month: partition.MonthString(),
+			Order: email.OrderDesc,
+		}) {
+			if err != nil {
+				return nil, err
+			}
+			t := item.Time()
+			if t.Before(cursor.Time) {
+				reached = true
+				break
+			}
+			if t.Equal(cursor.Time) && slices.Contains(cursor.IDs, item.MessageID) {
+				continue
+			}
+			items = append(items, item)
+		}
+		if reached || partition == last || !partition.End().After(last.End()) {
+			break
+		}
+		partition = partition.Previous()
+	}
+
+	slices.Reverse(items)
+	return items, nil
+}
+
+// deliver fetches the full email and hands it to the handlers, hook and webhook, returning their errors.
+// An unread email stays unread, since fetching it is not reading it.
+func deliver(ctx context.Context, client *email.Client, httpClient *http.Client, item email.Email, options WatchOptions) []error {
+	var errs []error
+	full, err := getKeepUnread(ctx, client, item)
+	if err != nil {
+		// the listed metadata is still worth a notification
+		errs = append(errs, err)
+	} else {
+		if full.Type == "" {
+			full.Type = item.Type
+		}
+		item = *full
+	}
+
+	if options.OnEmail != nil {
+		options.OnEmail(item)
+	}
+
+	if options.Hook == "" && options.Webhook == "" {
+		return errs
+	}
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return append(errs, err)
+	}
+	if options.Hook != "" {
+		env := map[string]string{
+			"MAILBOX_MESSAGE_ID": item.MessageID,
+			"MAILBOX_TYPE":       options.Type,
+			"MAILBOX_SUBJECT":    item.Subject,
+		}
+		// the output of the hook goes to stderr, since stdout is the stream of new emails
+		if err := notify.RunHook(ctx, options.Hook, payload, env, os.Stderr, os.Stderr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if options.Webhook != "" {
+		if err := notify.PostWebhook(ctx, httpClient, options.Webhook, payload); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func loadWatchState(path string) (map[string]watchCursor, error) {
+	cursors := map[string]watchCursor{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cursors, json.Unmarshal(data, &cursors)
+}
+
+// saveWatchState writes the state atomically, so that an interrupted watch never leaves a truncated file
+func saveWatchState(path string, cursors map[string]watchCursor) error {
+	data, err := json.Marshal(cursors)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
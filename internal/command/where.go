@@ -0,0 +1,117 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/cache"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+)
+
+// DefaultSelectMonths is the number of months listed by Select if not specified and the selector has no since: or after:
+const DefaultSelectMonths = 12
+
+type SelectOptions struct {
+	// client options
+	APIID    string
+	Region   string
+	Endpoint string
+	Retries  int
+	Verbose  bool
+
+	// request options
+	Where  string // selector in the syntax of search queries, such as "from:alerts@example.com before:2025-01-01"
+	Type   string // inbox if empty, unless the selector has type:
+	Months int    // number of months to list back from the selector's end date or the current month
+}
+
+// Select lists the emails matching the selector, newest first.
+//
+// The emails are listed by month from the end date of the selector, or the current month, back to its start date.
+// Without a start date, Months limits how far back to list. Words match the subject and addresses of the listed emails.
+// Emails fetched to check for attachments are kept unread.
+func Select(ctx context.Context, options SelectOptions) (*email.ListResult, error) {
+	client := email.Client{
+		APIID:       options.APIID,
+		Region:      options.Region,
+		Endpoint:    options.Endpoint,
+		RetryPolicy: retryPolicy(options.Retries),
+		Verbose:     options.Verbose,
+	}
+
+	query, err := search.ParseQuery(options.Where, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	emailType := options.Type
+	switch {
+	case query.Type == "":
+	case emailType != "" && emailType != query.Type:
+		return nil, fmt.Errorf("%w: type:%s conflicts with --type %s", search.ErrInvalidQuery, query.Type, emailType)
+	default:
+		emailType = query.Type
+	}
+	if emailType == "" {
+		emailType = email.EmailTypeInbox
+	}
+	query.Type = emailType
+	months := options.Months
+	if months <= 0 {
+		months = DefaultSelectMonths
+	}
+
+	end := time.Now().UTC()
+	if !query.Until.IsZero() && query.Until.Before(end) {
+		// Until is exclusive, so the last partition is the one containing the instant before it
+		end = query.Until.UTC().Add(-time.Nanosecond)
+	}
+	partition := cache.PartitionOf(emailType, end)
+
+	// with a start date, list back to its partition, otherwise the number of months
+	done := func(i int) bool {
+		if query.Since.IsZero() {
+			return i >= months
+		}
+		return !partition.End().After(query.Since)
+	}
+
+	result := &email.ListResult{Items: []email.Email{}}
+	for i := 0; !done(i); i++ {
+		for item, err := range client.ListAll(ctx, email.ListOptions{
+			Type:  emailType,
+			Year:  partition.YearString(),
+			Month: partition.MonthString(),
+			Order: email.OrderDesc,
+		}) {
+			if err != nil {
+				return nil, err
+			}
+			if item.Type == "" {
+				item.Type = emailType
+			}
+
+			// the listed metadata has no attachments, so that condition is checked on the full email
+			metadata := query
+			metadata.HasAttachment = false
+			if !metadata.Match(item) || !query.MatchTerms(item) {
+				continue
+			}
+			if query.HasAttachment {
+				full, err := getKeepUnread(ctx, &client, item)
+				if err != nil {
+					return nil, err
+				}
+				if len(full.Attachments) == 0 {
+					continue
+				}
+			}
+			result.Items = append(result.Items, item)
+		}
+		partition = partition.Previous()
+	}
+
+	result.Count = len(result.Items)
+	return result, nil
+}
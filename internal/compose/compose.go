@@ -0,0 +1,204 @@
+package compose
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/harryzcy/mailbox-cli/internal/contacts"
+	"github.com/harryzcy/mailbox-cli/internal/message"
+)
+
+var (
+	ErrEmptyDraft   = errors.New("empty draft, aborting")
+	ErrInvalidDraft = errors.New("invalid draft")
+)
+
+// Draft is the content of an email edited as a header block followed by the body
+type Draft struct {
+	From    []string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo []string
+	Subject string
+	Body    string
+}
+
+// headers lists the editable headers in display order
+var headers = []string{"From", "To", "Cc", "Bcc", "Reply-To", "Subject"}
+
+// Format renders the draft as an RFC 822-style header block, a blank line, and the body
+func Format(d Draft) []byte {
+	buf := &bytes.Buffer{}
+	for _, key := range headers {
+		value := d.Subject
+		if addresses := d.addresses(key); addresses != nil {
+			value = strings.Join(*addresses, ", ")
+		}
+		fmt.Fprintf(buf, "%s: %s\n", key, value)
+	}
+	buf.WriteString("\n")
+	buf.WriteString(d.Body)
+	return buf.Bytes()
+}
+
+// Parse reads a draft written by Format, validating the addresses.
+// References to contacts, such as @oncall, are kept as they are for the caller to expand.
+// Header names are case-insensitive, and lines starting with whitespace continue the previous header.
+func Parse(data []byte) (Draft, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return Draft{}, ErrEmptyDraft
+	}
+
+	d := Draft{}
+	values := map[string]string{}
+	var current string
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := reader.ReadString('\n')
+		if strings.TrimRight(line, "\r\n") == "" {
+			// the rest is the body
+			body := &strings.Builder{}
+			_, _ = reader.WriteTo(body)
+			d.Body = body.String()
+			break
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && current != "" {
+			values[current] += " " + strings.TrimSpace(line)
+		} else {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return Draft{}, fmt.Errorf("%w: expected a header or a blank line before the body, got %q", ErrInvalidDraft, strings.TrimSpace(line))
+			}
+			current = canonicalHeader(key)
+			if current == "" {
+				return Draft{}, fmt.Errorf("%w: unknown header %q", ErrInvalidDraft, strings.TrimSpace(key))
+			}
+			values[current] = strings.TrimSpace(value)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	for key, value := range values {
+		addresses := d.addresses(key)
+		if addresses == nil {
+			d.Subject = value
+			continue
+		}
+		for _, item := range splitAddresses(value) {
+			if contacts.IsReference(item) {
+				*addresses = append(*addresses, strings.TrimSpace(item))
+				continue
+			}
+			parsed, err := message.ParseAddressList(item)
+			if err != nil {
+				return Draft{}, fmt.Errorf("%w: %s: %w", ErrInvalidDraft, key, err)
+			}
+			*addresses = append(*addresses, parsed...)
+		}
+	}
+	return d, nil
+}
+
+// splitAddresses splits an address list at the commas outside quoted names, angle brackets and comments
+func splitAddresses(value string) []string {
+	var items []string
+	start, depth := 0, 0
+	quoted, escaped := false, false
+	for i, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '<' || r == '(':
+			depth++
+		case r == '>' || r == ')':
+			depth = max(depth-1, 0)
+		case r == ',' && depth == 0:
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, value[start:])
+}
+
+// addresses returns the address field of the header, or nil for the subject
+func (d *Draft) addresses(key string) *[]string {
+	switch key {
+	case "From":
+		return &d.From
+	case "To":
+		return &d.To
+	case "Cc":
+		return &d.Cc
+	case "Bcc":
+		return &d.Bcc
+	case "Reply-To":
+		return &d.ReplyTo
+	}
+	return nil
+}
+
+func canonicalHeader(key string) string {
+	key = strings.TrimSpace(key)
+	for _, header := range headers {
+		if strings.EqualFold(key, header) {
+			return header
+		}
+	}
+	return ""
+}
+
+// Editor returns the editor command from $VISUAL or $EDITOR, falling back to vi
+func Editor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// Edit writes the initial content to a temporary file and opens it in the editor.
+// It returns the path of the file along with the edited content;
+// the caller removes the file once the draft is no longer needed.
+func Edit(editor string, initial []byte) (string, []byte, error) {
+	file, err := os.CreateTemp("", "mailbox-cli-draft-*.txt")
+	if err != nil {
+		return "", nil, err
+	}
+	path := file.Name()
+	_, err = file.Write(initial)
+	if err = errors.Join(err, file.Close()); err != nil {
+		return path, nil, err
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return path, nil, errors.New("no editor configured")
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return path, nil, fmt.Errorf("editor %s: %w", args[0], err)
+	}
+
+	content, err := os.ReadFile(path)
+	return path, content, err
+}
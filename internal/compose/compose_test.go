@@ -0,0 +1,121 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	data := Format(Draft{
+		From:    []string{"Alice <alice@example.com>"},
+		To:      []string{"bob@example.com", "carol@example.com"},
+		Subject: "Hello",
+		Body:    "Hi\n",
+	})
+	assert.Equal(t, "From: Alice <alice@example.com>\n"+
+		"To: bob@example.com, carol@example.com\n"+
+		"Cc: \nBcc: \nReply-To: \nSubject: Hello\n\nHi\n", string(data))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected Draft
+		err      error
+	}{
+		{
+			data: "From: Alice <alice@example.com>\n" +
+				"to: bob@example.com,\n carol@example.com\n" +
+				"Cc:\nSubject: Hello\n\nHi\n\nBye\n",
+			expected: Draft{
+				From:    []string{"Alice <alice@example.com>"},
+				To:      []string{"bob@example.com", "carol@example.com"},
+				Subject: "Hello",
+				Body:    "Hi\n\nBye\n",
+			},
+		},
+		{
+			// references to contacts are kept for the caller to expand
+			data: "To: @oncall, Bob <bob@example.com>\nCc: \"Doe, John\" <john@example.com>, @alice\n\nbody",
+			expected: Draft{
+				To:   []string{"@oncall", "Bob <bob@example.com>"},
+				Cc:   []string{`"Doe, John" <john@example.com>`, "@alice"},
+				Body: "body",
+			},
+		},
+		{
+			data:     "Subject: no body",
+			expected: Draft{Subject: "no body"},
+		},
+		{
+			data: " \n\n",
+			err:  ErrEmptyDraft,
+		},
+		{
+			data: "To: bob@\n\nbody",
+			err:  ErrInvalidDraft,
+		},
+		{
+			data: "X-Custom: value\n\nbody",
+			err:  ErrInvalidDraft,
+		},
+		{
+			data: "not a header\n\nbody",
+			err:  ErrInvalidDraft,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			draft, err := Parse([]byte(test.data))
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, draft)
+		})
+	}
+
+	// round trip
+	d := Draft{
+		From:    []string{`"Doe, John" <john@example.com>`},
+		ReplyTo: []string{"reply@example.com"},
+		Subject: "Hello: World",
+		Body:    "body\n",
+	}
+	parsed, err := Parse(Format(d))
+	assert.Nil(t, err)
+	assert.Equal(t, d, parsed)
+}
+
+func TestEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	assert.Equal(t, "vi", Editor())
+
+	t.Setenv("EDITOR", "nano")
+	assert.Equal(t, "nano", Editor())
+
+	t.Setenv("VISUAL", "code --wait")
+	assert.Equal(t, "code --wait", Editor())
+}
+
+func TestEdit(t *testing.T) {
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	err := os.WriteFile(editor, []byte("#!/bin/sh\necho \"$1\" >> \"$2\"\n"), 0o700)
+	assert.Nil(t, err)
+
+	path, content, err := Edit(editor+" edited", []byte("initial\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "initial\nedited\n", string(content))
+	assert.Nil(t, os.Remove(path))
+
+	path, _, err = Edit("false", nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, os.Remove(path))
+}
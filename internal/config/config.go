@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// The environment variables recognized by the CLI
+const (
+	EnvProfile  = "MAILBOX_PROFILE"
+	EnvAPIID    = "MAILBOX_API_ID"
+	EnvRegion   = "MAILBOX_REGION"
+	EnvEndpoint = "MAILBOX_ENDPOINT"
+)
+
+// DefaultProfile is used when no profile is selected
+const DefaultProfile = "default"
+
+// The keys that can be stored in a profile
+const (
+	KeyAPIID    = "api-id"
+	KeyRegion   = "region"
+	KeyEndpoint = "endpoint"
+)
+
+// Keys lists all profile keys in display order
+var Keys = []string{KeyAPIID, KeyRegion, KeyEndpoint}
+
+var (
+	ErrInvalidKey      = errors.New("invalid key")
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+// Profile holds the client settings of a named profile
+type Profile struct {
+	APIID    string `yaml:"api-id,omitempty"`
+	Region   string `yaml:"region,omitempty"`
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// Get returns the value of key in the profile
+func (p Profile) Get(key string) (string, error) {
+	switch key {
+	case KeyAPIID:
+		return p.APIID, nil
+	case KeyRegion:
+		return p.Region, nil
+	case KeyEndpoint:
+		return p.Endpoint, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidKey, key)
+}
+
+// Set updates the value of key in the profile
+func (p *Profile) Set(key, value string) error {
+	switch key {
+	case KeyAPIID:
+		p.APIID = value
+	case KeyRegion:
+		p.Region = value
+	case KeyEndpoint:
+		p.Endpoint = value
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// Config is the content of the configuration file
+type Config struct {
+	CurrentProfile string             `yaml:"current-profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+var userHomeDir = os.UserHomeDir
+
+// Dir returns the directory of the configuration files,
+// which is $XDG_CONFIG_HOME/mailbox-cli or ~/.config/mailbox-cli.
+func Dir() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := userHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "mailbox-cli"), nil
+}
+
+// Path returns the location of the configuration file, which is config.yaml in Dir.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads the configuration file at path. A missing file results in an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration file to path, creating parent directories if needed.
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// ProfileName returns the profile selected by name, the MAILBOX_PROFILE environment variable,
+// or the current profile of the config, in that order.
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if env := os.Getenv(EnvProfile); env != "" {
+		return env
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// Profile returns the profile with the given name, or an empty profile if it doesn't exist
+func (c *Config) Profile(name string) Profile {
+	return c.Profiles[name]
+}
+
+// SetProfile stores the profile under the given name
+func (c *Config) SetProfile(name string, profile Profile) {
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = profile
+}
+
+// Use sets the current profile. The profile must exist.
+func (c *Config) Use(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	c.CurrentProfile = name
+	return nil
+}
+
+// ProfileNames returns the names of all profiles in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ResolveOptions contains the explicitly provided settings, usually from command line flags
+type ResolveOptions struct {
+	Profile  string
+	APIID    string
+	Region   string
+	Endpoint string
+}
+
+// Resolve determines the effective settings with the precedence flag > env > profile.
+// Settings that stay empty fall back to the defaults of email.Client.
+func Resolve(options ResolveOptions) (Profile, error) {
+	path, err := Path()
+	if err != nil {
+		return Profile{}, err
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	name := cfg.ProfileName(options.Profile)
+	profile, ok := cfg.Profiles[name]
+	if !ok && (options.Profile != "" || os.Getenv(EnvProfile) != "") {
+		// an explicitly requested profile must exist
+		return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	return Profile{
+		APIID:    firstNonEmpty(options.APIID, os.Getenv(EnvAPIID), profile.APIID),
+		Region:   firstNonEmpty(options.Region, os.Getenv(EnvRegion), profile.Region),
+		Endpoint: firstNonEmpty(options.Endpoint, os.Getenv(EnvEndpoint), profile.Endpoint),
+	}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
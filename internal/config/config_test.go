@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	path, err := Path()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("/xdg", "mailbox-cli", "config.yaml"), path)
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	defer func() {
+		userHomeDir = os.UserHomeDir
+	}()
+	userHomeDir = func() (string, error) {
+		return "/home/user", nil
+	}
+	path, err = Path()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("/home/user", ".config", "mailbox-cli", "config.yaml"), path)
+
+	userHomeDir = func() (string, error) {
+		return "", errors.New("error")
+	}
+	_, err = Path()
+	assert.Equal(t, errors.New("error"), err)
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mailbox-cli", "config.yaml")
+
+	cfg, err := Load(path)
+	assert.Nil(t, err)
+	assert.Empty(t, cfg.Profiles)
+
+	cfg.SetProfile("staging", Profile{APIID: "staging-id", Region: "us-west-2"})
+	cfg.SetProfile("production", Profile{Endpoint: "https://example.com"})
+	err = cfg.Use("production")
+	assert.Nil(t, err)
+	err = cfg.Use("invalid")
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+
+	err = cfg.Save(path)
+	assert.Nil(t, err)
+
+	loaded, err := Load(path)
+	assert.Nil(t, err)
+	assert.Equal(t, cfg, loaded)
+	assert.Equal(t, []string{"production", "staging"}, loaded.ProfileNames())
+
+	err = os.WriteFile(path, []byte("profiles: ["), 0o600)
+	assert.Nil(t, err)
+	_, err = Load(path)
+	assert.NotNil(t, err)
+}
+
+func TestProfile_GetSet(t *testing.T) {
+	profile := Profile{}
+	for _, key := range Keys {
+		err := profile.Set(key, key+"-value")
+		assert.Nil(t, err)
+		value, err := profile.Get(key)
+		assert.Nil(t, err)
+		assert.Equal(t, key+"-value", value)
+	}
+
+	err := profile.Set("invalid", "value")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = profile.Get("invalid")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestConfig_ProfileName(t *testing.T) {
+	cfg := &Config{}
+	t.Setenv(EnvProfile, "")
+	assert.Equal(t, DefaultProfile, cfg.ProfileName(""))
+
+	cfg.CurrentProfile = "current"
+	assert.Equal(t, "current", cfg.ProfileName(""))
+
+	t.Setenv(EnvProfile, "env")
+	assert.Equal(t, "env", cfg.ProfileName(""))
+	assert.Equal(t, "flag", cfg.ProfileName("flag"))
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvAPIID, "")
+	t.Setenv(EnvRegion, "")
+	t.Setenv(EnvEndpoint, "")
+
+	cfg := &Config{CurrentProfile: "staging"}
+	cfg.SetProfile("staging", Profile{APIID: "staging-id", Region: "us-west-2"})
+	cfg.SetProfile("production", Profile{APIID: "production-id", Region: "us-east-1"})
+	err := cfg.Save(filepath.Join(dir, "mailbox-cli", "config.yaml"))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		env      map[string]string
+		options  ResolveOptions
+		expected Profile
+		err      error
+	}{
+		{
+			expected: Profile{APIID: "staging-id", Region: "us-west-2"},
+		},
+		{
+			options:  ResolveOptions{Profile: "production"},
+			expected: Profile{APIID: "production-id", Region: "us-east-1"},
+		},
+		{
+			env:      map[string]string{EnvProfile: "production", EnvRegion: "eu-west-1"},
+			expected: Profile{APIID: "production-id", Region: "eu-west-1"},
+		},
+		{
+			env:      map[string]string{EnvAPIID: "env-id", EnvEndpoint: "https://env.example.com"},
+			options:  ResolveOptions{APIID: "flag-id"},
+			expected: Profile{APIID: "flag-id", Region: "us-west-2", Endpoint: "https://env.example.com"},
+		},
+		{
+			options: ResolveOptions{Profile: "invalid"},
+			err:     ErrProfileNotFound,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			profile, err := Resolve(test.options)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, profile)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package contacts
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/harryzcy/mailbox-cli/internal/config"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	ErrContactNotFound = errors.New("contact not found")
+	ErrUnknownAlias    = errors.New("unknown alias or group")
+	ErrInvalidContact  = errors.New("invalid contact")
+)
+
+// Contact is an entry of the address book
+type Contact struct {
+	Name   string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Email  string   `yaml:"email" json:"email"`
+	Alias  string   `yaml:"alias,omitempty" json:"alias,omitempty"`
+	Groups []string `yaml:"groups,omitempty" json:"groups,omitempty"`
+}
+
+// List is a list of contacts, shown one per row by the table output
+type List []Contact
+
+func (l List) TableHeader() []string {
+	return []string{"NAME", "EMAIL", "ALIAS", "GROUPS"}
+}
+
+func (l List) TableRows() [][]any {
+	rows := make([][]any, len(l))
+	for i, c := range l {
+		rows[i] = []any{c.Name, c.Email, c.Alias, c.Groups}
+	}
+	return rows
+}
+
+// Address returns the contact in the form of "Name <address>"
+func (c Contact) Address() string {
+	return email.FormatAddress(&mail.Address{Name: c.Name, Address: c.Email})
+}
+
+// Book is the address book, stored as contacts.yaml in the configuration directory
+type Book struct {
+	Contacts []Contact `yaml:"contacts,omitempty"`
+}
+
+// Path returns the location of the address book
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "contacts.yaml"), nil
+}
+
+// Load reads the address book at path. A missing file results in an empty book.
+func Load(path string) (*Book, error) {
+	book := &Book{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, book); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return book, nil
+}
+
+// Save writes the address book to path, creating parent directories if needed.
+func (b *Book) Save(path string) error {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// namePattern restricts aliases and groups to names that can be typed after @ without quoting
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Add adds the contact, or updates the existing contact with the same email address.
+// When updating, empty fields keep their existing values and groups are merged.
+// It reports whether the contact is new.
+func (b *Book) Add(c Contact) (bool, error) {
+	c, err := normalize(c)
+	if err != nil {
+		return false, err
+	}
+
+	i := b.index(c.Email)
+	if c.Alias != "" {
+		if j := b.aliasIndex(c.Alias); j >= 0 && j != i {
+			return false, fmt.Errorf("%w: alias %q is already used by %s", ErrInvalidContact, c.Alias, b.Contacts[j].Email)
+		}
+	}
+	if i < 0 {
+		b.Contacts = append(b.Contacts, c)
+		return true, nil
+	}
+
+	existing := &b.Contacts[i]
+	if c.Name != "" {
+		existing.Name = c.Name
+	}
+	if c.Alias != "" {
+		existing.Alias = c.Alias
+	}
+	for _, group := range c.Groups {
+		if !slices.Contains(existing.Groups, group) {
+			existing.Groups = append(existing.Groups, group)
+		}
+	}
+	return false, nil
+}
+
+// normalize validates the contact and converts the email address to its canonical form
+func normalize(c Contact) (Contact, error) {
+	list, err := email.ParseAddresses(c.Email)
+	if err != nil {
+		return Contact{}, fmt.Errorf("%w: email %q: %w", ErrInvalidContact, c.Email, err)
+	}
+	if len(list) != 1 {
+		return Contact{}, fmt.Errorf("%w: email %q: expected a single address", ErrInvalidContact, c.Email)
+	}
+	c.Email = list[0].Address
+	if c.Name == "" {
+		c.Name = list[0].Name
+	}
+
+	c.Alias = strings.TrimPrefix(strings.TrimSpace(c.Alias), "@")
+	if c.Alias != "" && !namePattern.MatchString(c.Alias) {
+		return Contact{}, fmt.Errorf("%w: alias %q may only contain letters, digits, '.', '_' and '-'", ErrInvalidContact, c.Alias)
+	}
+	groups := make([]string, 0, len(c.Groups))
+	for _, group := range c.Groups {
+		group = strings.TrimPrefix(strings.TrimSpace(group), "@")
+		if !namePattern.MatchString(group) {
+			return Contact{}, fmt.Errorf("%w: group %q may only contain letters, digits, '.', '_' and '-'", ErrInvalidContact, group)
+		}
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	c.Groups = groups
+	return c, nil
+}
+
+// Remove removes the contact with the email address or alias
+func (b *Book) Remove(key string) (Contact, error) {
+	i := b.index(key)
+	if i < 0 {
+		i = b.aliasIndex(strings.TrimPrefix(key, "@"))
+	}
+	if i < 0 {
+		return Contact{}, fmt.Errorf("%w: %s", ErrContactNotFound, key)
+	}
+	c := b.Contacts[i]
+	b.Contacts = slices.Delete(b.Contacts, i, i+1)
+	return c, nil
+}
+
+// Get returns the contact with the email address
+func (b *Book) Get(address string) (Contact, bool) {
+	i := b.index(address)
+	if i < 0 {
+		return Contact{}, false
+	}
+	return b.Contacts[i], true
+}
+
+// Group returns the contacts in the group, in the order they were added
+func (b *Book) Group(name string) []Contact {
+	var members []Contact
+	for _, c := range b.Contacts {
+		if slices.Contains(c.Groups, name) {
+			members = append(members, c)
+		}
+	}
+	return members
+}
+
+func (b *Book) index(address string) int {
+	return slices.IndexFunc(b.Contacts, func(c Contact) bool {
+		return strings.EqualFold(c.Email, address)
+	})
+}
+
+func (b *Book) aliasIndex(alias string) int {
+	if alias == "" {
+		return -1
+	}
+	return slices.IndexFunc(b.Contacts, func(c Contact) bool {
+		return strings.EqualFold(c.Alias, alias)
+	})
+}
+
+// IsReference reports whether the value refers to an alias or a group, such as @oncall
+func IsReference(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "@") && namePattern.MatchString(value[1:])
+}
+
+// Expand replaces references to aliases and groups with the addresses of the contacts.
+// An alias takes precedence over a group of the same name. Other values are returned unchanged.
+func (b *Book) Expand(values []string) ([]string, error) {
+	if len(values) == 0 {
+		return values, nil
+	}
+
+	expanded := make([]string, 0, len(values))
+	for _, value := range values {
+		if !IsReference(value) {
+			expanded = append(expanded, value)
+			continue
+		}
+
+		name := strings.TrimSpace(value)[1:]
+		if i := b.aliasIndex(name); i >= 0 {
+			expanded = append(expanded, b.Contacts[i].Address())
+			continue
+		}
+		members := b.Group(name)
+		if len(members) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAlias, value)
+		}
+		for _, c := range members {
+			expanded = append(expanded, c.Address())
+		}
+	}
+	return expanded, nil
+}
+
+// ImportResult reports the outcome of importing contacts into the address book
+type ImportResult struct {
+	Added          int              `json:"added"`
+	Updated        int              `json:"updated"`
+	Skipped        []SkippedContact `json:"skipped,omitempty"`
+	SkippedAliases []SkippedContact `json:"skippedAliases,omitempty"` // contacts imported without their invalid or taken alias
+}
+
+// SkippedContact is a contact that couldn't be imported
+type SkippedContact struct {
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+// Import adds the contacts, skipping invalid ones instead of stopping the import.
+// A contact whose alias is invalid or already taken is added without it.
+func (b *Book) Import(list []Contact) *ImportResult {
+	result := &ImportResult{}
+	for _, c := range list {
+		added, err := b.Add(c)
+		if err != nil && c.Alias != "" {
+			aliasErr := err
+			c.Alias = ""
+			if added, err = b.Add(c); err == nil {
+				result.SkippedAliases = append(result.SkippedAliases, SkippedContact{Email: c.Email, Error: aliasErr.Error()})
+			}
+		}
+		switch {
+		case err != nil:
+			result.Skipped = append(result.Skipped, SkippedContact{Email: c.Email, Error: err.Error()})
+		case added:
+			result.Added++
+		default:
+			result.Updated++
+		}
+	}
+	return result
+}
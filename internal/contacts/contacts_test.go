@@ -0,0 +1,129 @@
+package contacts
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	path, err := Path()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("/xdg", "mailbox-cli", "contacts.yaml"), path)
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mailbox-cli", "contacts.yaml")
+
+	book, err := Load(path)
+	assert.Nil(t, err)
+	assert.Empty(t, book.Contacts)
+
+	_, err = book.Add(Contact{Name: "Alice", Email: "alice@example.com", Alias: "alice", Groups: []string{"oncall"}})
+	assert.Nil(t, err)
+	err = book.Save(path)
+	assert.Nil(t, err)
+
+	loaded, err := Load(path)
+	assert.Nil(t, err)
+	assert.Equal(t, book, loaded)
+}
+
+func TestBook_Add(t *testing.T) {
+	book := &Book{}
+
+	added, err := book.Add(Contact{Email: "Alice Smith <alice@Bücher.example>", Alias: "@alice", Groups: []string{"team"}})
+	assert.Nil(t, err)
+	assert.True(t, added)
+	assert.Equal(t, Contact{Name: "Alice Smith", Email: "alice@xn--bcher-kva.example", Alias: "alice", Groups: []string{"team"}}, book.Contacts[0])
+
+	// update
+	added, err = book.Add(Contact{Email: "ALICE@xn--bcher-kva.example", Groups: []string{"oncall", "team"}})
+	assert.Nil(t, err)
+	assert.False(t, added)
+	assert.Equal(t, Contact{Name: "Alice Smith", Email: "alice@xn--bcher-kva.example", Alias: "alice", Groups: []string{"team", "oncall"}}, book.Contacts[0])
+
+	_, err = book.Add(Contact{Email: "bob@example.com", Alias: "Alice"})
+	assert.ErrorIs(t, err, ErrInvalidContact)
+	_, err = book.Add(Contact{Email: "bob@example.com", Alias: "on call"})
+	assert.ErrorIs(t, err, ErrInvalidContact)
+	_, err = book.Add(Contact{Email: "bob@example.com", Groups: []string{"a,b"}})
+	assert.ErrorIs(t, err, ErrInvalidContact)
+	_, err = book.Add(Contact{Email: "bob@"})
+	assert.ErrorIs(t, err, ErrInvalidContact)
+	_, err = book.Add(Contact{Email: "bob@example.com, carol@example.com"})
+	assert.ErrorIs(t, err, ErrInvalidContact)
+	assert.Len(t, book.Contacts, 1)
+}
+
+func TestBook_Remove(t *testing.T) {
+	book := &Book{Contacts: []Contact{
+		{Email: "alice@example.com", Alias: "alice"},
+		{Email: "bob@example.com"},
+	}}
+
+	removed, err := book.Remove("@alice")
+	assert.Nil(t, err)
+	assert.Equal(t, "alice@example.com", removed.Email)
+	removed, err = book.Remove("BOB@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "bob@example.com", removed.Email)
+	assert.Empty(t, book.Contacts)
+
+	_, err = book.Remove("carol@example.com")
+	assert.ErrorIs(t, err, ErrContactNotFound)
+}
+
+func TestBook_Expand(t *testing.T) {
+	book := &Book{Contacts: []Contact{
+		{Name: "Alice", Email: "alice@example.com", Alias: "alice", Groups: []string{"oncall"}},
+		{Name: "Smith, Bob", Email: "bob@example.com", Groups: []string{"oncall"}},
+		{Email: "carol@example.com", Alias: "team"},
+	}}
+
+	expanded, err := book.Expand([]string{"@oncall", "dave@example.com", " @alice", "@team"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"Alice <alice@example.com>",
+		`"Smith, Bob" <bob@example.com>`,
+		"dave@example.com",
+		"Alice <alice@example.com>",
+		"carol@example.com",
+	}, expanded)
+
+	_, err = book.Expand([]string{"@unknown"})
+	assert.ErrorIs(t, err, ErrUnknownAlias)
+
+	assert.False(t, IsReference("user@example.com"))
+	assert.False(t, IsReference("@"))
+	assert.True(t, IsReference("@on-call.team"))
+}
+
+func TestBook_Import(t *testing.T) {
+	book := &Book{Contacts: []Contact{{Email: "alice@example.com", Alias: "alice"}}}
+	result := book.Import([]Contact{
+		{Name: "Alice", Email: "alice@example.com"},
+		{Name: "Bob", Email: "bob@example.com"},
+		{Name: "Invalid", Email: "invalid"},
+		{Name: "Carol", Email: "carol@example.com", Alias: "carol smith"},
+		{Name: "Dave", Email: "dave@example.com", Alias: "alice"},
+		{Name: "Invalid", Email: "invalid", Alias: "eve smith"},
+	})
+	assert.Equal(t, 3, result.Added)
+	assert.Equal(t, 1, result.Updated)
+	assert.Len(t, result.Skipped, 2)
+	assert.Equal(t, "invalid", result.Skipped[0].Email)
+	assert.Equal(t, "Alice", book.Contacts[0].Name)
+
+	// an invalid or taken alias only drops the alias
+	if assert.Len(t, result.SkippedAliases, 2) {
+		assert.Equal(t, "carol@example.com", result.SkippedAliases[0].Email)
+		assert.Contains(t, result.SkippedAliases[0].Error, `alias "carol smith"`)
+		assert.Equal(t, "dave@example.com", result.SkippedAliases[1].Email)
+		assert.Contains(t, result.SkippedAliases[1].Error, "already used by alice@example.com")
+	}
+	assert.Equal(t, Contact{Name: "Carol", Email: "carol@example.com", Groups: []string{}}, book.Contacts[2])
+	assert.Equal(t, "alice@example.com", book.Contacts[book.aliasIndex("alice")].Email)
+}
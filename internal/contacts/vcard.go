@@ -0,0 +1,320 @@
+package contacts
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// The supported vCard versions
+const (
+	VCardVersion3 = "3.0"
+	VCardVersion4 = "4.0"
+)
+
+var ErrInvalidVCard = errors.New("invalid vCard")
+
+// vcardProperty is a content line of a vCard, such as EMAIL;TYPE=work:alice@example.com
+type vcardProperty struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// ReadVCards reads the contacts from vCard 3.0 or 4.0 data, which may contain several cards.
+// A card results in one contact per EMAIL property, all sharing the FN, NICKNAME and CATEGORIES of the card;
+// the nickname becomes the alias of the preferred address only. Cards without an email address are skipped.
+func ReadVCards(r io.Reader) ([]Contact, error) {
+	var contacts []Contact
+	var card []vcardProperty
+	inCard := false
+
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidVCard, i+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCARD"):
+			if inCard {
+				return nil, fmt.Errorf("%w: line %d: nested BEGIN:VCARD", ErrInvalidVCard, i+1)
+			}
+			inCard = true
+			card = nil
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCARD"):
+			if !inCard {
+				return nil, fmt.Errorf("%w: line %d: END:VCARD without BEGIN:VCARD", ErrInvalidVCard, i+1)
+			}
+			inCard = false
+			contacts = append(contacts, cardContacts(card)...)
+		case inCard:
+			card = append(card, prop)
+		default:
+			return nil, fmt.Errorf("%w: line %d: property outside of a card", ErrInvalidVCard, i+1)
+		}
+	}
+	if inCard {
+		return nil, fmt.Errorf("%w: missing END:VCARD", ErrInvalidVCard)
+	}
+	return contacts, nil
+}
+
+// unfoldLines splits the data into content lines, joining lines that start with a space or tab to the previous one
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseProperty(line string) (vcardProperty, error) {
+	// the value starts after the first colon outside of a quoted parameter value
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return vcardProperty{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	parts := splitUnquoted(line[:colon], ';')
+	name := strings.ToUpper(parts[0])
+	if _, after, ok := strings.Cut(name, "."); ok {
+		// drop the group prefix, such as item1.EMAIL
+		name = after
+	}
+	prop := vcardProperty{name: name, params: map[string][]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			// vCard 2.1 style parameter without a name, such as EMAIL;INTERNET
+			key, value = "TYPE", param
+		}
+		key = strings.ToUpper(key)
+		for _, v := range splitUnquoted(value, ',') {
+			prop.params[key] = append(prop.params[key], strings.Trim(v, `"`))
+		}
+	}
+	return prop, nil
+}
+
+// splitUnquoted splits s at sep, ignoring separators within double quotes
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// isPreferred reports whether the property is marked as preferred, by PREF=1 in vCard 4.0 or TYPE=pref in vCard 3.0
+func (p vcardProperty) isPreferred() bool {
+	if slices.Contains(p.params["PREF"], "1") {
+		return true
+	}
+	return slices.ContainsFunc(p.params["TYPE"], func(t string) bool {
+		return strings.EqualFold(t, "pref")
+	})
+}
+
+func cardContacts(card []vcardProperty) []Contact {
+	var name, nickname string
+	var groups []string
+	var emails []vcardProperty
+	for _, prop := range card {
+		switch prop.name {
+		case "FN":
+			name = unescapeValue(prop.value)
+		case "NICKNAME":
+			if nickname == "" {
+				// only the first nickname can be used as the alias
+				nickname = toName(unescapeValue(splitValue(prop.value, ',')[0]))
+			}
+		case "CATEGORIES":
+			for _, category := range splitValue(prop.value, ',') {
+				if category = toName(unescapeValue(category)); category != "" {
+					groups = append(groups, category)
+				}
+			}
+		case "EMAIL":
+			emails = append(emails, prop)
+		}
+	}
+
+	preferred := slices.IndexFunc(emails, vcardProperty.isPreferred)
+	if preferred < 0 {
+		preferred = 0
+	}
+	contacts := make([]Contact, 0, len(emails))
+	for i, prop := range emails {
+		address := strings.TrimSpace(strings.TrimPrefix(unescapeValue(prop.value), "mailto:"))
+		if address == "" {
+			continue
+		}
+		c := Contact{Name: name, Email: address, Groups: groups}
+		if i == preferred {
+			c.Alias = nickname
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts
+}
+
+// toName converts a nickname or category to an alias or group name, replacing spaces with dashes
+func toName(value string) string {
+	return strings.Join(strings.Fields(value), "-")
+}
+
+// splitValue splits a value at unescaped separators
+func splitValue(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	b := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+func escapeValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace(value)
+}
+
+// WriteVCards writes the contacts as vCards of the given version, one card per contact
+func WriteVCards(w io.Writer, contacts []Contact, version string) error {
+	if version != VCardVersion3 && version != VCardVersion4 {
+		return fmt.Errorf("unsupported vCard version %q, expected %s or %s", version, VCardVersion3, VCardVersion4)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, c := range contacts {
+		name := c.Name
+		if name == "" {
+			// FN is required by both versions
+			name = c.Email
+		}
+
+		lines := []string{"BEGIN:VCARD", "VERSION:" + version, "FN:" + escapeValue(name)}
+		if version == VCardVersion3 {
+			// N is required by vCard 3.0
+			lines = append(lines, "N:"+structuredName(c.Name))
+			lines = append(lines, "EMAIL;TYPE=INTERNET:"+c.Email)
+		} else {
+			lines = append(lines, "EMAIL:"+c.Email)
+		}
+		if c.Alias != "" {
+			lines = append(lines, "NICKNAME:"+escapeValue(c.Alias))
+		}
+		if len(c.Groups) > 0 {
+			categories := make([]string, len(c.Groups))
+			for i, group := range c.Groups {
+				categories[i] = escapeValue(group)
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		lines = append(lines, "END:VCARD")
+
+		for _, line := range lines {
+			if _, err := bw.WriteString(foldLine(line)); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// structuredName guesses the family and given names from the full name, in the form of Family;Given;;;
+func structuredName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ";;;;"
+	}
+	family := fields[len(fields)-1]
+	given := strings.Join(fields[:len(fields)-1], " ")
+	return escapeValue(family) + ";" + escapeValue(given) + ";;;"
+}
+
+// foldLine terminates the line with CRLF, folding it so that no line exceeds 75 octets
+func foldLine(line string) string {
+	const limit = 75
+	b := &strings.Builder{}
+	for len(line) > limit {
+		// avoid splitting a UTF-8 sequence
+		cut := limit
+		if b.Len() > 0 {
+			cut-- // the leading space of continuation lines
+		}
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
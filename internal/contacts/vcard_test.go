@@ -0,0 +1,122 @@
+package contacts
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadVCards(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected []Contact
+		err      bool
+	}{
+		{
+			data: "BEGIN:VCARD\r\n" +
+				"VERSION:3.0\r\n" +
+				"FN:Alice Smith\r\n" +
+				"N:Smith;Alice;;;\r\n" +
+				"NICKNAME:ali,al\r\n" +
+				"EMAIL;TYPE=INTERNET,WORK:alice@work.example\r\n" +
+				"EMAIL;TYPE=INTERNET,HOME,pref:alice@home.example\r\n" +
+				"CATEGORIES:On Call,team\r\n" +
+				"END:VCARD\r\n",
+			expected: []Contact{
+				{Name: "Alice Smith", Email: "alice@work.example", Groups: []string{"On-Call", "team"}},
+				{Name: "Alice Smith", Email: "alice@home.example", Alias: "ali", Groups: []string{"On-Call", "team"}},
+			},
+		},
+		{
+			data: "\ufeffBEGIN:VCARD\n" +
+				"VERSION:4.0\n" +
+				"FN:Smith\\, Bob\n" +
+				"item1.EMAIL;PREF=1:bob@\n" +
+				" example.com\n" +
+				"END:VCARD\n" +
+				"BEGIN:VCARD\n" +
+				"VERSION:4.0\n" +
+				"FN:No Email\n" +
+				"END:VCARD\n",
+			expected: []Contact{
+				{Name: "Smith, Bob", Email: "bob@example.com"},
+			},
+		},
+		{
+			data: "BEGIN:VCARD\nVERSION:4.0\nFN:Alice\n",
+			err:  true,
+		},
+		{
+			data: "FN:Alice\n",
+			err:  true,
+		},
+		{
+			data: "BEGIN:VCARD\nno colon\nEND:VCARD\n",
+			err:  true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			contacts, err := ReadVCards(strings.NewReader(test.data))
+			if test.err {
+				assert.ErrorIs(t, err, ErrInvalidVCard)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, contacts)
+		})
+	}
+}
+
+func TestWriteVCards(t *testing.T) {
+	contacts := []Contact{
+		{Name: "Alice Smith", Email: "alice@example.com", Alias: "alice", Groups: []string{"oncall", "team"}},
+		{Email: "bob@example.com"},
+	}
+
+	buf := &bytes.Buffer{}
+	err := WriteVCards(buf, contacts, VCardVersion3)
+	assert.Nil(t, err)
+	assert.Equal(t, "BEGIN:VCARD\r\n"+
+		"VERSION:3.0\r\n"+
+		"FN:Alice Smith\r\n"+
+		"N:Smith;Alice;;;\r\n"+
+		"EMAIL;TYPE=INTERNET:alice@example.com\r\n"+
+		"NICKNAME:alice\r\n"+
+		"CATEGORIES:oncall,team\r\n"+
+		"END:VCARD\r\n"+
+		"BEGIN:VCARD\r\n"+
+		"VERSION:3.0\r\n"+
+		"FN:bob@example.com\r\n"+
+		"N:;;;;\r\n"+
+		"EMAIL;TYPE=INTERNET:bob@example.com\r\n"+
+		"END:VCARD\r\n", buf.String())
+
+	// round trip
+	buf.Reset()
+	err = WriteVCards(buf, contacts, VCardVersion4)
+	assert.Nil(t, err)
+	assert.NotContains(t, buf.String(), "N:Smith")
+	read, err := ReadVCards(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Contact{contacts[0], {Name: "bob@example.com", Email: "bob@example.com"}}, read)
+
+	err = WriteVCards(buf, contacts, "2.1")
+	assert.NotNil(t, err)
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "FN:" + strings.Repeat("é", 100)
+	folded := foldLine(line)
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(part), 75)
+	}
+
+	lines, err := unfoldLines(strings.NewReader(folded))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{line}, lines)
+}
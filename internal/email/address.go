@@ -0,0 +1,108 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// AddressError reports an address that can't be parsed, along with the field it was given in
+type AddressError struct {
+	Field string // from, to, cc, bcc or reply-to
+	Value string
+	Err   error
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("invalid %s address %q: %s", e.Field, e.Value, e.Err)
+}
+
+func (e *AddressError) Unwrap() []error {
+	return []error{ErrInvalidAddress, e.Err}
+}
+
+// ParseAddresses parses an address list following RFC 5322, such as `Name <user@example.com>, "a.b"@example.com`.
+// Internationalized domains are converted to punycode.
+func ParseAddresses(value string) ([]*mail.Address, error) {
+	list, err := mail.ParseAddressList(value)
+	if err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "mail: "))
+	}
+
+	for _, address := range list {
+		at := strings.LastIndex(address.Address, "@")
+		local, domain := address.Address[:at], address.Address[at+1:]
+		ascii, err := idna.Lookup.ToASCII(domain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid domain %q: %w", domain, err)
+		}
+		address.Address = local + "@" + ascii
+	}
+	return list, nil
+}
+
+// FormatAddress formats the address as "Name <user@example.com>", or the bare address if there is no name.
+// Unlike mail.Address.String, the name is kept in UTF-8 and only quoted if it contains special characters.
+func FormatAddress(address *mail.Address) string {
+	// quote the local part if needed
+	addr := (&mail.Address{Address: address.Address}).String()
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "<"), ">")
+	if address.Name == "" {
+		return addr
+	}
+
+	name := address.Name
+	if strings.ContainsAny(name, `,;:<>@()[]".\`) {
+		name = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+	}
+	return name + " <" + addr + ">"
+}
+
+// normalizeAddresses parses and reformats the address fields in place, so that invalid addresses are reported
+// before making a request. Each value may contain several comma-separated addresses, and blank values are ignored.
+// Duplicates are removed within From and Reply-To, and across To, Cc and Bcc,
+// keeping the first occurrence so that a recipient in To isn't copied again in Cc or Bcc.
+func normalizeAddresses(from, to, cc, bcc, replyTo *[]string) error {
+	recipients := map[string]bool{}
+	for _, field := range []struct {
+		name   string
+		values *[]string
+		seen   map[string]bool
+	}{
+		{"from", from, map[string]bool{}},
+		{"to", to, recipients},
+		{"cc", cc, recipients},
+		{"bcc", bcc, recipients},
+		{"reply-to", replyTo, map[string]bool{}},
+	} {
+		if len(*field.values) == 0 {
+			continue
+		}
+
+		normalized := []string{}
+		for _, value := range *field.values {
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			list, err := ParseAddresses(value)
+			if err != nil {
+				return &AddressError{Field: field.name, Value: value, Err: err}
+			}
+			for _, address := range list {
+				key := strings.ToLower(address.Address)
+				if field.seen[key] {
+					continue
+				}
+				field.seen[key] = true
+				normalized = append(normalized, FormatAddress(address))
+			}
+		}
+		*field.values = normalized
+	}
+	return nil
+}
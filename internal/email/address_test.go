@@ -0,0 +1,83 @@
+package email
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddresses(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+		err      string
+	}{
+		{
+			value:    "alice@example.com",
+			expected: []string{"alice@example.com"},
+		},
+		{
+			value:    `Alice Smith <alice@example.com>, "Smith, Bob" <bob@example.com>`,
+			expected: []string{"Alice Smith <alice@example.com>", `"Smith, Bob" <bob@example.com>`},
+		},
+		{
+			value:    `"john doe"@example.com`,
+			expected: []string{`"john doe"@example.com`},
+		},
+		{
+			value:    "José <jose@Bücher.example>",
+			expected: []string{"José <jose@xn--bcher-kva.example>"},
+		},
+		{
+			value:    "John Q. Public <john@example.com>",
+			expected: []string{`"John Q. Public" <john@example.com>`},
+		},
+		{
+			value: "bob@",
+			err:   "missing '@' or angle-addr",
+		},
+		{
+			value: "bob@exa_mple.com",
+			err:   `invalid domain "exa_mple.com"`,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			list, err := ParseAddresses(test.value)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			addresses := make([]string, len(list))
+			for j, address := range list {
+				addresses[j] = FormatAddress(address)
+			}
+			assert.Equal(t, test.expected, addresses)
+		})
+	}
+}
+
+func TestNormalizeAddresses(t *testing.T) {
+	from := []string{"Alice <alice@example.com>", "alice@example.com"}
+	to := []string{"bob@example.com, Carol <carol@example.com>", " "}
+	cc := []string{"BOB@example.com", "dave@example.com"}
+	bcc := []string{"carol@example.com"}
+	replyTo := []string{"bob@example.com"}
+
+	err := normalizeAddresses(&from, &to, &cc, &bcc, &replyTo)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Alice <alice@example.com>"}, from)
+	assert.Equal(t, []string{"bob@example.com", "Carol <carol@example.com>"}, to)
+	assert.Equal(t, []string{"dave@example.com"}, cc)
+	assert.Equal(t, []string{}, bcc)
+	assert.Equal(t, []string{"bob@example.com"}, replyTo)
+
+	var empty []string
+	cc = []string{"carol@example.com", "not an address"}
+	err = normalizeAddresses(&empty, &empty, &cc, &empty, &empty)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	assert.Equal(t, `invalid cc address "not an address": no angle-addr`, err.Error())
+}
@@ -0,0 +1,133 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxAttachmentsSize is the maximum total size of the files attached to an email.
+// Attachments are base64 encoded in the request, which must stay below the 10 MB payload limit of API Gateway.
+const MaxAttachmentsSize = 7 << 20
+
+var (
+	ErrInvalidAttachment   = errors.New("invalid attachment")
+	ErrAttachmentsTooLarge = fmt.Errorf("attachments exceed the maximum total size of %d MiB", MaxAttachmentsSize>>20)
+)
+
+// FileAttachment is a file attached to an email when creating or saving it.
+// The content is base64 encoded in the JSON payload.
+type FileAttachment struct {
+	Path        string `json:"-"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	ContentID   string `json:"contentID,omitempty"` // only for inline attachments
+	Content     []byte `json:"content"`
+}
+
+// ParseAttachment parses an attachment in the format of path[;type=...;name=...]
+func ParseAttachment(spec string) (FileAttachment, error) {
+	parts := strings.Split(spec, ";")
+	attachment := FileAttachment{Path: strings.TrimSpace(parts[0])}
+	if attachment.Path == "" {
+		return FileAttachment{}, fmt.Errorf("%w: %q: missing path", ErrInvalidAttachment, spec)
+	}
+
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return FileAttachment{}, fmt.Errorf("%w: %q: expected key=value, got %q", ErrInvalidAttachment, spec, part)
+		}
+		switch strings.TrimSpace(key) {
+		case "type":
+			attachment.ContentType = strings.TrimSpace(value)
+		case "name":
+			attachment.Filename = strings.TrimSpace(value)
+		default:
+			return FileAttachment{}, fmt.Errorf("%w: %q: unknown parameter %q", ErrInvalidAttachment, spec, key)
+		}
+	}
+
+	return attachment, nil
+}
+
+// ParseInline parses an inline attachment in the format of cid=path[;type=...;name=...]
+func ParseInline(spec string) (FileAttachment, error) {
+	cid, rest, ok := strings.Cut(spec, "=")
+	cid = strings.Trim(strings.TrimSpace(cid), "<>")
+	if !ok || cid == "" {
+		return FileAttachment{}, fmt.Errorf("%w: %q: expected cid=path", ErrInvalidAttachment, spec)
+	}
+
+	attachment, err := ParseAttachment(rest)
+	if err != nil {
+		return FileAttachment{}, err
+	}
+	attachment.ContentID = cid
+	return attachment, nil
+}
+
+// loadAttachments reads the content of the attachments from their paths,
+// filling in the filename and content type if not provided.
+func loadAttachments(attachments ...[]FileAttachment) error {
+	var total int64
+	for _, list := range attachments {
+		for _, attachment := range list {
+			if attachment.Content != nil {
+				total += int64(len(attachment.Content))
+				continue
+			}
+			info, err := os.Stat(attachment.Path)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return fmt.Errorf("%w: %s is a directory", ErrInvalidAttachment, attachment.Path)
+			}
+			total += info.Size()
+		}
+	}
+	// validate before reading any file, so that nothing large is loaded into memory
+	if total > MaxAttachmentsSize {
+		return ErrAttachmentsTooLarge
+	}
+
+	for _, list := range attachments {
+		for i := range list {
+			if err := list[i].load(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *FileAttachment) load() error {
+	if a.Content == nil {
+		content, err := os.ReadFile(a.Path)
+		if err != nil {
+			return err
+		}
+		a.Content = content
+	}
+
+	if a.Filename == "" {
+		a.Filename = filepath.Base(a.Path)
+	}
+	if a.ContentType == "" {
+		a.ContentType = detectContentType(a.Filename, a.Content)
+	}
+	return nil
+}
+
+// detectContentType guesses the content type from the file extension, falling back to content sniffing
+func detectContentType(filename string, content []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(filename)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(content[:min(len(content), 512)])
+}
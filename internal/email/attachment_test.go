@@ -0,0 +1,131 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAttachment(t *testing.T) {
+	tests := []struct {
+		spec       string
+		attachment FileAttachment
+		err        error
+	}{
+		{
+			spec:       "invoice.pdf",
+			attachment: FileAttachment{Path: "invoice.pdf"},
+		},
+		{
+			spec:       "out/invoice.bin;type=application/pdf;name=Invoice 2025.pdf",
+			attachment: FileAttachment{Path: "out/invoice.bin", ContentType: "application/pdf", Filename: "Invoice 2025.pdf"},
+		},
+		{spec: "", err: ErrInvalidAttachment},
+		{spec: "invoice.pdf;type", err: ErrInvalidAttachment},
+		{spec: "invoice.pdf;size=1", err: ErrInvalidAttachment},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			attachment, err := ParseAttachment(test.spec)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.attachment, attachment)
+		})
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	inline, err := ParseInline("<logo>=images/logo.png;type=image/png")
+	assert.Nil(t, err)
+	assert.Equal(t, FileAttachment{Path: "images/logo.png", ContentType: "image/png", ContentID: "logo"}, inline)
+
+	_, err = ParseInline("images/logo.png")
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+	_, err = ParseInline("=images/logo.png")
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+	_, err = ParseInline("logo=")
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+}
+
+func TestLoadAttachments(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "notes.txt")
+	err := os.WriteFile(text, []byte("hello"), 0o600)
+	assert.Nil(t, err)
+	png := filepath.Join(dir, "image")
+	err = os.WriteFile(png, []byte("\x89PNG\r\n\x1a\n0000"), 0o600)
+	assert.Nil(t, err)
+
+	attachments := []FileAttachment{{Path: text}, {Path: text, Filename: "renamed.csv", ContentType: "text/csv"}}
+	inlines := []FileAttachment{{Path: png, ContentID: "logo"}}
+	err = loadAttachments(attachments, inlines)
+	assert.Nil(t, err)
+	assert.Equal(t, FileAttachment{Path: text, Filename: "notes.txt", ContentType: "text/plain; charset=utf-8", Content: []byte("hello")}, attachments[0])
+	assert.Equal(t, "renamed.csv", attachments[1].Filename)
+	assert.Equal(t, "text/csv", attachments[1].ContentType)
+	assert.Equal(t, "image/png", inlines[0].ContentType)
+	assert.Equal(t, "image", inlines[0].Filename)
+
+	err = loadAttachments([]FileAttachment{{Path: filepath.Join(dir, "missing")}})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	err = loadAttachments([]FileAttachment{{Path: dir}})
+	assert.ErrorIs(t, err, ErrInvalidAttachment)
+
+	large := filepath.Join(dir, "large")
+	err = os.WriteFile(large, make([]byte, MaxAttachmentsSize/2+1), 0o600)
+	assert.Nil(t, err)
+	err = loadAttachments([]FileAttachment{{Path: large}}, []FileAttachment{{Path: large}})
+	assert.ErrorIs(t, err, ErrAttachmentsTooLarge)
+}
+
+func TestClient_CreateWithAttachments(t *testing.T) {
+	var payload map[string]any
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(`{"messageID": "message-id"}`))
+		assert.Nil(t, err)
+	})
+
+	path := filepath.Join(t.TempDir(), "invoice.pdf")
+	err := os.WriteFile(path, []byte("%PDF-1.7"), 0o600)
+	assert.Nil(t, err)
+
+	client := Client{
+		Endpoint: ts.URL,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+	}
+	_, err = client.Create(context.Background(), CreateOptions{
+		Subject:     "Invoice",
+		Attachments: []FileAttachment{{Path: path}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []any{
+		map[string]any{
+			"filename":    "invoice.pdf",
+			"contentType": "application/pdf",
+			"content":     "JVBERi0xLjc=",
+		},
+	}, payload["attachments"])
+	assert.NotContains(t, payload, "inlines")
+
+	_, err = client.Save(context.Background(), SaveOptions{
+		MessageID: "message-id",
+		Inlines:   []FileAttachment{{Path: path + ".missing", ContentID: "logo"}},
+	})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
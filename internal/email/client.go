@@ -7,9 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -20,9 +22,30 @@ type Client struct {
 	Region      string
 	Endpoint    string
 	Credentials aws.CredentialsProvider
+	HTTPClient  *http.Client // optional, defaults to a client that times out on unresponsive servers
+	RetryPolicy *RetryPolicy // optional, requests are attempted once if nil
 	Verbose     bool
 }
 
+// defaultResponseHeaderTimeout limits how long to wait for the response headers,
+// without limiting the time to read the response body.
+const defaultResponseHeaderTimeout = 60 * time.Second
+
+var defaultHTTPClient = newDefaultHTTPClient()
+
+func newDefaultHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = defaultResponseHeaderTimeout
+	return &http.Client{Transport: transport}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
 func (c *Client) getEndpoint() string {
 	if c.Endpoint != "" {
 		return c.Endpoint
@@ -46,7 +69,58 @@ func (c *Client) loadCredentials(ctx context.Context) error {
 
 var ioReadall = io.ReadAll
 
-func (c Client) request(ctx context.Context, method string, path string, query url.Values, payload []byte) (text string, err error) {
+func (c Client) request(ctx context.Context, method string, path string, query url.Values, payload []byte) (data []byte, err error) {
+	resp, err := c.send(ctx, method, path, query, payload, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+	}()
+
+	data, err = ioReadall(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Verbose {
+		fmt.Printf("[DEBUG] Content-Type: %s\n", resp.Header.Get("Content-Type"))
+		fmt.Printf("[DEBUG] Response: %s\n", string(data))
+	}
+
+	return data, nil
+}
+
+// send performs the request, retrying according to the RetryPolicy,
+// and returns the first successful response. Non-2xx responses are returned as *APIError.
+// The caller is responsible for closing the response body.
+func (c Client) send(ctx context.Context, method string, path string, query url.Values, payload []byte, accept string) (*http.Response, error) {
+	policy := c.RetryPolicy
+	retryable := policy != nil && isIdempotent(method, path)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, method, path, query, payload, accept)
+		if err == nil {
+			return resp, nil
+		}
+		if !retryable || attempt >= policy.maxAttempts() || !isRetryable(ctx, err) {
+			return nil, err
+		}
+
+		delay := policy.backoff(attempt, retryAfter(err))
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Attempt %d failed: %s, retrying in %s\n", attempt, err, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt signs and sends the request once
+func (c Client) attempt(ctx context.Context, method string, path string, query url.Values, payload []byte, accept string) (*http.Response, error) {
 	body := bytes.NewReader(payload)
 
 	if c.Verbose {
@@ -55,7 +129,7 @@ func (c Client) request(ctx context.Context, method string, path string, query u
 
 	req, err := http.NewRequestWithContext(ctx, method, c.getEndpoint()+path, body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.URL.RawQuery = query.Encode()
 	if method == http.MethodPost || method == http.MethodPut {
@@ -66,7 +140,7 @@ func (c Client) request(ctx context.Context, method string, path string, query u
 		fmt.Printf("[DEBUG] Request URL: %s\n", req.URL.String())
 	}
 
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", accept)
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Signing request\n")
@@ -79,45 +153,32 @@ func (c Client) request(ctx context.Context, method string, path string, query u
 		Verbose:     c.Verbose,
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Sending request\n")
 	}
 
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer func() {
-		closeErr := resp.Body.Close()
-		err = errors.Join(err, closeErr)
-	}()
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Response status: %d\n", resp.StatusCode)
 	}
 
-	data, err := ioReadall(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	if c.Verbose {
-		fmt.Printf("[DEBUG] Content-Type: %s\n", resp.Header["Content-Type"][0])
-		fmt.Printf("[DEBUG] Response: %s\n", string(data))
-	}
-
-	if resp.Header["Content-Type"][0] == "application/json" {
-		if c.Verbose {
-			fmt.Printf("[DEBUG] Prettifying output\n")
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, err := ioReadall(resp.Body)
+		err = errors.Join(err, resp.Body.Close())
+		if err != nil {
+			return nil, err
 		}
-		return prettyResult(data)
+		return nil, newAPIError(resp, data)
 	}
 
-	return string(data), nil
+	return resp, nil
 }
 
 type ListOptions struct {
@@ -140,19 +201,44 @@ func (o ListOptions) check() error {
 	return nil
 }
 
-func (c *Client) List(options ListOptions) (string, error) {
+// ListAll returns an iterator over all emails matching the options,
+// lazily fetching the next page when the current one is exhausted.
+// Iteration stops after the first error, which is yielded with a zero Email.
+func (c *Client) ListAll(ctx context.Context, options ListOptions) iter.Seq2[Email, error] {
+	return func(yield func(Email, error) bool) {
+		for {
+			result, err := c.List(ctx, options)
+			if err != nil {
+				yield(Email{}, err)
+				return
+			}
+
+			for _, item := range result.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if result.NextCursor == "" || result.NextCursor == options.NextCursor {
+				return
+			}
+			options.NextCursor = result.NextCursor
+		}
+	}
+}
+
+func (c *Client) List(ctx context.Context, options ListOptions) (*ListResult, error) {
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Listing emails\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	q := url.Values{}
@@ -161,15 +247,20 @@ func (c *Client) List(options ListOptions) (string, error) {
 	addQuery(q, "month", options.Month)
 	addQuery(q, "order", options.Order)
 	addQuery(q, "next_cursor", options.NextCursor)
-	result, err := c.request(ctx, http.MethodGet, "/emails", q, nil)
+	data, err := c.request(ctx, http.MethodGet, "/emails", q, nil)
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return nil, err
 	}
 
-	return result, nil
+	var result ListResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
 type GetOptions struct {
@@ -184,31 +275,79 @@ func (o GetOptions) check() error {
 	return nil
 }
 
-func (c *Client) Get(options GetOptions) (string, error) {
+func (c *Client) Get(ctx context.Context, options GetOptions) (*Email, error) {
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Getting email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	q := url.Values{}
+	data, err := c.request(ctx, http.MethodGet, "/emails/"+options.MessageID, q, nil)
+	if err != nil {
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Error: %s\n", err)
+		}
+		return nil, err
+	}
+
+	var result Email
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+type GetThreadOptions struct {
+	ThreadID string
+}
+
+func (o GetThreadOptions) check() error {
+	if o.ThreadID == "" {
+		return errors.New("invalid thread id")
+	}
+
+	return nil
+}
+
+// GetThread returns the emails of a thread, as grouped by the backend
+func (c *Client) GetThread(ctx context.Context, options GetThreadOptions) (*Thread, error) {
+	if err := options.check(); err != nil {
+		return nil, err
+	}
+
+	if c.Verbose {
+		fmt.Printf("[DEBUG] Getting thread\n")
+	}
+
+	err := c.loadCredentials(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodGet, "/emails/"+options.MessageID, q, nil)
+	data, err := c.request(ctx, http.MethodGet, "/threads/"+options.ThreadID, q, nil)
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return nil, err
 	}
 
-	return result, nil
+	var result Thread
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
 type TrashOptions struct {
@@ -223,31 +362,35 @@ func (o TrashOptions) check() error {
 	return nil
 }
 
-func (c *Client) Trash(options TrashOptions) (string, error) {
+func (c *Client) Trash(ctx context.Context, options TrashOptions) (*ActionResult, error) {
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Trashing email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/trash", q, nil)
+	data, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/trash", q, nil)
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return nil, err
+	}
+
+	var result ActionResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &result, nil
 }
 
 type UntrashOptions struct {
@@ -262,31 +405,121 @@ func (o UntrashOptions) check() error {
 	return nil
 }
 
-func (c *Client) Untrash(options UntrashOptions) (string, error) {
+func (c *Client) Untrash(ctx context.Context, options UntrashOptions) (*ActionResult, error) {
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Untrashing email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	q := url.Values{}
+	data, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/untrash", q, nil)
+	if err != nil {
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Error: %s\n", err)
+		}
+		return nil, err
+	}
+
+	var result ActionResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+type ReadOptions struct {
+	MessageID string
+}
+
+func (o ReadOptions) check() error {
+	if o.MessageID == "" {
+		return errors.New("invalid message id")
+	}
+
+	return nil
+}
+
+func (c *Client) Read(ctx context.Context, options ReadOptions) (*ActionResult, error) {
+	if err := options.check(); err != nil {
+		return nil, err
+	}
+
+	if c.Verbose {
+		fmt.Printf("[DEBUG] Marking as read email\n")
+	}
+
+	err := c.loadCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	data, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/read", q, nil)
+	if err != nil {
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Error: %s\n", err)
+		}
+		return nil, err
+	}
+
+	var result ActionResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+type UnreadOptions struct {
+	MessageID string
+}
+
+func (o UnreadOptions) check() error {
+	if o.MessageID == "" {
+		return errors.New("invalid message id")
+	}
+
+	return nil
+}
+
+func (c *Client) Unread(ctx context.Context, options UnreadOptions) (*ActionResult, error) {
+	if err := options.check(); err != nil {
+		return nil, err
+	}
+
+	if c.Verbose {
+		fmt.Printf("[DEBUG] Marking as unread email\n")
+	}
+
+	err := c.loadCredentials(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/untrash", q, nil)
+	data, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/unread", q, nil)
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return nil, err
+	}
+
+	var result ActionResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &result, nil
 }
 
 type DeleteOptions struct {
@@ -301,31 +534,35 @@ func (o DeleteOptions) check() error {
 	return nil
 }
 
-func (c *Client) Delete(options DeleteOptions) (string, error) {
+func (c *Client) Delete(ctx context.Context, options DeleteOptions) (*ActionResult, error) {
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Deleting email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodDelete, "/emails/"+options.MessageID, q, nil)
+	data, err := c.request(ctx, http.MethodDelete, "/emails/"+options.MessageID, q, nil)
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return nil, err
 	}
 
-	return result, nil
+	var result ActionResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
 type CreateOptions struct {
@@ -340,6 +577,14 @@ type CreateOptions struct {
 	GenerateText string   `json:"generateText"`
 	Send         bool     `json:"send"`
 
+	// Message-ID headers, without the angle brackets, of the email being replied to and its thread,
+	// which the API turns into the In-Reply-To and References headers
+	InReplyTo  string   `json:"inReplyTo,omitempty"`
+	References []string `json:"references,omitempty"`
+
+	Attachments []FileAttachment `json:"attachments,omitempty"`
+	Inlines     []FileAttachment `json:"inlines,omitempty"`
+
 	File string `json:"-"`
 }
 
@@ -372,43 +617,53 @@ func (o *CreateOptions) loadFile() error {
 	return err
 }
 
-func (c *Client) Create(options CreateOptions) (string, error) {
+func (c *Client) Create(ctx context.Context, options CreateOptions) (*Email, error) {
 	if err := options.loadFile(); err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := normalizeAddresses(&options.From, &options.To, &options.Cc, &options.Bcc, &options.ReplyTo); err != nil {
+		return nil, err
+	}
+	if err := loadAttachments(options.Attachments, options.Inlines); err != nil {
+		return nil, err
 	}
 
 	if options.GenerateText == "" {
 		options.GenerateText = GenerateTextAuto
 	}
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Creating email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	body, err := json.Marshal(options)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodPost, "/emails", q, body)
+	data, err := c.request(ctx, http.MethodPost, "/emails", q, body)
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return nil, err
+	}
+
+	var result Email
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
 	}
 
-	return result, err
+	return &result, nil
 }
 
 type SaveOptions struct {
@@ -425,6 +680,9 @@ type SaveOptions struct {
 	GenerateText string   `json:"generateText"`
 	Send         bool     `json:"send"`
 
+	Attachments []FileAttachment `json:"attachments,omitempty"`
+	Inlines     []FileAttachment `json:"inlines,omitempty"`
+
 	File string `json:"-"`
 }
 
@@ -467,37 +725,53 @@ func (o *SaveOptions) loadFile() error {
 	return err
 }
 
-func (c *Client) Save(options SaveOptions) (string, error) {
+func (c *Client) Save(ctx context.Context, options SaveOptions) (*Email, error) {
 	if err := options.loadFile(); err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := normalizeAddresses(&options.From, &options.To, &options.Cc, &options.Bcc, &options.ReplyTo); err != nil {
+		return nil, err
+	}
+	if err := loadAttachments(options.Attachments, options.Inlines); err != nil {
+		return nil, err
 	}
 
 	if options.GenerateText == "" {
 		options.GenerateText = GenerateTextAuto
 	}
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Saving email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	body, err := json.Marshal(options)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodPut, "/emails/"+options.MessageID, q, body)
+	data, err := c.request(ctx, http.MethodPut, "/emails/"+options.MessageID, q, body)
+	if err != nil {
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Error: %s\n", err)
+		}
+		return nil, err
+	}
+
+	var result Email
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
 
-	return result, err
+	return &result, nil
 }
 
 type SendOptions struct {
@@ -512,29 +786,153 @@ func (o SendOptions) check() error {
 	return nil
 }
 
-func (c *Client) Send(options SendOptions) (string, error) {
+func (c *Client) Send(ctx context.Context, options SendOptions) (*ActionResult, error) {
 	if err := options.check(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if c.Verbose {
 		fmt.Printf("[DEBUG] Sending email\n")
 	}
 
-	ctx := context.Background()
 	err := c.loadCredentials(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	q := url.Values{}
-	result, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/send", q, nil)
+	data, err := c.request(ctx, http.MethodPost, "/emails/"+options.MessageID+"/send", q, nil)
+	if err != nil {
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Error: %s\n", err)
+		}
+		return nil, err
+	}
+
+	var result ActionResult
+	if err := decodeResult(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+type ListAttachmentsOptions struct {
+	MessageID string
+}
+
+func (o ListAttachmentsOptions) check() error {
+	if o.MessageID == "" {
+		return errors.New("invalid message id")
+	}
+
+	return nil
+}
+
+// ListAttachments returns the attachments and inline files of an email
+func (c *Client) ListAttachments(ctx context.Context, options ListAttachmentsOptions) (*AttachmentsResult, error) {
+	if err := options.check(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.Get(ctx, GetOptions{MessageID: options.MessageID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttachmentsResult{
+		MessageID:   options.MessageID,
+		Attachments: result.Attachments,
+		Inlines:     result.Inlines,
+	}, nil
+}
+
+type DownloadAttachmentOptions struct {
+	MessageID string
+	ContentID string
+	Inline    bool // download an inline file instead of an attachment
+}
+
+func (o DownloadAttachmentOptions) check() error {
+	if o.MessageID == "" {
+		return errors.New("invalid message id")
+	}
+
+	if o.ContentID == "" {
+		return errors.New("invalid content id")
+	}
+
+	return nil
+}
+
+// DownloadAttachment streams the content of an attachment or inline file to w,
+// returning the number of bytes written.
+func (c *Client) DownloadAttachment(ctx context.Context, options DownloadAttachmentOptions, w io.Writer) (int64, error) {
+	if err := options.check(); err != nil {
+		return 0, err
+	}
+
+	if c.Verbose {
+		fmt.Printf("[DEBUG] Downloading attachment\n")
+	}
+
+	err := c.loadCredentials(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	kind := "attachments"
+	if options.Inline {
+		kind = "inlines"
+	}
+	path := "/emails/" + options.MessageID + "/" + kind + "/" + url.PathEscape(options.ContentID)
+	resp, err := c.send(ctx, http.MethodGet, path, url.Values{}, nil, "*/*")
+	if err != nil {
+		if c.Verbose {
+			fmt.Printf("[DEBUG] Error: %s\n", err)
+		}
+		return 0, err
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	return n, errors.Join(err, resp.Body.Close())
+}
+
+type GetRawOptions struct {
+	MessageID string
+}
+
+func (o GetRawOptions) check() error {
+	if o.MessageID == "" {
+		return errors.New("invalid message id")
+	}
+
+	return nil
+}
+
+// GetRaw streams the raw RFC 5322 message to w, returning the number of bytes written
+func (c *Client) GetRaw(ctx context.Context, options GetRawOptions, w io.Writer) (int64, error) {
+	if err := options.check(); err != nil {
+		return 0, err
+	}
+
+	if c.Verbose {
+		fmt.Printf("[DEBUG] Getting raw email\n")
+	}
+
+	err := c.loadCredentials(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, "/emails/"+options.MessageID+"/raw", url.Values{}, nil, "message/rfc822, */*")
 	if err != nil {
 		if c.Verbose {
 			fmt.Printf("[DEBUG] Error: %s\n", err)
 		}
-		return "", err
+		return 0, err
 	}
 
-	return result, nil
+	n, err := io.Copy(w, resp.Body)
+	return n, errors.Join(err, resp.Body.Close())
 }
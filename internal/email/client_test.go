@@ -1,6 +1,7 @@
 package email
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -63,12 +64,28 @@ func TestGetEndpoint(t *testing.T) {
 	}
 }
 
+func TestClient_HTTPClient(t *testing.T) {
+	client := Client{}
+	assert.Equal(t, defaultHTTPClient, client.httpClient())
+	assert.Equal(t, defaultResponseHeaderTimeout, defaultHTTPClient.Transport.(*http.Transport).ResponseHeaderTimeout)
+
+	custom := &http.Client{Timeout: time.Second}
+	client.HTTPClient = custom
+	assert.Equal(t, custom, client.httpClient())
+}
+
 func TestClient_Request(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/text" {
 			_, _ = w.Write([]byte("plain text response"))
 			return
 		}
+		if r.URL.Path == "/forbidden" {
+			w.Header().Set("x-amzn-RequestId", "request-id")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"Forbidden"}`))
+			return
+		}
 
 		response := map[string]any{
 			"headers": map[string]any{
@@ -171,6 +188,24 @@ func TestClient_Request(t *testing.T) {
 			},
 			err: errors.New("error"),
 		},
+		{
+			ctx: context.Background(),
+			client: Client{
+				Endpoint: ts.URL,
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{}, nil
+				}),
+			},
+			method:    http.MethodGet,
+			path:      "/forbidden",
+			ioReadall: io.ReadAll,
+			err: &APIError{
+				StatusCode: http.StatusForbidden,
+				RequestID:  "request-id",
+				Message:    "Forbidden",
+				Body:       []byte(`{"message":"Forbidden"}`),
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -190,7 +225,7 @@ func TestClient_Request(t *testing.T) {
 
 			if test.path != "/text" {
 				var value map[string]any
-				err = json.Unmarshal([]byte(data), &value)
+				err = json.Unmarshal(data, &value)
 				assert.Nil(t, err)
 				assert.Contains(t, value["headers"], "Authorization")
 			}
@@ -238,8 +273,11 @@ func TestClient_List(t *testing.T) {
 		ioReadall = io.ReadAll
 	}()
 
+	var args map[string]any
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
-		args := map[string]any{}
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
+		args = map[string]any{}
 		for key, values := range r.URL.Query() {
 			if len(values) == 1 {
 				args[key] = values[0]
@@ -248,10 +286,16 @@ func TestClient_List(t *testing.T) {
 			}
 		}
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
-			"args": args,
+			"count": 1,
+			"items": []map[string]any{
+				{
+					"messageID":    "message-id",
+					"subject":      "subject",
+					"timeReceived": "2025-01-02T03:04:05Z",
+				},
+			},
+			"nextCursor": "cursor",
+			"hasMore":    true,
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -320,21 +364,83 @@ func TestClient_List(t *testing.T) {
 				ioReadall = io.ReadAll
 			}
 
-			resp, err := test.client.List(test.options)
+			resp, err := test.client.List(context.Background(), test.options)
 			assertErrorsEqual(t, err, test.err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
+			assert.Equal(t, test.args, args)
+			assert.Equal(t, 1, resp.Count)
+			assert.Equal(t, "cursor", resp.NextCursor)
+			assert.True(t, resp.HasMore)
+			assert.Equal(t, []Email{
+				{
+					MessageID:    "message-id",
+					Subject:      "subject",
+					TimeReceived: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			}, resp.Items)
+		})
+	}
+}
 
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+func TestClient_ListAll(t *testing.T) {
+	pages := map[string]string{
+		"":        `{"count": 2, "items": [{"messageID": "1"}, {"messageID": "2"}], "nextCursor": "cursor1"}`,
+		"cursor1": `{"count": 1, "items": [{"messageID": "3"}], "nextCursor": "cursor2"}`,
+		"cursor2": `{"count": 1, "items": [{"messageID": "4"}]}`,
+	}
+	requests := 0
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, ok := pages[r.URL.Query().Get("next_cursor")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, err := w.Write([]byte(page))
+		assert.Nil(t, err)
+	})
 
-			assert.Equal(t, test.args, values["args"])
-		})
+	client := Client{
+		Endpoint: ts.URL,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+	}
+
+	// all pages
+	var ids []string
+	for item, err := range client.ListAll(context.Background(), ListOptions{Type: EmailTypeInbox}) {
+		assert.Nil(t, err)
+		ids = append(ids, item.MessageID)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+	assert.Equal(t, 3, requests)
+
+	// stop early without fetching more pages
+	requests = 0
+	ids = nil
+	for item, err := range client.ListAll(context.Background(), ListOptions{Type: EmailTypeInbox}) {
+		assert.Nil(t, err)
+		ids = append(ids, item.MessageID)
+		if len(ids) == 2 {
+			break
+		}
 	}
+	assert.Equal(t, []string{"1", "2"}, ids)
+	assert.Equal(t, 1, requests)
+
+	// error
+	var errs []error
+	for _, err := range client.ListAll(context.Background(), ListOptions{Type: EmailTypeInbox, NextCursor: "invalid"}) {
+		errs = append(errs, err)
+	}
+	assert.Len(t, errs, 1)
+	var apiErr *APIError
+	assert.ErrorAs(t, errs[0], &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
 }
 
 func TestGetOptions_Check(t *testing.T) {
@@ -368,10 +474,10 @@ func TestClient_Get(t *testing.T) {
 	}()
 
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -429,17 +535,57 @@ func TestClient_Get(t *testing.T) {
 				ioReadall = io.ReadAll
 			}
 
-			resp, err := test.client.Get(test.options)
+			resp, err := test.client.Get(context.Background(), test.options)
 			assertErrorsEqual(t, err, test.err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
+			assert.Equal(t, "message-id", resp.MessageID)
+		})
+	}
+}
+
+func TestClient_GetThread(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/threads/thread-id", r.URL.Path)
+
+		response := map[string]any{
+			"threadID": "thread-id",
+			"emailIDs": []string{"id-1", "id-2"},
+		}
+		err := json.NewEncoder(w).Encode(response)
+		assert.Nil(t, err)
+	})
+
+	tests := []struct {
+		options GetThreadOptions
+		err     error
+	}{
+		{
+			options: GetThreadOptions{ThreadID: "thread-id"},
+		},
+		{
+			options: GetThreadOptions{},
+			err:     errors.New("invalid thread id"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			client := Client{
+				Endpoint: ts.URL,
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{}, nil
+				}),
+			}
+			resp, err := client.GetThread(context.Background(), test.options)
+			assertErrorsEqual(t, err, test.err)
+			if err != nil {
+				return
+			}
 
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+			assert.Equal(t, []string{"id-1", "id-2"}, resp.EmailIDs)
 		})
 	}
 }
@@ -471,10 +617,10 @@ func TestTrashOptions_Check(t *testing.T) {
 
 func TestClient_Trash(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -509,17 +655,13 @@ func TestClient_Trash(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			resp, err := test.client.Trash(test.options)
+			resp, err := test.client.Trash(context.Background(), test.options)
 			assert.Equal(t, test.err, err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
-
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+			assert.Equal(t, "message-id", resp.MessageID)
 		})
 	}
 }
@@ -551,10 +693,10 @@ func TestUntrashOptions_Check(t *testing.T) {
 
 func TestClient_Untrash(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -589,17 +731,167 @@ func TestClient_Untrash(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			resp, err := test.client.Untrash(test.options)
+			resp, err := test.client.Untrash(context.Background(), test.options)
 			assert.Equal(t, test.err, err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
+			assert.Equal(t, "message-id", resp.MessageID)
+		})
+	}
+}
+
+func TestReadOptions_Check(t *testing.T) {
+	tests := []struct {
+		options ReadOptions
+		err     error
+	}{
+		{
+			options: ReadOptions{},
+			err:     errors.New("invalid message id"),
+		},
+		{
+			options: ReadOptions{
+				MessageID: "message-id",
+			},
+			err: nil,
+		},
+	}
 
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := test.options.check()
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestClient_Read(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "/emails/message-id/read", r.URL.Path)
+
+		response := map[string]any{
+			"messageID": "message-id",
+		}
+		err := json.NewEncoder(w).Encode(response)
+		assert.Nil(t, err)
+	})
+
+	tests := []struct {
+		client  Client
+		options ReadOptions
+		err     error
+	}{
+		{
+			client: Client{
+				Endpoint: ts.URL,
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{}, nil
+				}),
+				Verbose: true,
+			},
+			options: ReadOptions{
+				MessageID: "message-id",
+			},
+			err: nil,
+		},
+		{
+			client: Client{
+				Verbose: true,
+			},
+			options: ReadOptions{},
+			err:     errors.New("invalid message id"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			resp, err := test.client.Read(context.Background(), test.options)
+			assert.Equal(t, test.err, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, "message-id", resp.MessageID)
+		})
+	}
+}
+
+func TestUnreadOptions_Check(t *testing.T) {
+	tests := []struct {
+		options UnreadOptions
+		err     error
+	}{
+		{
+			options: UnreadOptions{},
+			err:     errors.New("invalid message id"),
+		},
+		{
+			options: UnreadOptions{
+				MessageID: "message-id",
+			},
+			err: nil,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := test.options.check()
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestClient_Unread(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "/emails/message-id/unread", r.URL.Path)
+
+		response := map[string]any{
+			"messageID": "message-id",
+		}
+		err := json.NewEncoder(w).Encode(response)
+		assert.Nil(t, err)
+	})
+
+	tests := []struct {
+		client  Client
+		options UnreadOptions
+		err     error
+	}{
+		{
+			client: Client{
+				Endpoint: ts.URL,
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{}, nil
+				}),
+				Verbose: true,
+			},
+			options: UnreadOptions{
+				MessageID: "message-id",
+			},
+			err: nil,
+		},
+		{
+			client: Client{
+				Verbose: true,
+			},
+			options: UnreadOptions{},
+			err:     errors.New("invalid message id"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			resp, err := test.client.Unread(context.Background(), test.options)
+			assert.Equal(t, test.err, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, "message-id", resp.MessageID)
 		})
 	}
 }
@@ -631,10 +923,10 @@ func TestDeleteOptions_Check(t *testing.T) {
 
 func TestClient_Delete(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -669,17 +961,13 @@ func TestClient_Delete(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			resp, err := test.client.Delete(test.options)
+			resp, err := test.client.Delete(context.Background(), test.options)
 			assert.Equal(t, test.err, err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
-
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+			assert.Equal(t, "message-id", resp.MessageID)
 		})
 	}
 }
@@ -790,10 +1078,10 @@ func TestCreateOptions_LoadFile(t *testing.T) {
 
 func TestClient_Create(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -834,21 +1122,26 @@ func TestClient_Create(t *testing.T) {
 			},
 			err: &os.PathError{Op: "open", Path: "invalid.json", Err: syscall.ENOENT},
 		},
+		{
+			client: Client{
+				Endpoint: ts.URL,
+			},
+			options: CreateOptions{
+				To: []string{"bob@"},
+			},
+			err: &AddressError{Field: "to", Value: "bob@", Err: errors.New("missing '@' or angle-addr")},
+		},
 	}
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			resp, err := test.client.Create(test.options)
+			resp, err := test.client.Create(context.Background(), test.options)
 			assert.Equal(t, test.err, err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
-
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+			assert.Equal(t, "message-id", resp.MessageID)
 		})
 	}
 }
@@ -991,10 +1284,10 @@ func TestSaveOptions_LoadFile(t *testing.T) {
 
 func TestClient_Save(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -1040,17 +1333,13 @@ func TestClient_Save(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			resp, err := test.client.Save(test.options)
+			resp, err := test.client.Save(context.Background(), test.options)
 			assert.Equal(t, test.err, err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
-
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+			assert.Equal(t, "message-id", resp.MessageID)
 		})
 	}
 }
@@ -1082,10 +1371,10 @@ func TestSendOptions_Check(t *testing.T) {
 
 func TestClient_Send(t *testing.T) {
 	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
 		response := map[string]any{
-			"headers": map[string]any{
-				"Authorization": r.Header.Get("Authorization"),
-			},
+			"messageID": "message-id",
 		}
 		err := json.NewEncoder(w).Encode(response)
 		assert.Nil(t, err)
@@ -1120,17 +1409,149 @@ func TestClient_Send(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			resp, err := test.client.Send(test.options)
+			resp, err := test.client.Send(context.Background(), test.options)
 			assert.Equal(t, test.err, err)
 			if err != nil {
 				return
 			}
 
-			assert.NotEmpty(t, resp)
+			assert.Equal(t, "message-id", resp.MessageID)
+		})
+	}
+}
+
+func TestClient_ListAttachments(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/emails/message-id", r.URL.Path)
+		_, err := w.Write([]byte(`{
+			"messageID": "message-id",
+			"attachments": [{"contentID": "a1", "filename": "invoice.pdf", "contentType": "application/pdf"}],
+			"inlines": [{"contentID": "logo", "filename": "logo.png", "contentType": "image/png"}]
+		}`))
+		assert.Nil(t, err)
+	})
+
+	client := Client{
+		Endpoint: ts.URL,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+	}
+
+	result, err := client.ListAttachments(context.Background(), ListAttachmentsOptions{MessageID: "message-id"})
+	assert.Nil(t, err)
+	assert.Equal(t, &AttachmentsResult{
+		MessageID:   "message-id",
+		Attachments: []Attachment{{ContentID: "a1", Filename: "invoice.pdf", ContentType: "application/pdf"}},
+		Inlines:     []Attachment{{ContentID: "logo", Filename: "logo.png", ContentType: "image/png"}},
+	}, result)
+
+	_, err = client.ListAttachments(context.Background(), ListAttachmentsOptions{})
+	assert.Equal(t, errors.New("invalid message id"), err)
+}
+
+func TestClient_DownloadAttachment(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "*/*", r.Header.Get("Accept"))
+		switch r.URL.Path {
+		case "/emails/message-id/attachments/a1":
+			_, _ = w.Write([]byte("attachment content"))
+		case "/emails/message-id/inlines/logo":
+			_, _ = w.Write([]byte("inline content"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
 
-			var values map[string]any
-			err = json.Unmarshal([]byte(resp), &values)
-			assert.Nil(t, err)
+	client := Client{
+		Endpoint: ts.URL,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+		Verbose: true,
+	}
+
+	tests := []struct {
+		options DownloadAttachmentOptions
+		content string
+		err     error
+	}{
+		{
+			options: DownloadAttachmentOptions{MessageID: "message-id", ContentID: "a1"},
+			content: "attachment content",
+		},
+		{
+			options: DownloadAttachmentOptions{MessageID: "message-id", ContentID: "logo", Inline: true},
+			content: "inline content",
+		},
+		{
+			options: DownloadAttachmentOptions{MessageID: "message-id", ContentID: "missing"},
+			err:     &APIError{StatusCode: http.StatusNotFound, Body: []byte{}},
+		},
+		{
+			options: DownloadAttachmentOptions{MessageID: "message-id"},
+			err:     errors.New("invalid content id"),
+		},
+		{
+			options: DownloadAttachmentOptions{ContentID: "a1"},
+			err:     errors.New("invalid message id"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			n, err := client.DownloadAttachment(context.Background(), test.options, buf)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.content, buf.String())
+			assert.Equal(t, int64(len(test.content)), n)
+		})
+	}
+}
+
+func TestClient_GetRaw(t *testing.T) {
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/emails/message-id/raw" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("Subject: test\r\n\r\nbody"))
+	})
+
+	client := Client{
+		Endpoint: ts.URL,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+		Verbose: true,
+	}
+
+	tests := []struct {
+		options GetRawOptions
+		content string
+		err     error
+	}{
+		{
+			options: GetRawOptions{MessageID: "message-id"},
+			content: "Subject: test\r\n\r\nbody",
+		},
+		{
+			options: GetRawOptions{MessageID: "missing"},
+			err:     &APIError{StatusCode: http.StatusNotFound, Body: []byte{}},
+		},
+		{
+			options: GetRawOptions{},
+			err:     errors.New("invalid message id"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			n, err := client.GetRaw(context.Background(), test.options, buf)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.content, buf.String())
+			assert.Equal(t, int64(len(test.content)), n)
 		})
 	}
 }
@@ -1,5 +1,7 @@
 package email
 
+import "time"
+
 // The constants representing email types
 const (
 	// EmailTypeInbox represents an inbox email
@@ -12,3 +14,154 @@ const (
 	OrderAsc  = "asc"
 	OrderDesc = "desc"
 )
+
+// Email represents an email returned by the API
+type Email struct {
+	MessageID string   `json:"messageID"`
+	Type      string   `json:"type,omitempty"`
+	Subject   string   `json:"subject"`
+	From      []string `json:"from,omitempty"`
+	To        []string `json:"to,omitempty"`
+	Cc        []string `json:"cc,omitempty"`
+	Bcc       []string `json:"bcc,omitempty"`
+	ReplyTo   []string `json:"replyTo,omitempty"`
+
+	TimeReceived time.Time `json:"timeReceived,omitzero"`
+	TimeUpdated  time.Time `json:"timeUpdated,omitzero"`
+	TimeSent     time.Time `json:"timeSent,omitzero"`
+
+	Text string `json:"text,omitempty"`
+	HTML string `json:"html,omitempty"`
+
+	// inbox email attributes
+	ReturnPath string   `json:"returnPath,omitempty"`
+	Verdict    *Verdict `json:"verdict,omitempty"`
+	Unread     *bool    `json:"unread,omitempty"`
+
+	ThreadID       string `json:"threadID,omitempty"`
+	IsThreadLatest bool   `json:"isThreadLatest,omitempty"`
+	Status         string `json:"status,omitempty"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Inlines     []Attachment `json:"inlines,omitempty"`
+	OtherParts  []Attachment `json:"otherParts,omitempty"`
+}
+
+// Time returns the most relevant timestamp of the email based on its type
+func (e Email) Time() time.Time {
+	switch {
+	case !e.TimeReceived.IsZero():
+		return e.TimeReceived
+	case !e.TimeSent.IsZero():
+		return e.TimeSent
+	}
+	return e.TimeUpdated
+}
+
+// emailTableHeader is the header of the table output of emails
+var emailTableHeader = []string{"DATE", "FROM", "SUBJECT", "ID"}
+
+func (e Email) tableRow() []any {
+	return []any{e.Time(), e.From, e.Subject, e.MessageID}
+}
+
+// TableHeader and TableRows show the email as a single row of the table output.
+func (e *Email) TableHeader() []string {
+	return emailTableHeader
+}
+
+func (e *Email) TableRows() [][]any {
+	return [][]any{e.tableRow()}
+}
+
+// Verdict represents the spam and virus scanning results of an inbox email
+type Verdict struct {
+	Spam  bool `json:"spam"`
+	DKIM  bool `json:"dkim"`
+	DMARC bool `json:"dmarc"`
+	SPF   bool `json:"spf"`
+	Virus bool `json:"virus"`
+}
+
+// Attachment represents an attachment, inline or other MIME part of an email
+type Attachment struct {
+	ContentID         string            `json:"contentID,omitempty"`
+	ContentType       string            `json:"contentType,omitempty"`
+	ContentTypeParams map[string]string `json:"contentTypeParams,omitempty"`
+	Filename          string            `json:"filename,omitempty"`
+}
+
+// ListResult represents a page of emails returned by List
+type ListResult struct {
+	Count      int     `json:"count"`
+	Items      []Email `json:"items"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+	HasMore    bool    `json:"hasMore,omitempty"`
+}
+
+// ListItems returns the emails, which the ndjson output writes one per line
+func (r *ListResult) ListItems() []any {
+	items := make([]any, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = item
+	}
+	return items
+}
+
+func (r *ListResult) TableHeader() []string {
+	return emailTableHeader
+}
+
+func (r *ListResult) TableRows() [][]any {
+	rows := make([][]any, len(r.Items))
+	for i, item := range r.Items {
+		rows[i] = item.tableRow()
+	}
+	return rows
+}
+
+// Thread is a conversation as grouped by the backend
+type Thread struct {
+	ThreadID    string    `json:"threadID"`
+	Subject     string    `json:"subject,omitempty"`
+	EmailIDs    []string  `json:"emailIDs"`
+	Emails      []Email   `json:"emails,omitempty"`
+	TimeUpdated time.Time `json:"timeUpdated,omitzero"`
+}
+
+// ActionResult represents the result of an action on a single email,
+// such as trash, untrash, delete and send
+type ActionResult struct {
+	MessageID string `json:"messageID,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+func (r *ActionResult) TableHeader() []string {
+	return []string{"ID", "STATUS"}
+}
+
+func (r *ActionResult) TableRows() [][]any {
+	return [][]any{{r.MessageID, r.Status}}
+}
+
+// AttachmentsResult lists the attachments and inline files of an email
+type AttachmentsResult struct {
+	MessageID   string       `json:"messageID"`
+	Attachments []Attachment `json:"attachments"`
+	Inlines     []Attachment `json:"inlines"`
+}
+
+func (r *AttachmentsResult) TableHeader() []string {
+	return []string{"KIND", "FILENAME", "CONTENT TYPE", "CONTENT ID"}
+}
+
+func (r *AttachmentsResult) TableRows() [][]any {
+	rows := make([][]any, 0, len(r.Attachments)+len(r.Inlines))
+	for _, attachment := range r.Attachments {
+		rows = append(rows, []any{"attachment", attachment.Filename, attachment.ContentType, attachment.ContentID})
+	}
+	for _, inline := range r.Inlines {
+		rows = append(rows, []any{"inline", inline.Filename, inline.ContentType, inline.ContentID})
+	}
+	return rows
+}
@@ -0,0 +1,19 @@
+package email
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmail_Time(t *testing.T) {
+	received := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sent := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, received, Email{TimeReceived: received, TimeUpdated: updated}.Time())
+	assert.Equal(t, sent, Email{TimeSent: sent, TimeUpdated: updated}.Time())
+	assert.Equal(t, updated, Email{TimeUpdated: updated}.Time())
+	assert.True(t, Email{}.Time().IsZero())
+}
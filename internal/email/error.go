@@ -0,0 +1,72 @@
+package email
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIError is returned by Client methods when the API responds with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	RequestID  string // value of the x-amzn-RequestId header
+	Message    string // error message returned by the backend
+	Body       []byte // raw response body
+
+	RetryAfter time.Duration // value of the Retry-After header, zero if absent
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request id: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// IsAuth reports whether the error is caused by missing or invalid credentials
+func (e *APIError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether the requested resource doesn't exist
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsServer reports whether the error is caused by the server or by throttling
+func (e *APIError) IsServer() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsValidation reports whether the request was rejected as invalid
+func (e *APIError) IsValidation() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 && !e.IsAuth() && !e.IsNotFound() && !e.IsServer()
+}
+
+// newAPIError creates an APIError from the response, extracting the backend error message if present
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("x-amzn-RequestId"),
+		Body:       body,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	var data struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &data); err == nil {
+		apiErr.Message = data.Message
+		if apiErr.Message == "" {
+			apiErr.Message = data.Error
+		}
+	}
+
+	return apiErr
+}
@@ -0,0 +1,70 @@
+package email
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		header     http.Header
+		body       string
+		message    string
+		errString  string
+		class      string
+	}{
+		{
+			statusCode: http.StatusForbidden,
+			header:     http.Header{"X-Amzn-Requestid": []string{"request-id"}},
+			body:       `{"message": "Forbidden"}`,
+			message:    "Forbidden",
+			errString:  "api error: 403 Forbidden: Forbidden (request id: request-id)",
+			class:      "auth",
+		},
+		{
+			statusCode: http.StatusNotFound,
+			body:       `{"error": "email not found"}`,
+			message:    "email not found",
+			errString:  "api error: 404 Not Found: email not found",
+			class:      "not found",
+		},
+		{
+			statusCode: http.StatusBadRequest,
+			body:       "invalid input",
+			errString:  "api error: 400 Bad Request",
+			class:      "validation",
+		},
+		{
+			statusCode: http.StatusTooManyRequests,
+			errString:  "api error: 429 Too Many Requests",
+			class:      "server",
+		},
+		{
+			statusCode: http.StatusBadGateway,
+			body:       `{"message": "Internal server error"}`,
+			message:    "Internal server error",
+			errString:  "api error: 502 Bad Gateway: Internal server error",
+			class:      "server",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			resp := &http.Response{StatusCode: test.statusCode, Header: test.header}
+			err := newAPIError(resp, []byte(test.body))
+
+			assert.Equal(t, test.statusCode, err.StatusCode)
+			assert.Equal(t, test.message, err.Message)
+			assert.Equal(t, []byte(test.body), err.Body)
+			assert.Equal(t, test.errString, err.Error())
+			assert.Equal(t, test.class == "auth", err.IsAuth())
+			assert.Equal(t, test.class == "not found", err.IsNotFound())
+			assert.Equal(t, test.class == "validation", err.IsValidation())
+			assert.Equal(t, test.class == "server", err.IsServer())
+		})
+	}
+}
@@ -12,22 +12,10 @@ func addQuery(q url.Values, name string, value string) {
 	}
 }
 
-func prettyResult(result []byte) (string, error) {
-	var data map[string]any
-	err := json.Unmarshal(result, &data)
-	if err != nil {
-		return "", err
+// decodeResult decodes the JSON response into v. An empty response leaves v unchanged.
+func decodeResult(data []byte, v any) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
 	}
-
-	// cannot use json.MarshalIndent because it escapes unicode characters
-	buffer := &bytes.Buffer{}
-	encoder := json.NewEncoder(buffer)
-	encoder.SetEscapeHTML(false)
-	encoder.SetIndent("", "  ")
-	err = encoder.Encode(data)
-	if err != nil {
-		return "", err
-	}
-
-	return buffer.String(), nil
+	return json.Unmarshal(data, v)
 }
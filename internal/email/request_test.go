@@ -38,3 +38,17 @@ func TestAddQuery(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeResult(t *testing.T) {
+	var result ActionResult
+	err := decodeResult([]byte(" \n"), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionResult{}, result)
+
+	err = decodeResult([]byte(`{"messageID": "message-id", "status": "trashed"}`), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, ActionResult{MessageID: "message-id", Status: "trashed"}, result)
+
+	err = decodeResult([]byte("plain text"), &result)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,159 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// The default values of RetryPolicy
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 20 * time.Second
+)
+
+// RetryPolicy configures how failed requests are retried.
+// Only throttling (429), gateway errors (502, 503, 504), network timeouts and dropped connections are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // total number of attempts, including the first one
+	BaseDelay   time.Duration // delay before the first retry, doubled on each retry
+	MaxDelay    time.Duration // upper bound of the delay between attempts
+}
+
+// NewRetryPolicy returns a policy that retries up to the given number of times with the default delays
+func NewRetryPolicy(retries int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: retries + 1,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+// backoff returns the delay before the next attempt.
+// It uses exponential backoff with full jitter, unless the server specified a Retry-After value.
+func (p *RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+	if retryAfter > 0 {
+		return min(retryAfter, maxDelay)
+	}
+
+	baseDelay := p.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	delay := maxDelay
+	if shift := attempt - 1; shift < 32 && baseDelay<<shift < maxDelay {
+		delay = baseDelay << shift
+	}
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
+
+// isIdempotent reports whether the request can be safely repeated: GET and DELETE requests,
+// and the POST actions documented as idempotent by the backend, which are trashing, untrashing and marking as read or unread.
+// Other requests, such as creating, saving and sending emails, are never retried.
+func isIdempotent(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		for _, action := range []string{"/trash", "/untrash", "/read", "/unread"} {
+			if strings.HasSuffix(path, action) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isRetryable reports whether the error is transient
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	return isTransientNetworkError(err)
+}
+
+// isTransientNetworkError reports whether the network error may not happen again:
+// a timeout, a refused, reset or closed connection, or a temporary DNS failure.
+// Errors such as invalid certificates and malformed URLs are permanent.
+func isTransientNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	for _, transient := range []error{
+		syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE, io.EOF, io.ErrUnexpectedEOF,
+	} {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses the value of a Retry-After header, in either seconds or HTTP date format
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	}
+
+	for attempt := 1; attempt <= 40; attempt++ {
+		delay := policy.backoff(attempt, 0)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		upper := time.Second
+		if attempt <= 4 {
+			upper = 100 * time.Millisecond << (attempt - 1)
+		}
+		assert.LessOrEqual(t, delay, upper)
+	}
+
+	assert.Equal(t, 500*time.Millisecond, policy.backoff(1, 500*time.Millisecond))
+	assert.Equal(t, time.Second, policy.backoff(1, time.Minute))
+
+	empty := &RetryPolicy{}
+	assert.Equal(t, 1, empty.maxAttempts())
+	assert.LessOrEqual(t, empty.backoff(1, 0), DefaultRetryBaseDelay)
+	assert.Equal(t, 3, NewRetryPolicy(2).maxAttempts())
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		method     string
+		path       string
+		idempotent bool
+	}{
+		{method: http.MethodGet, path: "/emails", idempotent: true},
+		{method: http.MethodDelete, path: "/emails/id", idempotent: true},
+		{method: http.MethodPost, path: "/emails/id/trash", idempotent: true},
+		{method: http.MethodPost, path: "/emails/id/untrash", idempotent: true},
+		{method: http.MethodPost, path: "/emails/id/read", idempotent: true},
+		{method: http.MethodPost, path: "/emails/id/unread", idempotent: true},
+		{method: http.MethodPut, path: "/emails/id", idempotent: false},
+		{method: http.MethodHead, path: "/emails/id", idempotent: false},
+		{method: http.MethodPost, path: "/emails/id/send", idempotent: false},
+		{method: http.MethodPost, path: "/emails", idempotent: false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.idempotent, isIdempotent(test.method, test.path))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, isRetryable(ctx, &APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, isRetryable(ctx, &APIError{StatusCode: http.StatusServiceUnavailable}))
+	assert.False(t, isRetryable(ctx, &APIError{StatusCode: http.StatusInternalServerError}))
+	assert.False(t, isRetryable(ctx, &APIError{StatusCode: http.StatusNotFound}))
+	assert.False(t, isRetryable(ctx, errors.New("error")))
+
+	network := []struct {
+		err       error
+		retryable bool
+	}{
+		{err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, retryable: true},
+		{err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, retryable: true},
+		{err: &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, retryable: true},
+		{err: io.ErrUnexpectedEOF, retryable: true},
+		{err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}, retryable: true},
+		{err: &net.DNSError{Err: "no such host", IsNotFound: true}, retryable: false},
+		{err: x509.UnknownAuthorityError{}, retryable: false},
+		{err: &tls.CertificateVerificationError{Err: errors.New("expired")}, retryable: false},
+		{err: errors.New(`unsupported protocol scheme ""`), retryable: false},
+		{err: context.Canceled, retryable: false},
+	}
+	for i, test := range network {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.retryable, isRetryable(ctx, &url.Error{Op: "Get", URL: "https://example.com", Err: test.err}))
+		})
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, isRetryable(canceled, &url.Error{Op: "Get", Err: context.Canceled}))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-3", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter("Wed, 01 Jan 2025 00:00:10 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("invalid", now))
+}
+
+func TestSleep(t *testing.T) {
+	err := sleep(context.Background(), time.Millisecond)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = sleep(ctx, time.Hour)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestClient_Retry(t *testing.T) {
+	attempts := map[string]int{}
+	ts := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts[r.URL.Path]++
+		if attempts[r.URL.Path] < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`{"messageID": "message-id"}`))
+		assert.Nil(t, err)
+	})
+
+	client := Client{
+		Endpoint: ts.URL,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		}),
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    time.Millisecond,
+		},
+		Verbose: true,
+	}
+
+	// idempotent requests are retried until they succeed
+	data, err := client.request(context.Background(), http.MethodGet, "/emails/message-id", url.Values{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"messageID": "message-id"}`, string(data))
+	assert.Equal(t, 3, attempts["/emails/message-id"])
+
+	// non-idempotent requests are attempted once
+	_, err = client.request(context.Background(), http.MethodPost, "/emails/message-id/send", url.Values{}, nil)
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, 1, attempts["/emails/message-id/send"])
+
+	// unless the backend documents the action as idempotent
+	_, err = client.request(context.Background(), http.MethodPost, "/emails/message-id/unread", url.Values{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts["/emails/message-id/unread"])
+
+	// attempts are bounded
+	client.RetryPolicy.MaxAttempts = 2
+	_, err = client.request(context.Background(), http.MethodGet, "/emails/other", url.Values{}, nil)
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 2, attempts["/emails/other"])
+}
@@ -0,0 +1,108 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Options configures the rendering of Markdown
+type Options struct {
+	// InlineCSS adds style attributes to the HTML elements,
+	// since many email clients ignore <style> elements
+	InlineCSS bool
+}
+
+// md renders GitHub Flavored Markdown. Raw HTML is omitted and dangerous URLs are dropped,
+// so the output is safe to send regardless of the input.
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Render converts the Markdown source to sanitized HTML and a plain-text alternative
+func Render(source []byte, options Options) (htmlBody, textBody string, err error) {
+	buf := &bytes.Buffer{}
+	doc := md.Parser().Parse(text.NewReader(source))
+	if err := md.Renderer().Render(buf, source, doc); err != nil {
+		return "", "", err
+	}
+
+	htmlBody = buf.String()
+	if options.InlineCSS {
+		htmlBody, err = inlineCSS(htmlBody)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return htmlBody, renderText(source, doc), nil
+}
+
+// styles is applied to the elements when inlining CSS, resembling the rendering on GitHub
+var styles = map[string]string{
+	"h1":         "font-size:2em;font-weight:600;margin:24px 0 16px;",
+	"h2":         "font-size:1.5em;font-weight:600;margin:24px 0 16px;",
+	"h3":         "font-size:1.25em;font-weight:600;margin:24px 0 16px;",
+	"h4":         "font-size:1em;font-weight:600;margin:24px 0 16px;",
+	"h5":         "font-size:0.875em;font-weight:600;margin:24px 0 16px;",
+	"h6":         "font-size:0.85em;font-weight:600;margin:24px 0 16px;color:#57606a;",
+	"p":          "margin:0 0 16px;line-height:1.5;",
+	"a":          "color:#0969da;",
+	"ul":         "margin:0 0 16px;padding-left:2em;",
+	"ol":         "margin:0 0 16px;padding-left:2em;",
+	"blockquote": "margin:0 0 16px;padding:0 1em;color:#57606a;border-left:4px solid #d0d7de;",
+	"code":       "font-family:monospace;font-size:85%;padding:0.2em 0.4em;background-color:#f6f8fa;border-radius:6px;",
+	"pre":        "font-family:monospace;font-size:85%;padding:16px;overflow:auto;background-color:#f6f8fa;border-radius:6px;",
+	"table":      "border-collapse:collapse;margin:0 0 16px;",
+	"th":         "padding:6px 13px;border:1px solid #d0d7de;font-weight:600;background-color:#f6f8fa;",
+	"td":         "padding:6px 13px;border:1px solid #d0d7de;",
+	"hr":         "height:0;margin:24px 0;border:0;border-top:1px solid #d0d7de;",
+	"img":        "max-width:100%;",
+}
+
+// inlineCSS adds the styles to the elements of the HTML fragment, keeping their existing styles
+func inlineCSS(fragment string) (string, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &strings.Builder{}
+	for _, node := range nodes {
+		addStyles(node)
+		if err := html.Render(buf, node); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+func addStyles(node *html.Node) {
+	if node.Type == html.ElementNode {
+		style := styles[node.Data]
+		if node.Data == "code" && node.Parent != nil && node.Parent.Data == "pre" {
+			// the pre element already provides the background and padding
+			style = "font-family:monospace;"
+		}
+		if style != "" {
+			setStyle(node, style)
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		addStyles(child)
+	}
+}
+
+func setStyle(node *html.Node, style string) {
+	for i, attr := range node.Attr {
+		if attr.Key == "style" {
+			// existing styles, such as the alignment of table cells, take precedence
+			node.Attr[i].Val = style + attr.Val
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "style", Val: style})
+}
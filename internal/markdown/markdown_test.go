@@ -0,0 +1,63 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		source string
+		html   string
+		text   string
+	}{
+		{
+			source: "# Notice\n\nHello **world**, see [docs](https://example.com) and <https://example.org>.\n",
+			html:   "<h1>Notice</h1>\n<p>Hello <strong>world</strong>, see <a href=\"https://example.com\">docs</a> and <a href=\"https://example.org\">https://example.org</a>.</p>\n",
+			text:   "Notice\n======\n\nHello world, see docs (https://example.com) and https://example.org.\n",
+		},
+		{
+			source: "## Steps\n\n1. one\n2. two\n   - nested\n- [x] done\n- [ ] todo\n",
+			text:   "Steps\n-----\n\n1. one\n2. two\n   - nested\n\n- [x] done\n- [ ] todo\n",
+		},
+		{
+			source: "> quoted\n> text\n\n```go\nfmt.Println(1)\n\nreturn\n```\n\n---\n",
+			text:   "> quoted\n> text\n\n    fmt.Println(1)\n\n    return\n\n--------------------\n",
+		},
+		{
+			source: "| Name | Qty |\n|:-----|----:|\n| Apple | 1 |\n| Kiwi | 12 |\n",
+			text:   "Name  | Qty\n------|----\nApple | 1\nKiwi  | 12\n",
+		},
+		{
+			// raw HTML and dangerous URLs are dropped
+			source: "<script>alert(1)</script>\n\n[click](javascript:alert(1)) \\*not emphasis\\* &amp; <b>bold</b>\n",
+			html:   "<!-- raw HTML omitted -->\n<p><a href=\"\">click</a> *not emphasis* &amp; <!-- raw HTML omitted -->bold<!-- raw HTML omitted --></p>\n",
+			text:   "click *not emphasis* & bold\n",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			html, text, err := Render([]byte(test.source), Options{})
+			assert.Nil(t, err)
+			if test.html != "" {
+				assert.Equal(t, test.html, html)
+			}
+			assert.Equal(t, test.text, text)
+		})
+	}
+}
+
+func TestRender_InlineCSS(t *testing.T) {
+	html, _, err := Render([]byte("Text with `code`\n\n```\nblock\n```\n\n| A |\n|--:|\n| 1 |\n"), Options{InlineCSS: true})
+	assert.Nil(t, err)
+	assert.Contains(t, html, `<p style="`+styles["p"]+`">`)
+	assert.Contains(t, html, `<code style="`+styles["code"]+`">code</code>`)
+	assert.Contains(t, html, `<pre style="`+styles["pre"]+`"><code style="font-family:monospace;">block`)
+	// existing styles are kept after the inlined ones
+	assert.Contains(t, html, `<td style="`+styles["td"]+`text-align:right">1</td>`)
+	assert.False(t, strings.Contains(html, "<html>"))
+}
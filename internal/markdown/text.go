@@ -0,0 +1,197 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// renderText renders the document as plain text that reads like the HTML version:
+// headings are underlined, links are followed by their URL, and code blocks are indented.
+func renderText(source []byte, doc ast.Node) string {
+	r := &textRenderer{source: source}
+	return r.blocks(doc, "\n\n") + "\n"
+}
+
+type textRenderer struct {
+	source []byte
+}
+
+// blocks renders the block children of the node, separated by sep
+func (r *textRenderer) blocks(parent ast.Node, sep string) string {
+	var parts []string
+	for node := parent.FirstChild(); node != nil; node = node.NextSibling() {
+		if part := r.block(node); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (r *textRenderer) block(node ast.Node) string {
+	switch node := node.(type) {
+	case *ast.Heading:
+		heading := r.inline(node)
+		switch node.Level {
+		case 1:
+			return heading + "\n" + strings.Repeat("=", utf8.RuneCountInString(heading))
+		case 2:
+			return heading + "\n" + strings.Repeat("-", utf8.RuneCountInString(heading))
+		}
+		return heading
+	case *ast.Paragraph, *ast.TextBlock:
+		return r.inline(node)
+	case *ast.Blockquote:
+		return prefixLines(r.blocks(node, "\n\n"), "> ", "> ")
+	case *ast.List:
+		return r.list(node)
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		return prefixLines(strings.TrimRight(r.lines(node), "\n"), "    ", "    ")
+	case *ast.ThematicBreak:
+		return strings.Repeat("-", 20)
+	case *extast.Table:
+		return r.table(node)
+	case *ast.HTMLBlock:
+		// omitted like in the HTML version
+		return ""
+	}
+	return r.blocks(node, "\n\n")
+}
+
+func (r *textRenderer) list(list *ast.List) string {
+	sep := "\n\n"
+	if list.IsTight {
+		sep = "\n"
+	}
+
+	var items []string
+	number := list.Start
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := "- "
+		if list.IsOrdered() {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		content := r.blocks(item, sep)
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, sep)
+}
+
+func (r *textRenderer) table(table *extast.Table) string {
+	var rows [][]string
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, r.inline(cell))
+		}
+		rows = append(rows, cells)
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = cell + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, " | "), " "))
+
+		if i == 0 {
+			// separate the header row
+			separators := make([]string, len(widths))
+			for j, width := range widths {
+				separators[j] = strings.Repeat("-", width)
+			}
+			lines = append(lines, strings.Join(separators, "-|-"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lines returns the raw content of a code block
+func (r *textRenderer) lines(node ast.Node) string {
+	buf := &strings.Builder{}
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(r.source))
+	}
+	return buf.String()
+}
+
+// inline renders the inline children of the node
+func (r *textRenderer) inline(parent ast.Node) string {
+	buf := &strings.Builder{}
+	for node := parent.FirstChild(); node != nil; node = node.NextSibling() {
+		switch node := node.(type) {
+		case *ast.Text:
+			value := node.Value(r.source)
+			if !node.IsRaw() {
+				value = util.UnescapePunctuations(util.ResolveNumericReferences(util.ResolveEntityNames(value)))
+			}
+			buf.Write(value)
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				buf.WriteString("\n")
+			}
+		case *ast.String:
+			buf.Write(node.Value)
+		case *ast.Link:
+			buf.WriteString(withURL(r.inline(node), string(node.Destination)))
+		case *ast.Image:
+			buf.WriteString(withURL(r.inline(node), string(node.Destination)))
+		case *ast.AutoLink:
+			buf.Write(node.Label(r.source))
+		case *ast.RawHTML:
+			// omitted like in the HTML version
+		case *extast.TaskCheckBox:
+			if node.IsChecked {
+				buf.WriteString("[x] ")
+			} else {
+				buf.WriteString("[ ] ")
+			}
+		default:
+			buf.WriteString(r.inline(node))
+		}
+	}
+	return buf.String()
+}
+
+// withURL appends the URL to the label of a link, unless they are the same.
+// Dangerous URLs are dropped like in the HTML version.
+func withURL(label, url string) string {
+	if url == "" || label == url || goldmarkhtml.IsDangerousURL([]byte(url)) {
+		return label
+	}
+	if label == "" {
+		return url
+	}
+	return label + " (" + url + ")"
+}
+
+// prefixLines prefixes the first line with first and the other lines with rest
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
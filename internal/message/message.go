@@ -0,0 +1,149 @@
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+)
+
+// Domain is used to generate Message-ID headers for emails without one
+const Domain = "mailbox-cli"
+
+// MessageID returns the Message-ID header value of the email
+func MessageID(messageID string) string {
+	return "<" + messageID + "@" + Domain + ">"
+}
+
+// Build composes an RFC 5322 message from the email.
+// The body is multipart/alternative if both text and HTML are present.
+func Build(e email.Email) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	header := textproto.MIMEHeader{}
+	setAddressHeader(header, "From", e.From)
+	setAddressHeader(header, "To", e.To)
+	setAddressHeader(header, "Cc", e.Cc)
+	setAddressHeader(header, "Reply-To", e.ReplyTo)
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", e.Subject))
+	if t := e.Time(); !t.IsZero() {
+		header.Set("Date", t.Format(time.RFC1123Z))
+	}
+	if e.MessageID != "" {
+		header.Set("Message-ID", MessageID(e.MessageID))
+	}
+	header.Set("MIME-Version", "1.0")
+
+	switch {
+	case e.Text != "" && e.HTML != "":
+		writer := multipart.NewWriter(buf)
+		header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+		writeHeader(buf, header)
+		if err := writePart(writer, "text/plain; charset=utf-8", e.Text); err != nil {
+			return nil, err
+		}
+		if err := writePart(writer, "text/html; charset=utf-8", e.HTML); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+	case e.HTML != "":
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(buf, header)
+		if err := writeQuotedPrintable(buf, e.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(buf, header)
+		if err := writeQuotedPrintable(buf, e.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FormatAddresses formats the addresses for a header, encoding non-ASCII display names
+func FormatAddresses(addresses []string) string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if parsed, err := mail.ParseAddress(address); err == nil {
+			formatted = append(formatted, parsed.String())
+		} else {
+			formatted = append(formatted, address)
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func setAddressHeader(header textproto.MIMEHeader, key string, addresses []string) {
+	if len(addresses) > 0 {
+		header.Set(key, FormatAddresses(addresses))
+	}
+}
+
+// headerOrder keeps the generated headers in a conventional order
+var headerOrder = []string{
+	"Date", "From", "To", "Cc", "Reply-To", "Subject", "Message-Id", "In-Reply-To", "References",
+	"Mime-Version", "Content-Type", "Content-Transfer-Encoding",
+}
+
+func writeHeader(w io.Writer, header textproto.MIMEHeader) {
+	written := map[string]bool{}
+	for _, key := range headerOrder {
+		for _, value := range header.Values(key) {
+			fmt.Fprintf(w, "%s: %s\r\n", displayKey(key), value)
+		}
+		written[key] = true
+	}
+	for key, values := range header {
+		if written[key] {
+			continue
+		}
+		for _, value := range values {
+			fmt.Fprintf(w, "%s: %s\r\n", key, value)
+		}
+	}
+	fmt.Fprint(w, "\r\n")
+}
+
+func displayKey(key string) string {
+	switch key {
+	case "Message-Id":
+		return "Message-ID"
+	case "Mime-Version":
+		return "MIME-Version"
+	}
+	return key
+}
+
+func writePart(writer *multipart.Writer, contentType, content string) error {
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	return writeQuotedPrintable(part, content)
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,58 @@
+package message
+
+import (
+	"io"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	e := email.Email{
+		MessageID:    "message-id",
+		Subject:      "Grüße",
+		From:         []string{"Jörg <joerg@example.com>"},
+		To:           []string{"to@example.com"},
+		Cc:           []string{"invalid address"},
+		TimeReceived: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Text:         "Hello",
+		HTML:         "<p>Hello</p>",
+	}
+
+	raw, err := Build(e)
+	assert.Nil(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	assert.Nil(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Grüße", subject)
+	assert.Equal(t, "<message-id@mailbox-cli>", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 +0000", msg.Header.Get("Date"))
+	assert.Equal(t, "invalid address", msg.Header.Get("Cc"))
+	from, err := msg.Header.AddressList("From")
+	assert.Nil(t, err)
+	assert.Equal(t, []*mail.Address{{Name: "Jörg", Address: "joerg@example.com"}}, from)
+	assert.True(t, strings.HasPrefix(msg.Header.Get("Content-Type"), "multipart/alternative; boundary="))
+	assert.True(t, strings.HasPrefix(string(raw), "Date: "))
+
+	body, err := io.ReadAll(msg.Body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, string(body), "<p>Hello</p>")
+
+	raw, err = Build(email.Email{Subject: "HTML", HTML: "<p>only</p>"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(raw), "Content-Type: text/html; charset=utf-8\r\n")
+	assert.NotContains(t, string(raw), "Message-ID")
+
+	raw, err = Build(email.Email{Subject: "Text", Text: "only"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(raw), "Content-Type: text/plain; charset=utf-8\r\n")
+	assert.True(t, strings.HasSuffix(string(raw), "\r\n\r\nonly"))
+}
@@ -0,0 +1,224 @@
+package message
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+var ErrInvalidMessage = errors.New("invalid message")
+
+// wordDecoder decodes RFC 2047 encoded words in any charset known to the WHATWG encoding standard
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// Parse reads an RFC 5322 message and converts it into the options to create a draft.
+// The first text/plain and text/html parts become the body. Other parts become attachments,
+// or inline attachments if they have a Content-ID and aren't marked as attachment.
+func Parse(r io.Reader) (email.CreateOptions, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return email.CreateOptions{}, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+
+	options := email.CreateOptions{}
+	options.Subject, err = decodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return email.CreateOptions{}, err
+	}
+	for _, field := range []struct {
+		key  string
+		dest *[]string
+	}{
+		{"From", &options.From},
+		{"To", &options.To},
+		{"Cc", &options.Cc},
+		{"Bcc", &options.Bcc},
+		{"Reply-To", &options.ReplyTo},
+	} {
+		*field.dest, err = ParseAddressList(msg.Header.Get(field.key))
+		if err != nil {
+			return email.CreateOptions{}, fmt.Errorf("%w: %s: %w", ErrInvalidMessage, field.key, err)
+		}
+	}
+
+	header := partHeader{
+		contentType: msg.Header.Get("Content-Type"),
+		encoding:    msg.Header.Get("Content-Transfer-Encoding"),
+		disposition: msg.Header.Get("Content-Disposition"),
+		contentID:   msg.Header.Get("Content-ID"),
+	}
+	if err := parsePart(&options, header, msg.Body); err != nil {
+		return email.CreateOptions{}, err
+	}
+	return options, nil
+}
+
+type partHeader struct {
+	contentType string
+	encoding    string
+	disposition string
+	contentID   string
+}
+
+func parsePart(options *email.CreateOptions, header partHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.contentType)
+	if err != nil {
+		// RFC 2045: a missing or invalid Content-Type defaults to plain text
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+			}
+			err = parsePart(options, partHeader{
+				contentType: part.Header.Get("Content-Type"),
+				encoding:    part.Header.Get("Content-Transfer-Encoding"),
+				disposition: part.Header.Get("Content-Disposition"),
+				contentID:   part.Header.Get("Content-ID"),
+			}, part)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(header.encoding, body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.disposition)
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename, _ = wordDecoder.DecodeHeader(filename)
+
+	isBody := disposition != "attachment" && filename == ""
+	switch {
+	case isBody && mediaType == "text/plain" && options.Text == "":
+		options.Text, err = decodeCharset(params["charset"], content)
+		return err
+	case isBody && mediaType == "text/html" && options.HTML == "":
+		options.HTML, err = decodeCharset(params["charset"], content)
+		return err
+	}
+
+	attachment := email.FileAttachment{
+		Filename:    filename,
+		ContentType: mediaType,
+		Content:     content,
+	}
+	contentID := strings.Trim(strings.TrimSpace(header.contentID), "<>")
+	if contentID != "" && disposition != "attachment" {
+		attachment.ContentID = contentID
+		if attachment.Filename == "" {
+			attachment.Filename = contentID
+		}
+		options.Inlines = append(options.Inlines, attachment)
+		return nil
+	}
+	if attachment.Filename == "" {
+		attachment.Filename = fmt.Sprintf("attachment-%d", len(options.Attachments)+1)
+	}
+	options.Attachments = append(options.Attachments, attachment)
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &whitespaceStripper{r: r})
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// whitespaceStripper removes line breaks and spaces, which base64.NewDecoder doesn't skip
+type whitespaceStripper struct {
+	r io.Reader
+}
+
+func (s *whitespaceStripper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+func decodeCharset(charset string, content []byte) (string, error) {
+	reader, err := charsetReader(charset, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+func charsetReader(charset string, r io.Reader) (io.Reader, error) {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" || charset == "utf-8" || charset == "us-ascii" {
+		return r, nil
+	}
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported charset %q", ErrInvalidMessage, charset)
+	}
+	return encoding.NewDecoder().Reader(r), nil
+}
+
+func decodeHeader(value string) (string, error) {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+	return decoded, nil
+}
+
+// ParseAddressList parses an address list header into addresses in the form of "Name <address>"
+func ParseAddressList(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	list, err := parser.ParseList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(list))
+	for _, address := range list {
+		addresses = append(addresses, email.FormatAddress(address))
+	}
+	return addresses, nil
+}
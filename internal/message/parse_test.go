@@ -0,0 +1,108 @@
+package message
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected email.CreateOptions
+		err      error
+	}{
+		{
+			raw: "From: =?utf-8?q?J=C3=B6rg?= <joerg@example.com>\r\n" +
+				"To: a@example.com, \"Doe, John\" <john@example.com>\r\n" +
+				"Subject: =?iso-8859-1?q?Gr=FC=DFe?=\r\n" +
+				"\r\n" +
+				"Hello\r\n",
+			expected: email.CreateOptions{
+				Subject: "Grüße",
+				From:    []string{"Jörg <joerg@example.com>"},
+				To:      []string{"a@example.com", `"Doe, John" <john@example.com>`},
+				Text:    "Hello\r\n",
+			},
+		},
+		{
+			raw: "Subject: multipart\r\n" +
+				"Content-Type: multipart/mixed; boundary=outer\r\n" +
+				"\r\n" +
+				"--outer\r\n" +
+				"Content-Type: multipart/related; boundary=inner\r\n" +
+				"\r\n" +
+				"--inner\r\n" +
+				"Content-Type: multipart/alternative; boundary=alt\r\n" +
+				"\r\n" +
+				"--alt\r\n" +
+				"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+				"Content-Transfer-Encoding: quoted-printable\r\n" +
+				"\r\n" +
+				"Gr=FC=DFe\r\n" +
+				"--alt\r\n" +
+				"Content-Type: text/html; charset=utf-8\r\n" +
+				"Content-Transfer-Encoding: base64\r\n" +
+				"\r\n" +
+				"PHA+SGVs\r\n" +
+				"bG88L3A+\r\n" +
+				"--alt--\r\n" +
+				"--inner\r\n" +
+				"Content-Type: image/png\r\n" +
+				"Content-ID: <logo>\r\n" +
+				"Content-Transfer-Encoding: base64\r\n" +
+				"\r\n" +
+				"cG5n\r\n" +
+				"--inner--\r\n" +
+				"--outer\r\n" +
+				"Content-Type: text/plain; name=\"notes.txt\"\r\n" +
+				"Content-Disposition: attachment; filename=\"=?utf-8?q?n=C3=B6tes.txt?=\"\r\n" +
+				"\r\n" +
+				"notes\r\n" +
+				"--outer\r\n" +
+				"Content-Type: application/octet-stream\r\n" +
+				"\r\n" +
+				"data\r\n" +
+				"--outer--\r\n",
+			expected: email.CreateOptions{
+				Subject: "multipart",
+				Text:    "Grüße",
+				HTML:    "<p>Hello</p>",
+				Attachments: []email.FileAttachment{
+					{Filename: "nötes.txt", ContentType: "text/plain", Content: []byte("notes")},
+					{Filename: "attachment-2", ContentType: "application/octet-stream", Content: []byte("data")},
+				},
+				Inlines: []email.FileAttachment{
+					{Filename: "logo", ContentType: "image/png", ContentID: "logo", Content: []byte("png")},
+				},
+			},
+		},
+		{
+			raw: "invalid",
+			err: ErrInvalidMessage,
+		},
+		{
+			raw: "To: a@\r\n\r\n",
+			err: ErrInvalidMessage,
+		},
+		{
+			raw: "Content-Type: text/plain; charset=unknown\r\n\r\nbody",
+			err: ErrInvalidMessage,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			options, err := Parse(strings.NewReader(test.raw))
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, options)
+		})
+	}
+}
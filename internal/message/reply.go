@@ -0,0 +1,216 @@
+package message
+
+import (
+	"fmt"
+	"html"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+)
+
+// ReplyOptions contains the content written by the user when replying
+type ReplyOptions struct {
+	All  bool     // reply to all recipients
+	From []string // defaults to the address the original email was sent to
+	Text string
+	HTML string
+
+	// the threading headers of the original email, read from its raw message,
+	// which link the reply to the conversation
+	Thread ThreadHeaders
+}
+
+// Reply builds a draft replying to the original email.
+// The subject is prefixed with "Re:", and the original body is quoted below the reply.
+func Reply(original email.Email, options ReplyOptions) email.CreateOptions {
+	reply := email.CreateOptions{
+		Subject:    prefixSubject("Re:", original.Subject),
+		From:       options.From,
+		InReplyTo:  options.Thread.MessageID,
+		References: threadReferences(options.Thread),
+	}
+	if len(reply.From) == 0 {
+		reply.From = ownAddress(original)
+	}
+
+	if original.Type == email.EmailTypeSent {
+		// following up on an email sent by ourselves goes to the same recipients
+		reply.To = original.To
+	} else {
+		reply.To = original.ReplyTo
+		if len(reply.To) == 0 {
+			reply.To = original.From
+		}
+	}
+	if options.All {
+		exclude := append(append([]string{}, reply.From...), reply.To...)
+		reply.Cc = excludeAddresses(append(append([]string{}, original.To...), original.Cc...), exclude)
+	}
+
+	header := fmt.Sprintf("On %s, %s wrote:", formatDate(original.Time()), strings.Join(original.From, ", "))
+	reply.Text = options.Text + "\n\n" + header + "\n" + quoteText(original.Text)
+	reply.HTML = userHTML(options) +
+		"<br><br><div>" + html.EscapeString(header) + "</div>" +
+		`<blockquote style="margin:0 0 0 .8ex;border-left:1px solid #ccc;padding-left:1ex">` +
+		originalHTML(original) + "</blockquote>"
+	return reply
+}
+
+// ForwardOptions contains the recipients and content written by the user when forwarding
+type ForwardOptions struct {
+	From []string // defaults to the address the original email was sent to
+	To   []string
+	Cc   []string
+	Bcc  []string
+	Text string
+	HTML string
+
+	// the threading headers of the original email, read from its raw message
+	Thread ThreadHeaders
+}
+
+// Forward builds a draft forwarding the original email.
+// The subject is prefixed with "Fwd:", and the original headers and body are included below the message.
+// The attachments of the original email are left to the caller, which downloads their content to attach it.
+func Forward(original email.Email, options ForwardOptions) email.CreateOptions {
+	forward := email.CreateOptions{
+		Subject:    prefixSubject("Fwd:", original.Subject),
+		From:       options.From,
+		To:         options.To,
+		Cc:         options.Cc,
+		Bcc:        options.Bcc,
+		References: threadReferences(options.Thread),
+	}
+	if len(forward.From) == 0 {
+		forward.From = ownAddress(original)
+	}
+
+	fields := [][2]string{
+		{"From", strings.Join(original.From, ", ")},
+		{"Date", formatDate(original.Time())},
+		{"Subject", original.Subject},
+		{"To", strings.Join(original.To, ", ")},
+	}
+	if len(original.Cc) > 0 {
+		fields = append(fields, [2]string{"Cc", strings.Join(original.Cc, ", ")})
+	}
+
+	text := &strings.Builder{}
+	htmlBuilder := &strings.Builder{}
+	text.WriteString("---------- Forwarded message ----------\n")
+	htmlBuilder.WriteString("<div>---------- Forwarded message ----------<br>")
+	for _, field := range fields {
+		fmt.Fprintf(text, "%s: %s\n", field[0], field[1])
+		fmt.Fprintf(htmlBuilder, "%s: %s<br>", field[0], html.EscapeString(field[1]))
+	}
+	htmlBuilder.WriteString("</div><br>")
+
+	forward.Text = options.Text + "\n\n" + text.String() + "\n" + original.Text
+	forward.HTML = userHTML(ReplyOptions{Text: options.Text, HTML: options.HTML}) +
+		"<br><br>" + htmlBuilder.String() + originalHTML(original)
+	return forward
+}
+
+// ownAddress returns the address of the user in the original email:
+// the sender of a sent email, or else the first address it was sent to
+func ownAddress(original email.Email) []string {
+	if original.Type == email.EmailTypeSent {
+		return original.From
+	}
+	if len(original.To) > 0 {
+		return original.To[:1]
+	}
+	return nil
+}
+
+// threadReferences returns the References of a message following the original in its conversation,
+// which are the original's References, or else its In-Reply-To, followed by its Message-ID.
+// It's empty if the Message-ID of the original is unknown.
+func threadReferences(original ThreadHeaders) []string {
+	if original.MessageID == "" {
+		return nil
+	}
+	references := slices.Clone(original.References)
+	if len(references) == 0 {
+		references = slices.Clone(original.InReplyTo)
+	}
+	return append(references, original.MessageID)
+}
+
+// prefixSubject adds the prefix unless the subject already starts with it
+func prefixSubject(prefix, subject string) string {
+	if len(subject) >= len(prefix) && strings.EqualFold(subject[:len(prefix)], prefix) {
+		return subject
+	}
+	return prefix + " " + subject
+}
+
+func quoteText(text string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ">") {
+			lines[i] = ">" + line
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// userHTML returns the HTML written by the user, converting the text if no HTML is given
+func userHTML(options ReplyOptions) string {
+	if options.HTML != "" {
+		return options.HTML
+	}
+	return textToHTML(options.Text)
+}
+
+// originalHTML returns the HTML body of the email, converting the text if it has no HTML
+func originalHTML(e email.Email) string {
+	if e.HTML != "" {
+		return e.HTML
+	}
+	return textToHTML(e.Text)
+}
+
+func textToHTML(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "an unknown date"
+	}
+	return t.Format("Mon, Jan 2, 2006 at 3:04 PM")
+}
+
+// excludeAddresses removes the excluded and duplicate addresses, comparing by email address
+func excludeAddresses(addresses, exclude []string) []string {
+	seen := map[string]bool{}
+	for _, address := range exclude {
+		seen[addressKey(address)] = true
+	}
+
+	var result []string
+	for _, address := range addresses {
+		key := addressKey(address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, address)
+	}
+	return result
+}
+
+func addressKey(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return strings.ToLower(parsed.Address)
+	}
+	return strings.ToLower(strings.TrimSpace(address))
+}
@@ -0,0 +1,117 @@
+package message
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReply(t *testing.T) {
+	original := email.Email{
+		MessageID:    "message-id",
+		Type:         email.EmailTypeInbox,
+		Subject:      "Hello",
+		From:         []string{"Alice <alice@example.com>"},
+		To:           []string{"me@example.com", "Bob <bob@example.com>"},
+		Cc:           []string{"carol@example.com", "ALICE@example.com"},
+		TimeReceived: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+		Text:         "Hi\n> earlier\n",
+	}
+
+	thread := ThreadHeaders{MessageID: "c@example.com", InReplyTo: []string{"b@example.com"}, References: []string{"a@example.com", "b@example.com"}}
+	reply := Reply(original, ReplyOptions{Text: "Thanks", Thread: thread})
+	assert.Equal(t, "Re: Hello", reply.Subject)
+	assert.Equal(t, []string{"me@example.com"}, reply.From)
+	assert.Equal(t, []string{"Alice <alice@example.com>"}, reply.To)
+	assert.Empty(t, reply.Cc)
+	assert.Equal(t, "c@example.com", reply.InReplyTo)
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, reply.References)
+	assert.Equal(t, "Thanks\n\nOn Thu, Jan 2, 2025 at 3:04 PM, Alice <alice@example.com> wrote:\n> Hi\n>> earlier\n", reply.Text)
+	assert.Equal(t, "Thanks<br><br><div>On Thu, Jan 2, 2025 at 3:04 PM, Alice &lt;alice@example.com&gt; wrote:</div>"+
+		`<blockquote style="margin:0 0 0 .8ex;border-left:1px solid #ccc;padding-left:1ex">Hi<br>&gt; earlier<br></blockquote>`, reply.HTML)
+
+	reply = Reply(original, ReplyOptions{All: true})
+	assert.Equal(t, []string{"Bob <bob@example.com>", "carol@example.com"}, reply.Cc)
+	// without the Message-ID of the original, the reply can't be threaded
+	assert.Empty(t, reply.InReplyTo)
+	assert.Empty(t, reply.References)
+
+	original.ReplyTo = []string{"list@example.com"}
+	original.Subject = "RE: Hello"
+	reply = Reply(original, ReplyOptions{From: []string{"other@example.com"}})
+	assert.Equal(t, "RE: Hello", reply.Subject)
+	assert.Equal(t, []string{"other@example.com"}, reply.From)
+	assert.Equal(t, []string{"list@example.com"}, reply.To)
+
+	sent := email.Email{
+		Type: email.EmailTypeSent,
+		From: []string{"me@example.com"},
+		To:   []string{"bob@example.com"},
+		HTML: "<p>Sent</p>",
+	}
+	reply = Reply(sent, ReplyOptions{HTML: "<p>Follow up</p>"})
+	assert.Equal(t, []string{"me@example.com"}, reply.From)
+	assert.Equal(t, []string{"bob@example.com"}, reply.To)
+	assert.Contains(t, reply.HTML, "<p>Follow up</p><br><br><div>On an unknown date, me@example.com wrote:</div>")
+	assert.Contains(t, reply.HTML, "<p>Sent</p></blockquote>")
+}
+
+func TestForward(t *testing.T) {
+	original := email.Email{
+		MessageID:    "message-id",
+		Subject:      "Report",
+		From:         []string{"Alice <alice@example.com>"},
+		To:           []string{"me@example.com"},
+		Cc:           []string{"bob@example.com"},
+		TimeReceived: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+		Text:         "See attached",
+		HTML:         "<p>See attached</p>",
+	}
+
+	thread := ThreadHeaders{MessageID: "b@example.com", InReplyTo: []string{"a@example.com"}}
+	forward := Forward(original, ForwardOptions{To: []string{"carol@example.com"}, Text: "FYI", Thread: thread})
+	assert.Equal(t, "Fwd: Report", forward.Subject)
+	assert.Equal(t, []string{"me@example.com"}, forward.From)
+	assert.Equal(t, []string{"carol@example.com"}, forward.To)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, forward.References)
+	assert.Empty(t, forward.InReplyTo)
+	assert.Equal(t, "FYI\n\n---------- Forwarded message ----------\n"+
+		"From: Alice <alice@example.com>\nDate: Thu, Jan 2, 2025 at 3:04 PM\nSubject: Report\nTo: me@example.com\nCc: bob@example.com\n\n"+
+		"See attached", forward.Text)
+	assert.Equal(t, "FYI<br><br><div>---------- Forwarded message ----------<br>"+
+		"From: Alice &lt;alice@example.com&gt;<br>Date: Thu, Jan 2, 2025 at 3:04 PM<br>Subject: Report<br>To: me@example.com<br>Cc: bob@example.com<br></div><br>"+
+		"<p>See attached</p>", forward.HTML)
+
+	forward = Forward(original, ForwardOptions{From: []string{"other@example.com"}, To: []string{"carol@example.com"}})
+	assert.Equal(t, []string{"other@example.com"}, forward.From)
+	assert.Empty(t, forward.References)
+
+	original.Type = email.EmailTypeSent
+	original.From = []string{"me@example.com"}
+	original.To = []string{"bob@example.com"}
+	forward = Forward(original, ForwardOptions{To: []string{"carol@example.com"}})
+	assert.Equal(t, []string{"me@example.com"}, forward.From)
+}
+
+func TestPrefixSubject(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		subject  string
+		expected string
+	}{
+		{"Re:", "Hello", "Re: Hello"},
+		{"Re:", "re: Hello", "re: Hello"},
+		{"Re:", "", "Re: "},
+		{"Fwd:", "Re: Hello", "Fwd: Re: Hello"},
+		{"Fwd:", "FWD: Hello", "FWD: Hello"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, prefixSubject(test.prefix, test.subject))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package message
+
+import (
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+)
+
+// ThreadHeaders are the headers linking a message to the messages it replies to
+type ThreadHeaders struct {
+	MessageID  string   `json:"messageID,omitempty"`
+	InReplyTo  []string `json:"inReplyTo,omitempty"`
+	References []string `json:"references,omitempty"` // oldest first
+}
+
+// ReadThreadHeaders reads the Message-ID, In-Reply-To and References headers of an RFC 5322 message.
+// Message IDs are returned without the angle brackets.
+func ReadThreadHeaders(r io.Reader) (ThreadHeaders, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return ThreadHeaders{}, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+
+	headers := ThreadHeaders{
+		InReplyTo:  ParseMessageIDs(msg.Header.Get("In-Reply-To")),
+		References: ParseMessageIDs(msg.Header.Get("References")),
+	}
+	if ids := ParseMessageIDs(msg.Header.Get("Message-ID")); len(ids) > 0 {
+		headers.MessageID = ids[0]
+	}
+	return headers, nil
+}
+
+// ParseMessageIDs returns the message IDs of a header such as References, without the angle brackets.
+// Values without angle brackets, which some mailers send, are split on whitespace instead.
+func ParseMessageIDs(value string) []string {
+	var ids []string
+	if !strings.Contains(value, "<") {
+		return strings.Fields(value)
+	}
+	for {
+		_, rest, found := strings.Cut(value, "<")
+		if !found {
+			return ids
+		}
+		id, rest, found := strings.Cut(rest, ">")
+		if !found {
+			return ids
+		}
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+		value = rest
+	}
+}
@@ -0,0 +1,46 @@
+package message
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadThreadHeaders(t *testing.T) {
+	raw := "Message-ID: <c@example.com>\r\n" +
+		"In-Reply-To: <b@example.com>\r\n" +
+		"References: <a@example.com>\r\n <b@example.com>\r\n" +
+		"Subject: Re: Hello\r\n" +
+		"\r\n" +
+		"body\r\n"
+	headers, err := ReadThreadHeaders(strings.NewReader(raw))
+	assert.Nil(t, err)
+	assert.Equal(t, ThreadHeaders{
+		MessageID:  "c@example.com",
+		InReplyTo:  []string{"b@example.com"},
+		References: []string{"a@example.com", "b@example.com"},
+	}, headers)
+
+	_, err = ReadThreadHeaders(strings.NewReader("not a message"))
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestParseMessageIDs(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{value: "", expected: []string{}},
+		{value: "<a@example.com>", expected: []string{"a@example.com"}},
+		{value: "<a@example.com> (comment) <b@example.com>", expected: []string{"a@example.com", "b@example.com"}},
+		{value: "a@example.com b@example.com", expected: []string{"a@example.com", "b@example.com"}},
+		{value: "<a@example.com> <unterminated", expected: []string{"a@example.com"}},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, ParseMessageIDs(test.value))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// maxErrorBody limits how much of an error response is included in the error
+const maxErrorBody = 512
+
+// RunHook runs the command with the shell, writing the payload to its stdin.
+// The environment is extended with the variables in env, and the output of the command goes to stdout and stderr.
+func RunHook(ctx context.Context, command string, payload []byte, env map[string]string, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %q: %w", command, err)
+	}
+	return nil
+}
+
+// PostWebhook POSTs the JSON payload to the URL, failing unless the response status is 2xx
+func PostWebhook(ctx context.Context, client *http.Client, url string, payload []byte) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mailbox-cli")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, resp.Body.Close())
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			return fmt.Errorf("webhook %s: %s", url, resp.Status)
+		}
+		return fmt.Errorf("webhook %s: %s: %s", url, resp.Status, message)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHook(t *testing.T) {
+	tests := []struct {
+		command string
+		stdout  string
+		stderr  string
+		err     bool
+	}{
+		{command: "cat", stdout: `{"id":1}`},
+		{command: `printf %s "$MAILBOX_TEST"`, stdout: "value"},
+		{command: "echo oops >&2; exit 2", stderr: "oops\n", err: true},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			err := RunHook(context.Background(), test.command, []byte(`{"id":1}`), map[string]string{"MAILBOX_TEST": "value"}, stdout, stderr)
+			assert.Equal(t, test.err, err != nil)
+			assert.Equal(t, test.stdout, stdout.String())
+			assert.Equal(t, test.stderr, stderr.String())
+		})
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		err    string
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNoContent},
+		{status: http.StatusInternalServerError, body: "boom\n", err: "500 Internal Server Error: boom"},
+		{status: http.StatusNotFound, err: "404 Not Found"},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				body, err := io.ReadAll(r.Body)
+				assert.Nil(t, err)
+				assert.Equal(t, `{"id":1}`, string(body))
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer ts.Close()
+
+			err := PostWebhook(context.Background(), ts.Client(), ts.URL, []byte(`{"id":1}`))
+			if test.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidJSONPath = errors.New("invalid jsonpath")
+
+// evalJSONPath evaluates a subset of JSONPath against the generic JSON value.
+// Supported syntax: optional surrounding braces and leading "$",
+// field access (.name), array indexes ([0], [-1]) and wildcards ([*], .*), which visit object fields in key order.
+func evalJSONPath(expr string, data any) ([]any, error) {
+	path := strings.TrimSpace(expr)
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidJSONPath, expr, err)
+	}
+
+	current := []any{data}
+	for _, segment := range segments {
+		var next []any
+		for _, value := range current {
+			next = append(next, segment.apply(value)...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+type jsonPathSegment struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func (s jsonPathSegment) apply(value any) []any {
+	switch value := value.(type) {
+	case map[string]any:
+		if s.wildcard {
+			// the keys are sorted, like encoding/json does, so that the output is the same on every run
+			results := make([]any, 0, len(value))
+			for _, key := range slices.Sorted(maps.Keys(value)) {
+				results = append(results, value[key])
+			}
+			return results
+		}
+		if v, ok := value[s.field]; ok && !s.isIndex {
+			return []any{v}
+		}
+	case []any:
+		if s.wildcard {
+			return value
+		}
+		if s.isIndex {
+			index := s.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				return []any{value[index]}
+			}
+		}
+	}
+	return nil
+}
+
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	var segments []jsonPathSegment
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			name := path[:end]
+			path = path[end:]
+			switch name {
+			case "":
+				if path == "" && len(segments) == 0 {
+					return segments, nil // "." refers to the root
+				}
+				return nil, errors.New("empty field name")
+			case "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			default:
+				segments = append(segments, jsonPathSegment{field: name})
+			}
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end == -1 {
+				return nil, errors.New("unclosed bracket")
+			}
+			inner := strings.Trim(path[1:end], `'"`)
+			path = path[end+1:]
+			if inner == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				segments = append(segments, jsonPathSegment{field: inner})
+				continue
+			}
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", path[0])
+		}
+	}
+	return segments, nil
+}
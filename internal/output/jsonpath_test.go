@@ -0,0 +1,51 @@
+package output
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalJSONPath(t *testing.T) {
+	data := map[string]any{
+		"count": float64(2),
+		"items": []any{
+			map[string]any{"messageID": "id-1", "from": []any{"a@example.com"}},
+			map[string]any{"messageID": "id-2", "from": []any{"b@example.com", "c@example.com"}},
+		},
+		"nested": map[string]any{"key": "value", "b": "second", "a": "first"},
+	}
+
+	tests := []struct {
+		expr     string
+		expected []any
+		err      bool
+	}{
+		{expr: "{.count}", expected: []any{float64(2)}},
+		{expr: "$.count", expected: []any{float64(2)}},
+		{expr: ".", expected: []any{data}},
+		{expr: "{.items[*].messageID}", expected: []any{"id-1", "id-2"}},
+		{expr: "{.items[-1].from[0]}", expected: []any{"b@example.com"}},
+		{expr: "{.items[*].from[*]}", expected: []any{"a@example.com", "b@example.com", "c@example.com"}},
+		{expr: "{.nested.*}", expected: []any{"first", "second", "value"}},
+		{expr: "{.nested['key']}", expected: []any{"value"}},
+		{expr: "{.items[5]}", expected: nil},
+		{expr: "{.missing}", expected: nil},
+		{expr: "{.items[0}", err: true},
+		{expr: "{.items..x}", err: true},
+		{expr: "{items}", err: true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			results, err := evalJSONPath(test.expr, data)
+			if test.err {
+				assert.ErrorIs(t, err, ErrInvalidJSONPath)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, results)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// The supported output formats
+const (
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatNDJSON   = "ndjson"
+	FormatTable    = "table"
+	FormatTemplate = "template"
+	FormatJSONPath = "jsonpath"
+)
+
+// Formats lists the supported values of the --output flag
+var Formats = []string{FormatTable, FormatJSON, FormatYAML, FormatNDJSON, FormatTemplate + "=<go-template>", FormatJSONPath + "=<expr>"}
+
+var ErrInvalidFormat = errors.New("invalid output format")
+
+// Format is a parsed output format, such as "json" or "template={{.subject}}"
+type Format struct {
+	Name string
+	Arg  string
+}
+
+// ParseFormat parses the value of the --output flag. An empty value means JSON.
+func ParseFormat(value string) (Format, error) {
+	name, arg, hasArg := strings.Cut(value, "=")
+	switch name {
+	case "":
+		return Format{Name: FormatJSON}, nil
+	case FormatJSON, FormatYAML, FormatNDJSON, FormatTable:
+		if hasArg {
+			return Format{}, fmt.Errorf("%w: %s", ErrInvalidFormat, value)
+		}
+		return Format{Name: name}, nil
+	case FormatTemplate, FormatJSONPath:
+		if arg == "" {
+			return Format{}, fmt.Errorf("%w: %s requires an expression", ErrInvalidFormat, name)
+		}
+		return Format{Name: name, Arg: arg}, nil
+	}
+	return Format{}, fmt.Errorf("%w: %s", ErrInvalidFormat, value)
+}
+
+// Write renders v to w in the given format
+func Write(w io.Writer, format Format, v any) error {
+	switch format.Name {
+	case FormatYAML:
+		return writeYAML(w, v)
+	case FormatNDJSON:
+		return writeNDJSON(w, v)
+	case FormatTable:
+		return writeTable(w, v)
+	case FormatTemplate:
+		return writeTemplate(w, format.Arg, v)
+	case FormatJSONPath:
+		return writeJSONPath(w, format.Arg, v)
+	}
+	return writeJSON(w, v)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	// cannot use json.MarshalIndent because it escapes unicode characters
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func marshalJSON(v any) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// generic converts v to the generic JSON representation, so that field names match the JSON output
+func generic(v any) (any, error) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+func writeYAML(w io.Writer, v any) error {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return err
+	}
+
+	// decoding JSON as a YAML node preserves the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// resetStyle switches the flow style of JSON to the block style of YAML
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// List is implemented by results listing items, which the ndjson format writes one per line
+type List interface {
+	ListItems() []any
+}
+
+// Table is implemented by results that the table format writes as rows under a header.
+// A time.Time cell is written as a local date, a []string cell as a comma-separated list, and other cells with %v.
+type Table interface {
+	TableHeader() []string
+	TableRows() [][]any
+}
+
+// Transcript is implemented by results that the table format may write as a transcript instead of rows.
+// Each entry is a line of header cells followed by a body; ok is false to write the result as a table.
+type Transcript interface {
+	TranscriptEntries() (headers [][]any, bodies []string, ok bool)
+}
+
+// writeNDJSON writes one JSON object per line: one per item for lists, otherwise the value itself
+func writeNDJSON(w io.Writer, v any) error {
+	items := []any{v}
+	if list, ok := v.(List); ok {
+		items = list.ListItems()
+	}
+
+	for _, item := range items {
+		data, err := marshalJSON(item)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, v any) error {
+	if transcript, ok := v.(Transcript); ok {
+		if headers, bodies, ok := transcript.TranscriptEntries(); ok {
+			return writeTranscript(w, headers, bodies)
+		}
+	}
+	table, ok := v.(Table)
+	if !ok {
+		return writeJSON(w, v)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(table.TableHeader(), "\t"))
+	for _, row := range table.TableRows() {
+		fmt.Fprintln(tw, joinCells(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeTranscript writes the entries one after another, each with a header line followed by its body
+func writeTranscript(w io.Writer, headers [][]any, bodies []string) error {
+	for i, header := range headers {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "--- %s\n%s\n", joinCells(header, "  "), strings.TrimRight(bodies[i], "\r\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinCells(cells []any, sep string) string {
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		switch cell := cell.(type) {
+		case time.Time:
+			values[i] = formatDate(cell)
+		case []string:
+			values[i] = strings.Join(cell, ", ")
+		default:
+			values[i] = fmt.Sprint(cell)
+		}
+	}
+	return strings.Join(values, sep)
+}
+
+// formatDate formats the time of an email in the local time zone, or returns an empty string if unknown
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func writeTemplate(w io.Writer, text string, v any) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return err
+	}
+	data, err := generic(v)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+func writeJSONPath(w io.Writer, expr string, v any) error {
+	data, err := generic(v)
+	if err != nil {
+		return err
+	}
+	results, err := evalJSONPath(expr, data)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if s, ok := result.(string); ok {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := marshalJSON(result)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/bulk"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+	"github.com/harryzcy/mailbox-cli/internal/thread"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value  string
+		format Format
+		err    error
+	}{
+		{value: "", format: Format{Name: FormatJSON}},
+		{value: "json", format: Format{Name: FormatJSON}},
+		{value: "yaml", format: Format{Name: FormatYAML}},
+		{value: "ndjson", format: Format{Name: FormatNDJSON}},
+		{value: "table", format: Format{Name: FormatTable}},
+		{value: "template={{.subject}}", format: Format{Name: FormatTemplate, Arg: "{{.subject}}"}},
+		{value: "jsonpath={.items[*].messageID}", format: Format{Name: FormatJSONPath, Arg: "{.items[*].messageID}"}},
+		{value: "template=", err: ErrInvalidFormat},
+		{value: "json=x", err: ErrInvalidFormat},
+		{value: "xml", err: ErrInvalidFormat},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			format, err := ParseFormat(test.value)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.format, format)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() {
+		time.Local = local
+	}()
+
+	list := &email.ListResult{
+		Count: 2,
+		Items: []email.Email{
+			{
+				MessageID:    "id-1",
+				Subject:      "Hello <world>",
+				From:         []string{"alice@example.com"},
+				TimeReceived: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			{
+				MessageID: "id-2",
+				Subject:   "Draft",
+				From:      []string{"bob@example.com", "carol@example.com"},
+			},
+		},
+	}
+
+	tests := []struct {
+		format   Format
+		value    any
+		expected string
+		err      bool
+	}{
+		{
+			format:   Format{Name: FormatJSON},
+			value:    &email.ActionResult{MessageID: "id-1", Status: "trashed"},
+			expected: "{\n  \"messageID\": \"id-1\",\n  \"status\": \"trashed\"\n}\n",
+		},
+		{
+			format:   Format{Name: FormatYAML},
+			value:    &email.Email{MessageID: "id-1", Subject: "Hello", To: []string{"to@example.com"}},
+			expected: "messageID: id-1\nsubject: Hello\nto:\n  - to@example.com\n",
+		},
+		{
+			format:   Format{Name: FormatNDJSON},
+			value:    list,
+			expected: "{\"messageID\":\"id-1\",\"subject\":\"Hello <world>\",\"from\":[\"alice@example.com\"],\"timeReceived\":\"2025-01-02T03:04:05Z\"}\n{\"messageID\":\"id-2\",\"subject\":\"Draft\",\"from\":[\"bob@example.com\",\"carol@example.com\"]}\n",
+		},
+		{
+			format:   Format{Name: FormatNDJSON},
+			value:    &email.ActionResult{MessageID: "id-1"},
+			expected: "{\"messageID\":\"id-1\"}\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value:  list,
+			expected: "DATE                 FROM                                SUBJECT        ID\n" +
+				"2025-01-02 03:04:05  alice@example.com                   Hello <world>  id-1\n" +
+				"                     bob@example.com, carol@example.com  Draft          id-2\n",
+		},
+		{
+			format:   Format{Name: FormatTable},
+			value:    &email.ActionResult{MessageID: "id-1", Status: "trashed"},
+			expected: "ID    STATUS\nid-1  trashed\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value: &email.AttachmentsResult{
+				Attachments: []email.Attachment{{ContentID: "a1", Filename: "a.pdf", ContentType: "application/pdf"}},
+				Inlines:     []email.Attachment{{ContentID: "logo", Filename: "logo.png", ContentType: "image/png"}},
+			},
+			expected: "KIND        FILENAME  CONTENT TYPE     CONTENT ID\n" +
+				"attachment  a.pdf     application/pdf  a1\n" +
+				"inline      logo.png  image/png        logo\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value: &search.Result{Count: 1, Items: []search.Hit{
+				{Email: list.Items[0], Score: 1.5, Snippet: "say **hello**"},
+			}},
+			expected: "DATE                 FROM               SUBJECT        SNIPPET        ID\n" +
+				"2025-01-02 03:04:05  alice@example.com  Hello <world>  say **hello**  id-1\n",
+		},
+		{
+			format:   Format{Name: FormatNDJSON},
+			value:    &search.Result{Count: 1, Items: []search.Hit{{Email: email.Email{MessageID: "id-1"}, Score: 2}}},
+			expected: "{\"messageID\":\"id-1\",\"subject\":\"\",\"score\":2}\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value: &thread.Result{View: thread.ViewTree, Count: 3, Items: []thread.Item{
+				{Email: list.Items[0]},
+				{Email: email.Email{MessageID: "id-3", Subject: "Re: Hello", From: []string{"bob@example.com"}}, Depth: 1},
+				{Email: email.Email{MessageID: "id-4", Subject: "Re: Re: Hello", From: []string{"alice@example.com"}}, Depth: 2},
+			}},
+			expected: "DATE                 FROM               SUBJECT             ID\n" +
+				"2025-01-02 03:04:05  alice@example.com  Hello <world>       id-1\n" +
+				"                     bob@example.com    └─ Re: Hello        id-3\n" +
+				"                     alice@example.com    └─ Re: Re: Hello  id-4\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value: &thread.Result{View: thread.ViewTranscript, Count: 2, Items: []thread.Item{
+				{Email: email.Email{Subject: "Hello", From: []string{"alice@example.com"}, Text: "Hi Bob\n"}},
+				{Email: email.Email{Subject: "Re: Hello", From: []string{"bob@example.com"}, HTML: "<p>Hi Alice</p>"}, Depth: 1},
+			}},
+			expected: "---   alice@example.com  Hello\nHi Bob\n\n---   bob@example.com  Re: Hello\nHi Alice\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value: &thread.GroupResult{Count: 1, Items: []thread.Summary{
+				{Subject: "Hello <world>", Count: 2, Unread: 1, From: []string{"alice@example.com"}, Latest: list.Items[0]},
+			}},
+			expected: "DATE                 FROM               SUBJECT        COUNT  UNREAD  LATEST ID\n" +
+				"2025-01-02 03:04:05  alice@example.com  Hello <world>  2      1       id-1\n",
+		},
+		{
+			format: Format{Name: FormatTable},
+			value: &bulk.Result{Succeeded: 1, Failed: 1, Items: []bulk.Item{
+				{MessageID: "id-1", Status: bulk.StatusOK, Result: &email.ActionResult{MessageID: "id-1"}},
+				{MessageID: "id-2", Status: bulk.StatusFailed, Error: "not found"},
+			}},
+			expected: "ID    STATUS  ERROR\n" +
+				"id-1  ok      \n" +
+				"id-2  failed  not found\n",
+		},
+		{
+			format:   Format{Name: FormatNDJSON},
+			value:    &bulk.Result{Succeeded: 1, Items: []bulk.Item{{MessageID: "id-1", Status: bulk.StatusOK, Result: &email.ActionResult{MessageID: "id-1"}}}},
+			expected: "{\"messageID\":\"id-1\",\"status\":\"ok\",\"result\":{\"messageID\":\"id-1\"}}\n",
+		},
+		{
+			format:   Format{Name: FormatTable},
+			value:    map[string]string{"key": "value"},
+			expected: "{\n  \"key\": \"value\"\n}\n",
+		},
+		{
+			format:   Format{Name: FormatTemplate, Arg: "{{range .items}}{{.messageID}}: {{.subject}};{{end}}"},
+			value:    list,
+			expected: "id-1: Hello <world>;id-2: Draft;\n",
+		},
+		{
+			format: Format{Name: FormatTemplate, Arg: "{{.invalid"},
+			value:  list,
+			err:    true,
+		},
+		{
+			format:   Format{Name: FormatJSONPath, Arg: "{.items[*].messageID}"},
+			value:    list,
+			expected: "id-1\nid-2\n",
+		},
+		{
+			format:   Format{Name: FormatJSONPath, Arg: "{.items[1].from}"},
+			value:    list,
+			expected: "[\"bob@example.com\",\"carol@example.com\"]\n",
+		},
+		{
+			format: Format{Name: FormatJSONPath, Arg: "{.items[}"},
+			value:  list,
+			err:    true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := Write(buf, test.format, test.value)
+			if test.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, buf.String())
+		})
+	}
+}
+
+// table implements Table with cells of every kind
+type table struct{}
+
+func (table) TableHeader() []string {
+	return []string{"NAME", "COUNT", "TAGS", "DATE"}
+}
+
+func (table) TableRows() [][]any {
+	return [][]any{{"a", 2, []string{"x", "y"}, time.Time{}}}
+}
+
+func TestWrite_Table(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := Write(buf, Format{Name: FormatTable}, table{})
+	assert.Nil(t, err)
+	assert.Equal(t, "NAME  COUNT  TAGS  DATE\na     2      x, y  \n", buf.String())
+
+	// values that aren't tables are written as JSON
+	buf.Reset()
+	err = Write(buf, Format{Name: FormatTable}, map[string]int{"count": 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"count\": 1\n}\n", buf.String())
+}
+
+func TestWrite_Error(t *testing.T) {
+	err := Write(new(bytes.Buffer), Format{Name: FormatYAML}, make(chan int))
+	assert.NotNil(t, err)
+	err = Write(new(bytes.Buffer), Format{Name: FormatNDJSON}, make(chan int))
+	assert.NotNil(t, err)
+	err = Write(new(bytes.Buffer), Format{Name: FormatJSONPath, Arg: ".x"}, make(chan int))
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidJSONPath))
+}
@@ -0,0 +1,219 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/config"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+	"go.yaml.in/yaml/v3"
+)
+
+var ErrInvalidRules = errors.New("invalid rules")
+
+// The values of Actions.Mark
+const (
+	MarkRead   = "read"
+	MarkUnread = "unread"
+)
+
+// File is the rules file, stored as rules.yaml in the configuration directory
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule applies its actions to the emails matching all of its conditions
+type Rule struct {
+	Name    string  `yaml:"name"`
+	Match   Match   `yaml:"match"`
+	Actions Actions `yaml:"actions"`
+}
+
+// Match is the conditions of a rule. Empty conditions match every email.
+type Match struct {
+	Type          string `yaml:"type"`          // inbox if empty
+	From          string `yaml:"from"`          // substring of a From address, case-insensitive
+	To            string `yaml:"to"`            // substring of a To, Cc or Bcc address, case-insensitive
+	Subject       string `yaml:"subject"`       // regular expression
+	OlderThan     string `yaml:"olderThan"`     // age such as 30d, 2w or 12h
+	NewerThan     string `yaml:"newerThan"`     // age such as 30d, 2w or 12h
+	HasAttachment *bool  `yaml:"hasAttachment"` // requires fetching each email otherwise matching
+
+	subject   *regexp.Regexp
+	olderThan time.Duration
+	newerThan time.Duration
+}
+
+// Actions are applied in the order of the fields, stopping at the first failure
+type Actions struct {
+	Mark    string   `yaml:"mark"` // read or unread
+	Hook    string   `yaml:"hook"` // shell command run with the email JSON on stdin
+	Forward *Forward `yaml:"forward"`
+	Trash   bool     `yaml:"trash"`
+	Delete  bool     `yaml:"delete"`
+}
+
+// Forward forwards the email with its attachments
+type Forward struct {
+	From []string `yaml:"from"` // defaults to the address the email was sent to
+	To   []string `yaml:"to"`
+	Text string   `yaml:"text"`
+	Send bool     `yaml:"send"` // send immediately instead of creating a draft
+}
+
+// Path returns the default location of the rules file
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rules.yaml"), nil
+}
+
+// Load reads and validates the rules file at path
+func Load(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Parse parses and validates the rules in YAML
+func Parse(data []byte) ([]Rule, error) {
+	file := &File{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("%w: no rules", ErrInvalidRules)
+	}
+
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if rule.Name == "" {
+			rule.Name = "rule " + strconv.Itoa(i+1)
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%w: rule %q: %w", ErrInvalidRules, rule.Name, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func (r *Rule) compile() error {
+	m := &r.Match
+	if m.Type == "" {
+		m.Type = email.EmailTypeInbox
+	}
+	if !slices.Contains([]string{email.EmailTypeInbox, email.EmailTypeSent, email.EmailTypeDraft}, m.Type) {
+		return fmt.Errorf("unknown type %q", m.Type)
+	}
+
+	var err error
+	if m.Subject != "" {
+		if m.subject, err = regexp.Compile(m.Subject); err != nil {
+			return fmt.Errorf("subject: %w", err)
+		}
+	}
+	if m.OlderThan != "" {
+		if m.olderThan, err = ParseAge(m.OlderThan); err != nil {
+			return fmt.Errorf("olderThan: %w", err)
+		}
+	}
+	if m.NewerThan != "" {
+		if m.newerThan, err = ParseAge(m.NewerThan); err != nil {
+			return fmt.Errorf("newerThan: %w", err)
+		}
+	}
+
+	a := r.Actions
+	if a.Mark != "" && a.Mark != MarkRead && a.Mark != MarkUnread {
+		return fmt.Errorf("mark must be read or unread, not %q", a.Mark)
+	}
+	if a.Forward != nil && len(a.Forward.To) == 0 {
+		return errors.New("forward requires at least one recipient")
+	}
+	if a.Trash && a.Delete {
+		return errors.New("trash and delete can't be combined")
+	}
+	if len(a.Names()) == 0 {
+		return errors.New("no actions")
+	}
+	return nil
+}
+
+// ParseAge parses an age such as 30d or 2w, in addition to the units of time.ParseDuration
+func ParseAge(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return d, nil
+}
+
+// NeedsBody reports whether matching requires the full email rather than the listed metadata
+func (m Match) NeedsBody() bool {
+	return m.HasAttachment != nil
+}
+
+// Matches reports whether the email meets all conditions at the time now.
+// The type isn't checked, since listed emails are already of the type.
+func (m Match) Matches(e email.Email, now time.Time) bool {
+	query := search.Query{From: m.From, To: m.To}
+	if m.OlderThan != "" {
+		query.Until = now.Add(-m.olderThan)
+	}
+	if m.NewerThan != "" {
+		query.Since = now.Add(-m.newerThan)
+	}
+	if !query.Match(e) {
+		return false
+	}
+	if m.subject != nil && !m.subject.MatchString(e.Subject) {
+		return false
+	}
+	return m.HasAttachment == nil || *m.HasAttachment == (len(e.Attachments) > 0)
+}
+
+// Names returns the names of the actions in the order they're applied, such as "mark read" and "trash"
+func (a Actions) Names() []string {
+	var names []string
+	if a.Mark != "" {
+		names = append(names, "mark "+a.Mark)
+	}
+	if a.Hook != "" {
+		names = append(names, "hook")
+	}
+	if a.Forward != nil {
+		names = append(names, "forward")
+	}
+	if a.Trash {
+		names = append(names, "trash")
+	}
+	if a.Delete {
+		names = append(names, "delete")
+	}
+	return names
+}
@@ -0,0 +1,132 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value string
+		age   time.Duration
+		err   bool
+	}{
+		{value: "30d", age: 30 * 24 * time.Hour},
+		{value: "2w", age: 14 * 24 * time.Hour},
+		{value: "12h", age: 12 * time.Hour},
+		{value: "90m", age: 90 * time.Minute},
+		{value: "d", err: true},
+		{value: "-1d", err: true},
+		{value: "soon", err: true},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			age, err := ParseAge(test.value)
+			assert.Equal(t, test.err, err != nil)
+			assert.Equal(t, test.age, age)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{data: "rules:\n  - match: {from: a}\n    actions: {trash: true}\n"},
+		{data: "rules:\n  - actions: {forward: {from: [me@example.com], to: [boss@example.com]}}\n"},
+		{data: "rules: []", err: "invalid rules: no rules"},
+		{data: "rules: [", err: "invalid rules: yaml"},
+		{data: "rules:\n  - actions: {}\n", err: `rule "rule 1": no actions`},
+		{data: "rules:\n  - match: {type: spam}\n    actions: {trash: true}\n", err: `unknown type "spam"`},
+		{data: "rules:\n  - match: {subject: '('}\n    actions: {trash: true}\n", err: "subject: error parsing regexp"},
+		{data: "rules:\n  - match: {olderThan: x}\n    actions: {trash: true}\n", err: `olderThan: invalid age "x"`},
+		{data: "rules:\n  - match: {newerThan: x}\n    actions: {trash: true}\n", err: `newerThan: invalid age "x"`},
+		{data: "rules:\n  - actions: {mark: seen}\n", err: `mark must be read or unread, not "seen"`},
+		{data: "rules:\n  - actions: {forward: {text: hi}}\n", err: "forward requires at least one recipient"},
+		{data: "rules:\n  - name: both\n    actions: {trash: true, delete: true}\n", err: `rule "both": trash and delete can't be combined`},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rules, err := Parse([]byte(test.data))
+			if test.err != "" {
+				assert.ErrorIs(t, err, ErrInvalidRules)
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, rules, 1)
+			assert.Equal(t, "rule 1", rules[0].Name)
+			assert.Equal(t, email.EmailTypeInbox, rules[0].Match.Type)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	_, err := Load(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	err = os.WriteFile(path, []byte("rules: []"), 0o600)
+	assert.Nil(t, err)
+	_, err = Load(path)
+	assert.ErrorContains(t, err, path+": invalid rules")
+
+	t.Setenv("XDG_CONFIG_HOME", "/config")
+	path, err = Path()
+	assert.Nil(t, err)
+	assert.Equal(t, "/config/mailbox-cli/rules.yaml", path)
+}
+
+func TestMatch_Matches(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+	e := email.Email{
+		MessageID:    "id",
+		Subject:      "[GitHub] New issue",
+		From:         []string{"GitHub <notifications@github.com>"},
+		To:           []string{"me@example.com"},
+		Cc:           []string{"team@example.com"},
+		TimeReceived: now.Add(-40 * 24 * time.Hour),
+		Attachments:  []email.Attachment{{Filename: "a.txt"}},
+	}
+	yes, no := true, false
+	tests := []struct {
+		match   Match
+		matches bool
+	}{
+		{match: Match{}, matches: true},
+		{match: Match{From: "NOTIFICATIONS@github.com"}, matches: true},
+		{match: Match{From: "alice"}, matches: false},
+		{match: Match{To: "team@"}, matches: true},
+		{match: Match{To: "alice"}, matches: false},
+		{match: Match{Subject: `^\[GitHub\]`}, matches: true},
+		{match: Match{Subject: `^New`}, matches: false},
+		{match: Match{OlderThan: "30d"}, matches: true},
+		{match: Match{OlderThan: "8w"}, matches: false},
+		{match: Match{NewerThan: "8w"}, matches: true},
+		{match: Match{NewerThan: "30d"}, matches: false},
+		{match: Match{HasAttachment: &yes}, matches: true},
+		{match: Match{HasAttachment: &no}, matches: false},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rule := Rule{Match: test.match, Actions: Actions{Trash: true}}
+			err := rule.compile()
+			assert.Nil(t, err)
+			assert.Equal(t, test.matches, rule.Match.Matches(e, now))
+			assert.Equal(t, test.match.HasAttachment != nil, rule.Match.NeedsBody())
+		})
+	}
+}
+
+func TestActions_Names(t *testing.T) {
+	actions := Actions{Mark: MarkRead, Hook: "cat", Forward: &Forward{To: []string{"a@example.com"}}, Delete: true}
+	assert.Equal(t, []string{"mark read", "hook", "forward", "delete"}, actions.Names())
+	assert.Empty(t, Actions{}.Names())
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+var operators = map[string]bool{
+	"from": true, "to": true, "type": true, "has": true, "since": true, "after": true, "until": true, "before": true,
+}
+
+// ParseQuery parses a query of words and operators, which is shared by search and the --where selectors:
+//
+//	from:alice         a From address containing the text
+//	to:team@           a To, Cc or Bcc address containing the text
+//	type:inbox         emails of the type: inbox, sent or draft
+//	has:attachment     emails with attachments
+//	since:2025-01-01   emails on or after the date, and until: on or before it
+//	after:2025-01-01   emails after the date, and before: before it
+//
+// Dates are in the location. Values may be double-quoted to include spaces, and the other words are terms.
+// Operators are case-insensitive.
+func ParseQuery(text string, loc *time.Location) (Query, error) {
+	query := Query{}
+	words, err := splitQuery(text)
+	if err != nil {
+		return Query{}, err
+	}
+
+	for _, word := range words {
+		key, value, ok := strings.Cut(word, ":")
+		operator := strings.ToLower(key)
+		if !ok || !operators[operator] {
+			// words such as "Re:" and URLs are terms, which can only narrow the query
+			query.Terms = append(query.Terms, Tokenize(word)...)
+			continue
+		}
+		if value == "" {
+			return Query{}, fmt.Errorf("%w: %s: requires a value", ErrInvalidQuery, key)
+		}
+
+		switch operator {
+		case "from":
+			query.From = value
+		case "to":
+			query.To = value
+		case "type":
+			if value != email.EmailTypeInbox && value != email.EmailTypeSent && value != email.EmailTypeDraft {
+				return Query{}, fmt.Errorf("%w: unknown type %q", ErrInvalidQuery, value)
+			}
+			query.Type = value
+		case "has":
+			if value != "attachment" {
+				return Query{}, fmt.Errorf("%w: has:%s, expected has:attachment", ErrInvalidQuery, value)
+			}
+			query.HasAttachment = true
+		case "since", "after", "until", "before":
+			date, err := time.ParseInLocation(time.DateOnly, value, loc)
+			if err != nil {
+				return Query{}, fmt.Errorf("%w: %s:%s, expected YYYY-MM-DD", ErrInvalidQuery, key, value)
+			}
+			// the bounds are narrowed, so that repeating an operator can't widen the selection
+			switch operator {
+			case "since":
+				query.Since = latest(query.Since, date)
+			case "after":
+				query.Since = latest(query.Since, date.AddDate(0, 0, 1))
+			case "until":
+				query.Until = earliest(query.Until, date.AddDate(0, 0, 1))
+			case "before":
+				query.Until = earliest(query.Until, date)
+			}
+		}
+	}
+	return query, nil
+}
+
+// splitQuery splits the text into words at whitespace outside of double quotes, removing the quotes
+func splitQuery(text string) ([]string, error) {
+	var words []string
+	word := &strings.Builder{}
+	quoted, inWord := false, false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.IsZero() || b.After(a) {
+		return b
+	}
+	return a
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		text     string
+		expected Query
+		err      string
+	}{
+		{text: "", expected: Query{}},
+		{text: "Quarterly REPORT", expected: Query{Terms: []string{"quarterly", "report"}}},
+		{
+			text:     `from:alerts@x.com to:"Team Lead" type:sent has:attachment budget`,
+			expected: Query{Terms: []string{"budget"}, From: "alerts@x.com", To: "Team Lead", Type: "sent", HasAttachment: true},
+		},
+		{text: "since:2025-01-01 until:2025-01-31", expected: Query{Since: day(1, 1), Until: day(2, 1)}},
+		{text: "after:2025-01-01 before:2025-02-01", expected: Query{Since: day(1, 2), Until: day(2, 1)}},
+		{text: "before:2025-03-01 before:2025-02-01 since:2025-01-05 after:2025-01-01", expected: Query{Since: day(1, 5), Until: day(2, 1)}},
+		{text: "FROM:bob", expected: Query{From: "bob"}},
+		{text: "Re: https://example.com", expected: Query{Terms: []string{"re", "https", "example", "com"}}},
+		{text: "from:", err: "from: requires a value"},
+		{text: "type:spam", err: `unknown type "spam"`},
+		{text: "has:link", err: "has:link, expected has:attachment"},
+		{text: "before:yesterday", err: "before:yesterday, expected YYYY-MM-DD"},
+		{text: "form:alice", expected: Query{Terms: []string{"form", "alice"}}},
+		{text: `from:"alice`, err: "unterminated quote"},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			query, err := ParseQuery(test.text, time.UTC)
+			if test.err != "" {
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, query)
+		})
+	}
+}
+
+func TestQuery_MatchTerms(t *testing.T) {
+	e := email.Email{Subject: "Quarterly report", From: []string{"alice@example.com"}}
+	tests := []struct {
+		terms   []string
+		matches bool
+	}{
+		{terms: nil, matches: true},
+		{terms: []string{"report"}, matches: true},
+		{terms: []string{"report", "alice"}, matches: true},
+		{terms: []string{"report", "bob"}, matches: false},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.matches, Query{Terms: test.terms}.MatchTerms(e))
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"golang.org/x/net/html"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// subjectWeight counts each term of the subject as if it appeared this many times,
+// since a match in the subject is more relevant than one in the body
+const subjectWeight = 3
+
+// Tokenize splits the text into lowercase terms of letters and digits, folding diacritics so that "café" matches "cafe"
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// fold lowercases the text and removes diacritics
+func fold(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = text
+	}
+	return strings.ToLower(folded)
+}
+
+// Body returns the text of the email, extracting it from the HTML if there is no text part
+func Body(e email.Email) string {
+	if strings.TrimSpace(e.Text) != "" || e.HTML == "" {
+		return e.Text
+	}
+	return htmlText(e.HTML)
+}
+
+// blockTags start a new line when converting HTML to text
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "br": true, "div": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+var htmlWhitespace = strings.NewReplacer("\r", " ", "\n", " ", "\t", " ")
+
+// htmlText returns the text content of the HTML, with a line per block and without scripts and styles
+func htmlText(source string) string {
+	b := &strings.Builder{}
+	newline := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+	tokenizer := html.NewTokenizer(strings.NewReader(source))
+	skip := 0
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			lines := strings.Split(b.String(), "\n")
+			for i, line := range lines {
+				lines[i] = strings.Join(strings.Fields(line), " ")
+			}
+			return strings.TrimSpace(strings.Join(lines, "\n"))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if string(name) == "script" || string(name) == "style" {
+				skip++
+			} else if blockTags[string(name)] {
+				newline()
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if (string(name) == "script" || string(name) == "style") && skip > 0 {
+				skip--
+			} else if blockTags[string(name)] {
+				newline()
+			}
+		case html.TextToken:
+			if skip == 0 {
+				// line breaks in HTML text are whitespace like any other, collapsed with it once the lines are split
+				b.WriteString(htmlWhitespace.Replace(string(tokenizer.Text())))
+			}
+		}
+	}
+}
+
+// DocumentTerms returns the frequency of each term in the email and the length of the document,
+// indexing the subject, addresses, attachment names and body
+func DocumentTerms(e email.Email) (map[string]int, int) {
+	terms := map[string]int{}
+	length := 0
+	add := func(text string, weight int) {
+		for _, term := range Tokenize(text) {
+			terms[term] += weight
+			length += weight
+		}
+	}
+
+	add(e.Subject, subjectWeight)
+	for _, addresses := range [][]string{e.From, e.To, e.Cc, e.Bcc, e.ReplyTo} {
+		add(strings.Join(addresses, " "), 1)
+	}
+	for _, attachment := range e.Attachments {
+		add(attachment.Filename, 1)
+	}
+	add(Body(e), 1)
+	return terms, length
+}
+
+// Query describes the emails to find. All terms must match, and the filters are optional.
+type Query struct {
+	Terms         []string
+	From          string // substring of a From address
+	To            string // substring of a To, Cc or Bcc address
+	Since         time.Time
+	Until         time.Time // exclusive
+	HasAttachment bool
+	Type          string
+}
+
+// Match reports whether the email passes the filters of the query, regardless of the terms
+func (q Query) Match(e email.Email) bool {
+	if q.Type != "" && e.Type != q.Type {
+		return false
+	}
+	if q.From != "" && !containsAddress(e.From, q.From) {
+		return false
+	}
+	if q.To != "" && !containsAddress(e.To, q.To) && !containsAddress(e.Cc, q.To) && !containsAddress(e.Bcc, q.To) {
+		return false
+	}
+	if t := e.Time(); (!q.Since.IsZero() && t.Before(q.Since)) || (!q.Until.IsZero() && !t.Before(q.Until)) {
+		return false
+	}
+	return !q.HasAttachment || len(e.Attachments) > 0
+}
+
+// MatchTerms reports whether the email contains every term of the query,
+// for matching emails outside of the index such as the results of the list API
+func (q Query) MatchTerms(e email.Email) bool {
+	terms, _ := DocumentTerms(e)
+	for _, term := range q.Terms {
+		if terms[term] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAddress(addresses []string, substr string) bool {
+	substr = strings.ToLower(substr)
+	for _, address := range addresses {
+		if strings.Contains(strings.ToLower(address), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// The parameters of BM25, using the common defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// BM25 scores a term of a document, given the term frequency in the document, the number of documents containing the term,
+// the total number of documents, and the length of the document relative to the average length
+func BM25(tf, df, docs int, length, avgLength float64) float64 {
+	idf := math.Log(1 + (float64(docs)-float64(df)+0.5)/(float64(df)+0.5))
+	norm := 1.0
+	if avgLength > 0 {
+		norm = 1 - bm25B + bm25B*length/avgLength
+	}
+	return idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+}
+
+// Hit is an email found by a search, without its body
+type Hit struct {
+	email.Email
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+// Result lists the hits of a search, the most relevant first
+type Result struct {
+	Count int   `json:"count"`
+	Items []Hit `json:"items"`
+}
+
+// ListItems returns the hits, which the ndjson output writes one per line
+func (r *Result) ListItems() []any {
+	items := make([]any, len(r.Items))
+	for i, hit := range r.Items {
+		items[i] = hit
+	}
+	return items
+}
+
+func (r *Result) TableHeader() []string {
+	return []string{"DATE", "FROM", "SUBJECT", "SNIPPET", "ID"}
+}
+
+func (r *Result) TableRows() [][]any {
+	rows := make([][]any, len(r.Items))
+	for i, hit := range r.Items {
+		rows[i] = []any{hit.Time(), hit.From, hit.Subject, hit.Snippet, hit.MessageID}
+	}
+	return rows
+}
+
+// Highlight markers surrounding the matched words of a snippet
+const (
+	HighlightStart = "**"
+	HighlightEnd   = "**"
+)
+
+// snippetWords is the number of words of a snippet
+const snippetWords = 24
+
+// Snippet returns an excerpt of the text around the first matched term, with the matched words highlighted
+func Snippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return ""
+	}
+
+	match := func(word string) bool {
+		for _, token := range Tokenize(word) {
+			for _, term := range terms {
+				if token == term {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	first := -1
+	for i, word := range words {
+		if match(word) {
+			first = i
+			break
+		}
+	}
+	start := 0
+	if first >= 0 {
+		// show a few words of context before the match
+		start = max(0, first-snippetWords/4)
+	}
+	end := min(len(words), start+snippetWords)
+
+	parts := make([]string, 0, end-start+2)
+	if start > 0 {
+		parts = append(parts, "…")
+	}
+	for _, word := range words[start:end] {
+		if match(word) {
+			word = HighlightStart + word + HighlightEnd
+		}
+		parts = append(parts, word)
+	}
+	if end < len(words) {
+		parts = append(parts, "…")
+	}
+	return strings.Join(parts, " ")
+}
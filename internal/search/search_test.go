@@ -0,0 +1,110 @@
+package search
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "", expected: []string{}},
+		{text: "Hello, World!", expected: []string{"hello", "world"}},
+		{text: "Café crème", expected: []string{"cafe", "creme"}},
+		{text: "alice@example.com", expected: []string{"alice", "example", "com"}},
+		{text: "Q3-2025 report.pdf", expected: []string{"q3", "2025", "report", "pdf"}},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, Tokenize(test.text))
+		})
+	}
+}
+
+func TestBody(t *testing.T) {
+	assert.Equal(t, "text", Body(email.Email{Text: "text", HTML: "<p>html</p>"}))
+	assert.Equal(t, "Hello\nworld", Body(email.Email{
+		HTML: "<html><head><style>p { color: red; }</style></head><body><p>Hello</p><script>alert(1)</script><p>world</p></body></html>",
+	}))
+	assert.Equal(t, "Pizza today?\nSee you at 12:00", Body(email.Email{
+		HTML: "<div>Pizza\n  <b>today</b>?<br>See you&nbsp;at <i>12:00</i></div>",
+	}))
+}
+
+func TestDocumentTerms(t *testing.T) {
+	terms, length := DocumentTerms(email.Email{
+		Subject:     "Report",
+		From:        []string{"alice@example.com"},
+		Text:        "See the report",
+		Attachments: []email.Attachment{{Filename: "q3.pdf"}},
+	})
+	assert.Equal(t, subjectWeight+1, terms["report"])
+	assert.Equal(t, 1, terms["alice"])
+	assert.Equal(t, 1, terms["q3"])
+	assert.Equal(t, subjectWeight+3+2+3, length)
+}
+
+func TestQuery_Match(t *testing.T) {
+	e := email.Email{
+		Type:         email.EmailTypeInbox,
+		From:         []string{"Alice <alice@example.com>"},
+		Cc:           []string{"bob@example.com"},
+		TimeReceived: time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		query    Query
+		expected bool
+	}{
+		{query: Query{}, expected: true},
+		{query: Query{Type: email.EmailTypeSent}, expected: false},
+		{query: Query{From: "ALICE"}, expected: true},
+		{query: Query{From: "bob"}, expected: false},
+		{query: Query{To: "bob"}, expected: true},
+		{query: Query{Since: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)}, expected: true},
+		{query: Query{Since: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC)}, expected: false},
+		{query: Query{Until: time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)}, expected: false},
+		{query: Query{HasAttachment: true}, expected: false},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, test.query.Match(e))
+		})
+	}
+}
+
+func TestBM25(t *testing.T) {
+	// rarer terms and higher frequencies score higher
+	assert.Greater(t, BM25(1, 1, 10, 10, 10), BM25(1, 5, 10, 10, 10))
+	assert.Greater(t, BM25(3, 1, 10, 10, 10), BM25(1, 1, 10, 10, 10))
+	// shorter documents score higher
+	assert.Greater(t, BM25(1, 1, 10, 5, 10), BM25(1, 1, 10, 20, 10))
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		text     string
+		terms    []string
+		expected string
+	}{
+		{text: "", terms: []string{"a"}, expected: ""},
+		{text: "Hello, Café world", terms: []string{"cafe"}, expected: "Hello, **Café** world"},
+		{text: "no match here", terms: []string{"other"}, expected: "no match here"},
+		{
+			text:  "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour twentyfive twentysix",
+			terms: []string{"twelve"},
+			expected: "… six seven eight nine ten eleven **twelve** thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty " +
+				"twentyone twentytwo twentythree twentyfour twentyfive twentysix",
+		},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, Snippet(test.text, test.terms))
+		})
+	}
+}
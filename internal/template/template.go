@@ -0,0 +1,178 @@
+package template
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/markdown"
+	"go.yaml.in/yaml/v3"
+)
+
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// Template is an email with Go template placeholders, loaded from a YAML file.
+// HTML is executed with html/template, so that variables are escaped; the other fields use text/template.
+type Template struct {
+	Subject  string   `yaml:"subject"`
+	From     []string `yaml:"from"`
+	To       []string `yaml:"to"`
+	Cc       []string `yaml:"cc"`
+	Bcc      []string `yaml:"bcc"`
+	ReplyTo  []string `yaml:"replyTo"`
+	Text     string   `yaml:"text"`
+	HTML     string   `yaml:"html"`
+	Markdown string   `yaml:"markdown"` // rendered to both text and HTML after executing the template
+
+	// InlineCSS adds inline styles to the HTML rendered from Markdown
+	InlineCSS bool `yaml:"inlineCSS"`
+}
+
+// Load reads the template file at path
+func Load(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{}
+	if err := yaml.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, path, err)
+	}
+	if t.Markdown != "" && (t.Text != "" || t.HTML != "") {
+		return nil, fmt.Errorf("%w: %s: markdown can't be combined with text or html", ErrInvalidTemplate, path)
+	}
+	return t, nil
+}
+
+// Execute renders the template with the variables. Referencing an undefined variable is an error.
+// Addresses that render to an empty string are dropped, so that optional recipients can be left empty.
+func (t *Template) Execute(vars map[string]string) (email.CreateOptions, error) {
+	options := email.CreateOptions{}
+
+	var err error
+	if options.Subject, err = executeText("subject", t.Subject, vars); err != nil {
+		return email.CreateOptions{}, err
+	}
+	for _, field := range []struct {
+		name   string
+		values []string
+		dest   *[]string
+	}{
+		{"from", t.From, &options.From},
+		{"to", t.To, &options.To},
+		{"cc", t.Cc, &options.Cc},
+		{"bcc", t.Bcc, &options.Bcc},
+		{"replyTo", t.ReplyTo, &options.ReplyTo},
+	} {
+		for _, value := range field.values {
+			address, err := executeText(field.name, value, vars)
+			if err != nil {
+				return email.CreateOptions{}, err
+			}
+			if address = strings.TrimSpace(address); address != "" {
+				*field.dest = append(*field.dest, address)
+			}
+		}
+	}
+
+	if t.Markdown != "" {
+		source, err := executeText("markdown", t.Markdown, vars)
+		if err != nil {
+			return email.CreateOptions{}, err
+		}
+		options.HTML, options.Text, err = markdown.Render([]byte(source), markdown.Options{InlineCSS: t.InlineCSS})
+		if err != nil {
+			return email.CreateOptions{}, err
+		}
+		options.GenerateText = email.GenerateTextOff
+		return options, nil
+	}
+
+	if options.Text, err = executeText("text", t.Text, vars); err != nil {
+		return email.CreateOptions{}, err
+	}
+	if options.HTML, err = executeHTML("html", t.HTML, vars); err != nil {
+		return email.CreateOptions{}, err
+	}
+	return options, nil
+}
+
+func executeText(name, text string, vars map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := texttemplate.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, vars); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+func executeHTML(name, text string, vars map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := htmltemplate.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, vars); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+// ParseVars parses variables in the format of key=value
+func ParseVars(specs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected key=value", spec)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// ReadData reads the rows of a CSV file as variables, using the header row as the variable names
+func ReadData(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+
+	header := records[0]
+	for i, name := range header {
+		// spreadsheet applications may prepend a byte order mark
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,128 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "template.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	assert.Nil(t, err)
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tmpl, err := Load(writeTemplate(t, "subject: Hello {{.name}}\n"+
+		"from: [newsletter@example.com]\n"+
+		"to: ['{{.name}} <{{.email}}>']\n"+
+		"replyTo: [support@example.com]\n"+
+		"text: Hi {{.name}}\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, &Template{
+		Subject: "Hello {{.name}}",
+		From:    []string{"newsletter@example.com"},
+		To:      []string{"{{.name}} <{{.email}}>"},
+		ReplyTo: []string{"support@example.com"},
+		Text:    "Hi {{.name}}",
+	}, tmpl)
+
+	_, err = Load(writeTemplate(t, "markdown: '# Hi'\ntext: Hi\n"))
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	_, err = Load(writeTemplate(t, "to: invalid: yaml\n"))
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestTemplate_Execute(t *testing.T) {
+	tests := []struct {
+		tmpl     Template
+		vars     map[string]string
+		expected email.CreateOptions
+		err      error
+	}{
+		{
+			tmpl: Template{
+				Subject: "Hello {{.name}}",
+				From:    []string{"newsletter@example.com"},
+				To:      []string{"{{.name}} <{{.email}}>"},
+				Cc:      []string{"{{.manager}}"},
+				Text:    "Hi {{.name}}",
+				HTML:    "<p>Hi {{.name}}</p>",
+			},
+			vars: map[string]string{"name": "Tom & Jerry", "email": "tom@example.com", "manager": " "},
+			expected: email.CreateOptions{
+				Subject: "Hello Tom & Jerry",
+				From:    []string{"newsletter@example.com"},
+				To:      []string{"Tom & Jerry <tom@example.com>"},
+				Text:    "Hi Tom & Jerry",
+				HTML:    "<p>Hi Tom &amp; Jerry</p>",
+			},
+		},
+		{
+			tmpl: Template{Subject: "Notice", Markdown: "# Hi {{.name}}\n", InlineCSS: false},
+			vars: map[string]string{"name": "Tom"},
+			expected: email.CreateOptions{
+				Subject:      "Notice",
+				Text:         "Hi Tom\n======\n",
+				HTML:         "<h1>Hi Tom</h1>\n",
+				GenerateText: email.GenerateTextOff,
+			},
+		},
+		{
+			tmpl: Template{Subject: "Hello {{.name}}"},
+			vars: map[string]string{},
+			err:  ErrInvalidTemplate,
+		},
+		{
+			tmpl: Template{Text: "Hi {{.name"},
+			vars: map[string]string{"name": "Tom"},
+			err:  ErrInvalidTemplate,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			options, err := test.tmpl.Execute(test.vars)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, options)
+		})
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"name=Tom", "greeting=a=b", "empty="})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"name": "Tom", "greeting": "a=b", "empty": ""}, vars)
+
+	_, err = ParseVars([]string{"name"})
+	assert.NotNil(t, err)
+	_, err = ParseVars([]string{"=value"})
+	assert.NotNil(t, err)
+}
+
+func TestReadData(t *testing.T) {
+	rows, err := ReadData(strings.NewReader("\ufeffname, email\nTom,tom@example.com\n\"Doe, Jane\",jane@example.com\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]string{
+		{"name": "Tom", "email": "tom@example.com"},
+		{"name": "Doe, Jane", "email": "jane@example.com"},
+	}, rows)
+
+	_, err = ReadData(strings.NewReader(""))
+	assert.NotNil(t, err)
+	_, err = ReadData(strings.NewReader("name,email\nTom\n"))
+	assert.NotNil(t, err)
+}
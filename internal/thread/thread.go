@@ -0,0 +1,286 @@
+package thread
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/message"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+)
+
+// The ways a thread can be rendered
+const (
+	ViewTree       = "tree"       // replies below the email they reply to
+	ViewTranscript = "transcript" // chronological, with the bodies
+)
+
+// Message is an email along with its threading headers, which may be empty if unknown
+type Message struct {
+	Email   email.Email
+	Headers message.ThreadHeaders
+}
+
+// Item is an email of a thread
+type Item struct {
+	email.Email
+	Depth    int    `json:"depth"`              // number of ancestors in the thread
+	ParentID string `json:"parentID,omitempty"` // message ID of the email it replies to
+}
+
+// Result is a reconstructed conversation
+type Result struct {
+	ThreadID string `json:"threadID,omitempty"`
+	Subject  string `json:"subject"`
+	View     string `json:"view"`
+	Count    int    `json:"count"`
+	Items    []Item `json:"items"`
+}
+
+// ListItems returns the emails of the thread, which the ndjson output writes one per line
+func (r *Result) ListItems() []any {
+	items := make([]any, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = item
+	}
+	return items
+}
+
+func (r *Result) TableHeader() []string {
+	return []string{"DATE", "FROM", "SUBJECT", "ID"}
+}
+
+// TableRows indents the subjects of replies below the email they reply to
+func (r *Result) TableRows() [][]any {
+	rows := make([][]any, len(r.Items))
+	for i, item := range r.Items {
+		subject := item.Subject
+		if item.Depth > 0 {
+			subject = strings.Repeat("  ", item.Depth-1) + "└─ " + subject
+		}
+		rows[i] = []any{item.Time(), item.From, subject, item.MessageID}
+	}
+	return rows
+}
+
+// TranscriptEntries writes the transcript view as the emails one after another, each with its body,
+// converted from HTML if there is no text
+func (r *Result) TranscriptEntries() (headers [][]any, bodies []string, ok bool) {
+	if r.View != ViewTranscript {
+		return nil, nil, false
+	}
+	for _, item := range r.Items {
+		body := item.Text
+		if strings.TrimSpace(body) == "" {
+			body = search.Body(item.Email)
+		}
+		headers = append(headers, []any{item.Time(), item.From, item.Subject})
+		bodies = append(bodies, body)
+	}
+	return headers, bodies, true
+}
+
+// Build reconstructs the thread of the email with the message ID from the candidate messages.
+//
+// A message replies to the latest of its References, or else In-Reply-To, that is among the candidates.
+// The thread contains the messages linked to the email through replies, along with those sharing its backend thread ID.
+// In the tree view, items are in depth-first order with replies sorted chronologically;
+// in the transcript view, they are sorted chronologically.
+func Build(messageID string, candidates []Message, view string) *Result {
+	byHeader := map[string]int{}
+	for i, m := range candidates {
+		if m.Headers.MessageID != "" {
+			byHeader[m.Headers.MessageID] = i
+		}
+	}
+
+	parents := make([]int, len(candidates))
+	for i := range parents {
+		parents[i] = -1
+	}
+	for i, m := range candidates {
+		references := append(slices.Clone(m.Headers.References), m.Headers.InReplyTo...)
+		for _, id := range slices.Backward(references) {
+			if j, ok := byHeader[id]; ok && !isAncestor(parents, i, j) {
+				parents[i] = j
+				break
+			}
+		}
+	}
+
+	// find every message connected to the email
+	root := slices.IndexFunc(candidates, func(m Message) bool {
+		return m.Email.MessageID == messageID
+	})
+	if root < 0 {
+		return &Result{View: view, Items: []Item{}}
+	}
+	threadID := candidates[root].Email.ThreadID
+	included := make([]bool, len(candidates))
+	included[root] = true
+	for changed := true; changed; {
+		changed = false
+		for i := range candidates {
+			if included[i] {
+				continue
+			}
+			if (threadID != "" && candidates[i].Email.ThreadID == threadID) ||
+				(parents[i] >= 0 && included[parents[i]]) || slices.ContainsFunc(children(parents, i), func(j int) bool { return included[j] }) {
+				included[i] = true
+				changed = true
+			}
+		}
+	}
+
+	byTime := func(a, b int) int {
+		return cmp.Compare(candidates[a].Email.Time().UnixNano(), candidates[b].Email.Time().UnixNano())
+	}
+	var items []Item
+	var visit func(i, depth int)
+	visit = func(i, depth int) {
+		item := Item{Email: candidates[i].Email, Depth: depth}
+		if parents[i] >= 0 {
+			item.ParentID = candidates[parents[i]].Email.MessageID
+		}
+		items = append(items, item)
+		replies := slices.SortedStableFunc(slices.Values(children(parents, i)), byTime)
+		for _, j := range replies {
+			if included[j] {
+				visit(j, depth+1)
+			}
+		}
+	}
+	var roots []int
+	for i := range candidates {
+		if included[i] && (parents[i] < 0 || !included[parents[i]]) {
+			roots = append(roots, i)
+		}
+	}
+	slices.SortStableFunc(roots, byTime)
+	for _, i := range roots {
+		visit(i, 0)
+	}
+
+	if view == ViewTranscript {
+		slices.SortStableFunc(items, func(a, b Item) int {
+			return cmp.Compare(a.Time().UnixNano(), b.Time().UnixNano())
+		})
+	}
+	return &Result{
+		ThreadID: threadID,
+		Subject:  candidates[roots[0]].Email.Subject,
+		View:     view,
+		Count:    len(items),
+		Items:    items,
+	}
+}
+
+// isAncestor reports whether i is j or an ancestor of j, in which case j can't become the parent of i
+func isAncestor(parents []int, i, j int) bool {
+	for ; j >= 0; j = parents[j] {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
+
+func children(parents []int, i int) []int {
+	var result []int
+	for j, parent := range parents {
+		if parent == i {
+			result = append(result, j)
+		}
+	}
+	return result
+}
+
+// subjectPrefix matches the reply and forward prefixes added by mail clients, such as "Re:", "Fwd:" and "AW:"
+var subjectPrefix = regexp.MustCompile(`(?i)^\s*(re|fwd?|aw|sv|vs)(\[\d+\])?\s*:\s*`)
+
+// NormalizeSubject returns the subject without reply and forward prefixes, in lowercase
+func NormalizeSubject(subject string) string {
+	for {
+		trimmed := subjectPrefix.ReplaceAllString(subject, "")
+		if trimmed == subject {
+			return strings.ToLower(strings.TrimSpace(subject))
+		}
+		subject = trimmed
+	}
+}
+
+// Summary describes a thread among listed emails
+type Summary struct {
+	ThreadID   string      `json:"threadID,omitempty"`
+	Subject    string      `json:"subject"`
+	Count      int         `json:"count"`
+	Unread     int         `json:"unread,omitempty"`
+	From       []string    `json:"from,omitempty"` // distinct senders, in the order they first appear
+	Latest     email.Email `json:"latest"`
+	MessageIDs []string    `json:"messageIDs"` // in the order listed
+}
+
+// GroupResult lists the threads of listed emails, in the order their first email is listed
+type GroupResult struct {
+	Count int       `json:"count"`
+	Items []Summary `json:"items"`
+}
+
+// ListItems returns the threads, which the ndjson output writes one per line
+func (r *GroupResult) ListItems() []any {
+	items := make([]any, len(r.Items))
+	for i, summary := range r.Items {
+		items[i] = summary
+	}
+	return items
+}
+
+func (r *GroupResult) TableHeader() []string {
+	return []string{"DATE", "FROM", "SUBJECT", "COUNT", "UNREAD", "LATEST ID"}
+}
+
+func (r *GroupResult) TableRows() [][]any {
+	rows := make([][]any, len(r.Items))
+	for i, summary := range r.Items {
+		rows[i] = []any{summary.Latest.Time(), summary.From, summary.Subject, summary.Count, summary.Unread, summary.Latest.MessageID}
+	}
+	return rows
+}
+
+// Group groups listed emails by thread. Emails are grouped by their backend thread ID,
+// or by subject ignoring reply and forward prefixes if they don't have one.
+func Group(items []email.Email) *GroupResult {
+	summaries := []Summary{}
+	index := map[string]int{}
+	for _, item := range items {
+		key := "subject:" + NormalizeSubject(item.Subject)
+		if item.ThreadID != "" {
+			key = "thread:" + item.ThreadID
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, Summary{ThreadID: item.ThreadID, Subject: item.Subject, MessageIDs: []string{}})
+		}
+
+		summary := &summaries[i]
+		summary.Count++
+		summary.MessageIDs = append(summary.MessageIDs, item.MessageID)
+		if item.Unread != nil && *item.Unread {
+			summary.Unread++
+		}
+		for _, from := range item.From {
+			if !slices.Contains(summary.From, from) {
+				summary.From = append(summary.From, from)
+			}
+		}
+		if summary.Count == 1 || item.Time().After(summary.Latest.Time()) {
+			summary.Latest = item
+		}
+	}
+
+	return &GroupResult{Count: len(summaries), Items: summaries}
+}
@@ -0,0 +1,128 @@
+package thread
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func at(minute int) time.Time {
+	return time.Date(2025, time.January, 1, 0, minute, 0, 0, time.UTC)
+}
+
+func TestBuild(t *testing.T) {
+	candidates := []Message{
+		{
+			Email:   email.Email{MessageID: "c", Subject: "Re: Hello", TimeReceived: at(3)},
+			Headers: message.ThreadHeaders{MessageID: "c@x", InReplyTo: []string{"a@x"}, References: []string{"a@x"}},
+		},
+		{
+			Email:   email.Email{MessageID: "a", Subject: "Hello", TimeReceived: at(1)},
+			Headers: message.ThreadHeaders{MessageID: "a@x"},
+		},
+		{
+			Email:   email.Email{MessageID: "b", Subject: "Re: Hello", TimeSent: at(2), Text: "reply"},
+			Headers: message.ThreadHeaders{MessageID: "b@x", References: []string{"a@x", "missing@x"}},
+		},
+		{
+			Email:   email.Email{MessageID: "d", Subject: "Re: Re: Hello", TimeReceived: at(4)},
+			Headers: message.ThreadHeaders{MessageID: "d@x", InReplyTo: []string{"b@x"}, References: []string{"a@x", "b@x"}},
+		},
+		{
+			// same subject, but not linked to the thread
+			Email:   email.Email{MessageID: "e", Subject: "Hello", TimeReceived: at(5)},
+			Headers: message.ThreadHeaders{MessageID: "e@x"},
+		},
+	}
+
+	result := Build("d", candidates, ViewTree)
+	assert.Equal(t, "Hello", result.Subject)
+	assert.Equal(t, 4, result.Count)
+	ids := []string{}
+	depths := []int{}
+	for _, item := range result.Items {
+		ids = append(ids, item.MessageID)
+		depths = append(depths, item.Depth)
+	}
+	assert.Equal(t, []string{"a", "b", "d", "c"}, ids)
+	assert.Equal(t, []int{0, 1, 2, 1}, depths)
+	assert.Equal(t, "b", result.Items[2].ParentID)
+
+	result = Build("a", candidates, ViewTranscript)
+	ids = []string{}
+	for _, item := range result.Items {
+		ids = append(ids, item.MessageID)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids)
+
+	result = Build("missing", candidates, ViewTree)
+	assert.Equal(t, 0, result.Count)
+}
+
+func TestBuild_ThreadID(t *testing.T) {
+	// without headers, emails are linked by their backend thread ID
+	candidates := []Message{
+		{Email: email.Email{MessageID: "b", ThreadID: "t", TimeReceived: at(2)}},
+		{Email: email.Email{MessageID: "a", ThreadID: "t", TimeReceived: at(1)}},
+		{Email: email.Email{MessageID: "c", ThreadID: "other", TimeReceived: at(3)}},
+	}
+	result := Build("b", candidates, ViewTree)
+	assert.Equal(t, "t", result.ThreadID)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, "a", result.Items[0].MessageID)
+	assert.Equal(t, 0, result.Items[1].Depth)
+}
+
+func TestBuild_Cycle(t *testing.T) {
+	candidates := []Message{
+		{Email: email.Email{MessageID: "a"}, Headers: message.ThreadHeaders{MessageID: "a@x", InReplyTo: []string{"b@x"}}},
+		{Email: email.Email{MessageID: "b"}, Headers: message.ThreadHeaders{MessageID: "b@x", InReplyTo: []string{"a@x"}}},
+	}
+	result := Build("a", candidates, ViewTree)
+	assert.Equal(t, 2, result.Count)
+}
+
+func TestNormalizeSubject(t *testing.T) {
+	tests := []struct {
+		subject  string
+		expected string
+	}{
+		{subject: "Hello", expected: "hello"},
+		{subject: "Re: Hello", expected: "hello"},
+		{subject: "RE: Fwd: re[2]: Hello ", expected: "hello"},
+		{subject: "AW: Hello", expected: "hello"},
+		{subject: "Regarding: Hello", expected: "regarding: hello"},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeSubject(test.subject))
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	unread := true
+	result := Group([]email.Email{
+		{MessageID: "4", Subject: "Re: Hello", From: []string{"bob@example.com"}, Unread: &unread, TimeReceived: at(4)},
+		{MessageID: "3", Subject: "Report", ThreadID: "t", From: []string{"carol@example.com"}, TimeReceived: at(3)},
+		{MessageID: "2", Subject: "Re: Report", ThreadID: "t", From: []string{"carol@example.com"}, TimeReceived: at(2)},
+		{MessageID: "1", Subject: "Hello", From: []string{"alice@example.com"}, TimeReceived: at(1)},
+	})
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, Summary{
+		Subject:    "Re: Hello",
+		Count:      2,
+		Unread:     1,
+		From:       []string{"bob@example.com", "alice@example.com"},
+		Latest:     email.Email{MessageID: "4", Subject: "Re: Hello", From: []string{"bob@example.com"}, Unread: &unread, TimeReceived: at(4)},
+		MessageIDs: []string{"4", "1"},
+	}, result.Items[0])
+	assert.Equal(t, "t", result.Items[1].ThreadID)
+	assert.Equal(t, []string{"3", "2"}, result.Items[1].MessageIDs)
+
+	assert.Equal(t, &GroupResult{Count: 0, Items: []Summary{}}, Group(nil))
+}
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/mattn/go-runewidth"
+)
+
+// folderWidth is the width of the folder pane, excluding the separator
+const folderWidth = 18
+
+var (
+	styleDefault  = tcell.StyleDefault
+	styleBar      = tcell.StyleDefault.Reverse(true)
+	styleHeader   = tcell.StyleDefault.Bold(true)
+	styleSelected = tcell.StyleDefault.Reverse(true)
+	styleDimmed   = tcell.StyleDefault.Dim(true)
+	styleUnread   = tcell.StyleDefault.Bold(true)
+)
+
+const keyHelp = "tab:pane  enter:open  t:trash  u:untrash  d:delete  r/R:reply  s:send  q:quit"
+
+// listHeight returns the number of rows of the message list
+func (a *App) listHeight() int {
+	_, height := a.screen.Size()
+	// the title and status bars, and the separator between the list and the reader
+	return max((height-3)/2, 1)
+}
+
+// readerHeight returns the number of rows of the reader pane
+func (a *App) readerHeight() int {
+	_, height := a.screen.Size()
+	return max(height-3-a.listHeight(), 1)
+}
+
+func (a *App) pageSize() int {
+	if a.focus == paneReader {
+		return a.readerHeight()
+	}
+	return a.listHeight()
+}
+
+func (a *App) draw() {
+	a.screen.Clear()
+	width, height := a.screen.Size()
+
+	title := " mailbox-cli"
+	if f := a.currentFolder(); !f.header() {
+		title += fmt.Sprintf(" — %s (%d emails", f, len(a.items))
+		if a.nextCursor != "" {
+			title += ", more"
+		}
+		title += ")"
+	}
+	fill(a.screen, 0, 0, width, styleBar)
+	drawText(a.screen, 0, 0, width, styleBar, title)
+
+	bodyHeight := height - 2
+	for y := 1; y <= bodyHeight; y++ {
+		a.screen.SetContent(folderWidth, y, '│', nil, styleDimmed)
+	}
+	a.drawFolders(0, 1, folderWidth, bodyHeight)
+
+	x := folderWidth + 1
+	listHeight := a.listHeight()
+	a.drawList(x, 1, width-x, listHeight)
+	for i := x; i < width; i++ {
+		a.screen.SetContent(i, 1+listHeight, '─', nil, styleDimmed)
+	}
+	a.drawReader(x, 2+listHeight, width-x, a.readerHeight())
+
+	fill(a.screen, 0, height-1, width, styleBar)
+	switch {
+	case a.prompt != nil:
+		line := a.prompt.label
+		if !a.prompt.confirm {
+			line += string(a.prompt.input)
+		}
+		drawText(a.screen, 0, height-1, width, styleBar, line)
+		a.screen.ShowCursor(min(runewidth.StringWidth(line), width-1), height-1)
+	case a.status != "":
+		drawText(a.screen, 0, height-1, width, styleBar, a.status)
+		a.screen.HideCursor()
+	default:
+		drawText(a.screen, 0, height-1, width, styleBar, keyHelp)
+		a.screen.HideCursor()
+	}
+
+	a.screen.Show()
+}
+
+func (a *App) drawFolders(x, y, width, height int) {
+	a.folderOffset = scroll(a.folderOffset, a.folderIndex, height)
+	for row := range height {
+		i := a.folderOffset + row
+		if i >= len(a.folders) {
+			break
+		}
+		f := a.folders[i]
+		if f.header() {
+			drawText(a.screen, x, y+row, width, styleHeader, strings.ToUpper(f.Type[:1])+f.Type[1:])
+			continue
+		}
+		style := styleDefault
+		if i == a.folderIndex {
+			style = selectedStyle(a.focus == paneFolders)
+		}
+		fill(a.screen, x, y+row, width, style)
+		drawText(a.screen, x, y+row, width, style, fmt.Sprintf("  %04d-%02d", f.Year, int(f.Month)))
+	}
+}
+
+func (a *App) drawList(x, y, width, height int) {
+	if len(a.items) == 0 {
+		drawText(a.screen, x+1, y, width-1, styleDimmed, "No emails")
+		return
+	}
+
+	a.listOffset = scroll(a.listOffset, a.listIndex, height)
+	for row := range height {
+		i := a.listOffset + row
+		if i >= len(a.items) {
+			break
+		}
+		item := a.items[i]
+
+		flag := " "
+		style := styleDefault
+		switch {
+		case a.trashed[item.MessageID]:
+			flag = "T"
+			style = styleDimmed
+		case item.Unread != nil && *item.Unread:
+			flag = "●"
+			style = styleUnread
+		}
+		if i == a.listIndex {
+			style = selectedStyle(a.focus == paneList)
+		}
+
+		date := ""
+		if t := item.Time(); !t.IsZero() {
+			date = t.Local().Format(time.DateOnly)
+		}
+		// the recipients are more useful than our own address for sent emails and drafts
+		from := strings.Join(item.From, ", ")
+		if a.currentFolder().Type != email.EmailTypeInbox {
+			from = strings.Join(item.To, ", ")
+		}
+		line := fmt.Sprintf("%s %-10s  %-24s  %s", flag, date, truncate(from, 24), item.Subject)
+		fill(a.screen, x, y+row, width, style)
+		drawText(a.screen, x, y+row, width, style, line)
+	}
+}
+
+func (a *App) drawReader(x, y, width, height int) {
+	if a.reading == nil {
+		drawText(a.screen, x+1, y, width-1, styleDimmed, "Press enter to read the selected email")
+		return
+	}
+	for row := range height {
+		i := a.readerOffset + row
+		if i >= len(a.readerLines) {
+			break
+		}
+		drawText(a.screen, x+1, y+row, width-1, styleDefault, a.readerLines[i])
+	}
+}
+
+func selectedStyle(focused bool) tcell.Style {
+	if focused {
+		return styleSelected
+	}
+	return styleDefault.Underline(true)
+}
+
+// scroll returns the offset of a pane of the height so that the selected row is visible
+func scroll(offset, selected, height int) int {
+	if selected < offset {
+		return selected
+	}
+	if selected >= offset+height {
+		return selected - height + 1
+	}
+	return offset
+}
+
+func fill(screen tcell.Screen, x, y, width int, style tcell.Style) {
+	for i := range width {
+		screen.SetContent(x+i, y, ' ', nil, style)
+	}
+}
+
+// drawText draws a line of text, clipped to the width and with tabs expanded
+func drawText(screen tcell.Screen, x, y, width int, style tcell.Style, text string) {
+	col := 0
+	for _, r := range strings.ReplaceAll(text, "\t", "    ") {
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		if col+w > width {
+			return
+		}
+		screen.SetContent(x+col, y, r, nil, style)
+		col += w
+	}
+}
+
+// truncate shortens the text to the width, ending with an ellipsis if shortened
+func truncate(text string, width int) string {
+	if runewidth.StringWidth(text) <= width {
+		return text
+	}
+	return runewidth.Truncate(text, width, "…")
+}
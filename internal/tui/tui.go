@@ -0,0 +1,506 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/harryzcy/mailbox-cli/internal/message"
+	"github.com/harryzcy/mailbox-cli/internal/search"
+)
+
+// Client is the part of email.Client used by the interface, so that tests can use a fake client
+type Client interface {
+	List(ctx context.Context, options email.ListOptions) (*email.ListResult, error)
+	Get(ctx context.Context, options email.GetOptions) (*email.Email, error)
+	GetRaw(ctx context.Context, options email.GetRawOptions, w io.Writer) (int64, error)
+	Trash(ctx context.Context, options email.TrashOptions) (*email.ActionResult, error)
+	Untrash(ctx context.Context, options email.UntrashOptions) (*email.ActionResult, error)
+	Delete(ctx context.Context, options email.DeleteOptions) (*email.ActionResult, error)
+	Send(ctx context.Context, options email.SendOptions) (*email.ActionResult, error)
+	Create(ctx context.Context, options email.CreateOptions) (*email.Email, error)
+}
+
+// DefaultMonths is the number of months listed for each type in the folder pane
+const DefaultMonths = 12
+
+type Options struct {
+	Types  []string  // email types of the folder pane, inbox, sent and draft if empty
+	Months int       // number of months of each type, including the current month
+	Now    time.Time // the current time, which selects the months
+}
+
+// folder is a row of the folder pane: either the header of a type, or a month of the type
+type folder struct {
+	Type  string
+	Year  int
+	Month time.Month // zero for a header
+}
+
+func (f folder) header() bool {
+	return f.Month == 0
+}
+
+func (f folder) String() string {
+	return fmt.Sprintf("%s %04d-%02d", f.Type, f.Year, int(f.Month))
+}
+
+type pane int
+
+const (
+	paneFolders pane = iota
+	paneList
+	paneReader
+)
+
+// prompt reads a line of input in the status bar, or a single key if confirm is set
+type prompt struct {
+	label   string
+	input   []rune
+	confirm bool
+	submit  func(input string)
+}
+
+// App is the full-screen interface. It is driven by key events, and calls the client synchronously.
+type App struct {
+	ctx    context.Context
+	screen tcell.Screen
+	client Client
+
+	folders      []folder
+	folderIndex  int
+	folderOffset int
+
+	items      []email.Email
+	trashed    map[string]bool
+	nextCursor string
+	listIndex  int
+	listOffset int
+
+	reading      *email.Email
+	readerLines  []string
+	readerOffset int
+
+	focus  pane
+	status string
+	prompt *prompt
+	quit   bool
+}
+
+// New creates the interface on an initialized screen
+func New(ctx context.Context, screen tcell.Screen, client Client, options Options) *App {
+	types := options.Types
+	if len(types) == 0 {
+		types = []string{email.EmailTypeInbox, email.EmailTypeSent, email.EmailTypeDraft}
+	}
+	months := options.Months
+	if months <= 0 {
+		months = DefaultMonths
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	a := &App{ctx: ctx, screen: screen, client: client, trashed: map[string]bool{}, focus: paneList}
+	for _, emailType := range types {
+		a.folders = append(a.folders, folder{Type: emailType})
+		t := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for range months {
+			a.folders = append(a.folders, folder{Type: emailType, Year: t.Year(), Month: t.Month()})
+			t = t.AddDate(0, -1, 0)
+		}
+	}
+	a.folderIndex = 1
+	return a
+}
+
+// Run shows the interface until the user quits
+func (a *App) Run() error {
+	a.openFolder()
+	for !a.quit {
+		a.draw()
+		switch ev := a.screen.PollEvent().(type) {
+		case nil:
+			// the screen was finalized
+			return nil
+		case *tcell.EventKey:
+			a.handleKey(ev)
+		case *tcell.EventResize:
+			a.screen.Sync()
+		}
+	}
+	return nil
+}
+
+func (a *App) handleKey(ev *tcell.EventKey) {
+	if a.prompt != nil {
+		a.handlePrompt(ev)
+		return
+	}
+	a.status = ""
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		a.quit = true
+		return
+	case tcell.KeyTab:
+		a.focus = (a.focus + 1) % 3
+		return
+	case tcell.KeyBacktab:
+		a.focus = (a.focus + 2) % 3
+		return
+	case tcell.KeyEsc, tcell.KeyLeft:
+		if a.focus > paneFolders {
+			a.focus--
+		}
+		return
+	case tcell.KeyUp:
+		a.move(-1)
+		return
+	case tcell.KeyDown:
+		a.move(1)
+		return
+	case tcell.KeyPgUp:
+		a.move(-a.pageSize())
+		return
+	case tcell.KeyPgDn:
+		a.move(a.pageSize())
+		return
+	case tcell.KeyEnter, tcell.KeyRight:
+		a.enter()
+		return
+	case tcell.KeyRune:
+	default:
+		return
+	}
+
+	switch ev.Rune() {
+	case 'q':
+		a.quit = true
+	case 'k':
+		a.move(-1)
+	case 'j':
+		a.move(1)
+	case 'h':
+		if a.focus > paneFolders {
+			a.focus--
+		}
+	case 'l':
+		a.enter()
+	case 'g':
+		a.move(-len(a.items) - len(a.folders) - len(a.readerLines))
+	case 'G':
+		a.move(len(a.items) + len(a.folders) + len(a.readerLines))
+	case 't':
+		a.trash()
+	case 'u':
+		a.untrash()
+	case 'd':
+		a.delete()
+	case 'r':
+		a.reply(false)
+	case 'R':
+		a.reply(true)
+	case 's':
+		a.send()
+	}
+}
+
+func (a *App) handlePrompt(ev *tcell.EventKey) {
+	p := a.prompt
+	if p.confirm {
+		a.prompt = nil
+		if ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y') {
+			p.submit("y")
+		} else {
+			a.status = "Cancelled"
+		}
+		return
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEsc, tcell.KeyCtrlC:
+		a.prompt = nil
+		a.status = "Cancelled"
+	case tcell.KeyEnter:
+		a.prompt = nil
+		p.submit(string(p.input))
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(p.input) > 0 {
+			p.input = p.input[:len(p.input)-1]
+		}
+	case tcell.KeyRune:
+		p.input = append(p.input, ev.Rune())
+	}
+}
+
+// move moves the selection of the focused pane by delta rows
+func (a *App) move(delta int) {
+	switch a.focus {
+	case paneFolders:
+		index := a.folderIndex
+		step := 1
+		if delta < 0 {
+			step = -1
+		}
+		for range abs(delta) {
+			next := index + step
+			for next >= 0 && next < len(a.folders) && a.folders[next].header() {
+				next += step
+			}
+			if next < 0 || next >= len(a.folders) {
+				break
+			}
+			index = next
+		}
+		a.folderIndex = index
+	case paneList:
+		if len(a.items) == 0 {
+			return
+		}
+		a.listIndex = min(max(a.listIndex+delta, 0), len(a.items)-1)
+		if a.listIndex == len(a.items)-1 && a.nextCursor != "" {
+			a.loadMore()
+		}
+	case paneReader:
+		a.readerOffset = min(max(a.readerOffset+delta, 0), max(len(a.readerLines)-a.readerHeight(), 0))
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (a *App) enter() {
+	switch a.focus {
+	case paneFolders:
+		a.openFolder()
+		a.focus = paneList
+	case paneList:
+		a.openMessage()
+	}
+}
+
+func (a *App) currentFolder() folder {
+	return a.folders[a.folderIndex]
+}
+
+// selected returns the selected email of the list, if any
+func (a *App) selected() *email.Email {
+	if a.listIndex < 0 || a.listIndex >= len(a.items) {
+		return nil
+	}
+	return &a.items[a.listIndex]
+}
+
+func (a *App) openFolder() {
+	a.items, a.nextCursor = nil, ""
+	a.listIndex, a.listOffset = 0, 0
+	a.reading, a.readerLines, a.readerOffset = nil, nil, 0
+	a.loading()
+	a.loadMore()
+}
+
+// loadMore lists the next page of the current folder
+func (a *App) loadMore() {
+	f := a.currentFolder()
+	result, err := a.client.List(a.ctx, email.ListOptions{
+		Type:       f.Type,
+		Year:       fmt.Sprintf("%04d", f.Year),
+		Month:      fmt.Sprintf("%02d", int(f.Month)),
+		Order:      email.OrderDesc,
+		NextCursor: a.nextCursor,
+	})
+	if err != nil {
+		a.status = "Error: " + err.Error()
+		return
+	}
+	a.items = append(a.items, result.Items...)
+	if result.NextCursor == a.nextCursor {
+		// guard against a backend returning the same cursor forever
+		a.nextCursor = ""
+	} else {
+		a.nextCursor = result.NextCursor
+	}
+}
+
+func (a *App) openMessage() {
+	item := a.selected()
+	if item == nil {
+		return
+	}
+	a.loading()
+	full, err := a.client.Get(a.ctx, email.GetOptions{MessageID: item.MessageID})
+	if err != nil {
+		a.status = "Error: " + err.Error()
+		return
+	}
+	a.reading = full
+	a.readerLines = readerLines(*full)
+	a.readerOffset = 0
+	a.focus = paneReader
+
+	if item.Unread != nil && *item.Unread {
+		// the backend marks inbox emails as read when they are fetched
+		read := false
+		item.Unread = &read
+	}
+}
+
+// readerLines renders the headers and body of the email, converting HTML to text if there is no text part
+func readerLines(e email.Email) []string {
+	lines := []string{
+		"From:    " + strings.Join(e.From, ", "),
+		"To:      " + strings.Join(e.To, ", "),
+	}
+	if len(e.Cc) > 0 {
+		lines = append(lines, "Cc:      "+strings.Join(e.Cc, ", "))
+	}
+	lines = append(lines, "Subject: "+e.Subject)
+	if t := e.Time(); !t.IsZero() {
+		lines = append(lines, "Date:    "+t.Local().Format(time.DateTime))
+	}
+	for _, attachment := range e.Attachments {
+		lines = append(lines, "Attach:  "+attachment.Filename)
+	}
+	lines = append(lines, "")
+	body := strings.ReplaceAll(search.Body(e), "\r\n", "\n")
+	return append(lines, strings.Split(strings.TrimRight(body, "\n"), "\n")...)
+}
+
+func (a *App) trash() {
+	item := a.selected()
+	if item == nil {
+		return
+	}
+	if _, err := a.client.Trash(a.ctx, email.TrashOptions{MessageID: item.MessageID}); err != nil {
+		a.status = "Error: " + err.Error()
+		return
+	}
+	a.trashed[item.MessageID] = true
+	a.status = "Trashed " + item.MessageID
+}
+
+func (a *App) untrash() {
+	item := a.selected()
+	if item == nil {
+		return
+	}
+	if _, err := a.client.Untrash(a.ctx, email.UntrashOptions{MessageID: item.MessageID}); err != nil {
+		a.status = "Error: " + err.Error()
+		return
+	}
+	delete(a.trashed, item.MessageID)
+	a.status = "Untrashed " + item.MessageID
+}
+
+func (a *App) delete() {
+	item := a.selected()
+	if item == nil {
+		return
+	}
+	id := item.MessageID
+	a.prompt = &prompt{label: "Delete " + id + " permanently? (y/n)", confirm: true, submit: func(string) {
+		if _, err := a.client.Delete(a.ctx, email.DeleteOptions{MessageID: id}); err != nil {
+			a.status = "Error: " + err.Error()
+			return
+		}
+		a.remove(id)
+		a.status = "Deleted " + id
+	}}
+}
+
+func (a *App) send() {
+	item := a.selected()
+	if item == nil {
+		return
+	}
+	if a.currentFolder().Type != email.EmailTypeDraft {
+		a.status = "Only drafts can be sent"
+		return
+	}
+	id := item.MessageID
+	a.prompt = &prompt{label: "Send " + id + "? (y/n)", confirm: true, submit: func(string) {
+		if _, err := a.client.Send(a.ctx, email.SendOptions{MessageID: id}); err != nil {
+			a.status = "Error: " + err.Error()
+			return
+		}
+		a.remove(id)
+		a.status = "Sent " + id
+	}}
+}
+
+// reply prompts for the text of a reply, which is saved as a draft quoting the original email
+func (a *App) reply(all bool) {
+	item := a.selected()
+	if item == nil {
+		return
+	}
+	label := "Reply: "
+	if all {
+		label = "Reply all: "
+	}
+	id := item.MessageID
+	a.prompt = &prompt{label: label, submit: func(text string) {
+		original := a.reading
+		if original == nil || original.MessageID != id {
+			var err error
+			original, err = a.client.Get(a.ctx, email.GetOptions{MessageID: id})
+			if err != nil {
+				a.status = "Error: " + err.Error()
+				return
+			}
+		}
+		// the reply is still saved without threading headers if the raw message can't be read
+		headers, _ := a.threadHeaders(id)
+		options := message.Reply(*original, message.ReplyOptions{All: all, Text: text, Thread: headers})
+		options.GenerateText = email.GenerateTextAuto
+		draft, err := a.client.Create(a.ctx, options)
+		if err != nil {
+			a.status = "Error: " + err.Error()
+			return
+		}
+		a.status = "Reply saved as draft " + draft.MessageID
+	}}
+}
+
+// threadHeaders reads the threading headers of the raw message of the email
+func (a *App) threadHeaders(messageID string) (message.ThreadHeaders, error) {
+	buf := &bytes.Buffer{}
+	if _, err := a.client.GetRaw(a.ctx, email.GetRawOptions{MessageID: messageID}, buf); err != nil {
+		return message.ThreadHeaders{}, err
+	}
+	return message.ReadThreadHeaders(buf)
+}
+
+// remove removes the email from the list, such as after deleting or sending it
+func (a *App) remove(messageID string) {
+	i := slices.IndexFunc(a.items, func(e email.Email) bool {
+		return e.MessageID == messageID
+	})
+	if i < 0 {
+		return
+	}
+	a.items = slices.Delete(a.items, i, i+1)
+	a.listIndex = min(a.listIndex, max(len(a.items)-1, 0))
+	if a.reading != nil && a.reading.MessageID == messageID {
+		a.reading, a.readerLines, a.readerOffset = nil, nil, 0
+	}
+}
+
+// loading shows a loading status before a blocking request
+func (a *App) loading() {
+	a.status = "Loading…"
+	a.draw()
+	a.status = ""
+}
@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/harryzcy/mailbox-cli/internal/email"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	pages   map[string]*email.ListResult // by next cursor
+	emails  map[string]*email.Email
+	raw     map[string]string
+	listed  []email.ListOptions
+	actions []string
+	created []email.CreateOptions
+	err     error
+}
+
+func (c *fakeClient) List(_ context.Context, options email.ListOptions) (*email.ListResult, error) {
+	c.listed = append(c.listed, options)
+	if c.err != nil {
+		return nil, c.err
+	}
+	if page, ok := c.pages[options.NextCursor]; ok {
+		return page, nil
+	}
+	return &email.ListResult{}, nil
+}
+
+func (c *fakeClient) Get(_ context.Context, options email.GetOptions) (*email.Email, error) {
+	return c.emails[options.MessageID], c.err
+}
+
+func (c *fakeClient) GetRaw(_ context.Context, options email.GetRawOptions, w io.Writer) (int64, error) {
+	raw, ok := c.raw[options.MessageID]
+	if !ok {
+		return 0, &email.APIError{StatusCode: http.StatusNotFound}
+	}
+	n, err := io.WriteString(w, raw)
+	return int64(n), err
+}
+
+func (c *fakeClient) action(name, messageID string) (*email.ActionResult, error) {
+	c.actions = append(c.actions, name+" "+messageID)
+	return &email.ActionResult{MessageID: messageID}, c.err
+}
+
+func (c *fakeClient) Trash(_ context.Context, options email.TrashOptions) (*email.ActionResult, error) {
+	return c.action("trash", options.MessageID)
+}
+
+func (c *fakeClient) Untrash(_ context.Context, options email.UntrashOptions) (*email.ActionResult, error) {
+	return c.action("untrash", options.MessageID)
+}
+
+func (c *fakeClient) Delete(_ context.Context, options email.DeleteOptions) (*email.ActionResult, error) {
+	return c.action("delete", options.MessageID)
+}
+
+func (c *fakeClient) Send(_ context.Context, options email.SendOptions) (*email.ActionResult, error) {
+	return c.action("send", options.MessageID)
+}
+
+func (c *fakeClient) Create(_ context.Context, options email.CreateOptions) (*email.Email, error) {
+	c.created = append(c.created, options)
+	return &email.Email{MessageID: "draft-id"}, c.err
+}
+
+func newTestApp(t *testing.T, client Client, options Options) (*App, tcell.SimulationScreen) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	err := screen.Init()
+	assert.Nil(t, err)
+	t.Cleanup(screen.Fini)
+	screen.SetSize(100, 20)
+
+	if options.Now.IsZero() {
+		options.Now = time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+	}
+	return New(context.Background(), screen, client, options), screen
+}
+
+// screenText returns the contents of the screen, one line per row
+func screenText(screen tcell.SimulationScreen) string {
+	cells, width, height := screen.GetContents()
+	b := &strings.Builder{}
+	for y := range height {
+		for x := range width {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				b.WriteRune(runes[0])
+			} else {
+				b.WriteRune(' ')
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func press(a *App, keys ...any) {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case rune:
+			a.handleKey(tcell.NewEventKey(tcell.KeyRune, key, tcell.ModNone))
+		case string:
+			for _, r := range key {
+				a.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			}
+		case tcell.Key:
+			a.handleKey(tcell.NewEventKey(key, 0, tcell.ModNone))
+		}
+	}
+	a.draw()
+}
+
+func TestApp(t *testing.T) {
+	unread := true
+	client := &fakeClient{
+		pages: map[string]*email.ListResult{
+			"": {Items: []email.Email{
+				{MessageID: "id-1", Subject: "Quarterly report", From: []string{"alice@example.com"}, Unread: &unread},
+				{MessageID: "id-2", Subject: "Lunch", From: []string{"bob@example.com"}},
+			}, NextCursor: "cursor"},
+			"cursor": {Items: []email.Email{
+				{MessageID: "id-3", Subject: "Older", From: []string{"carol@example.com"}},
+			}},
+		},
+		emails: map[string]*email.Email{
+			"id-1": {MessageID: "id-1", Subject: "Quarterly report", From: []string{"alice@example.com"}, Text: "See attached"},
+			"id-2": {MessageID: "id-2", Subject: "Lunch", From: []string{"bob@example.com"}, HTML: "<p>Pizza <b>today</b>?</p>"},
+		},
+		raw: map[string]string{
+			"id-1": "Message-ID: <id-1@example.com>\r\nSubject: Quarterly report\r\n\r\nSee attached\r\n",
+		},
+	}
+	a, screen := newTestApp(t, client, Options{Months: 2})
+	a.openFolder()
+	a.draw()
+
+	text := screenText(screen)
+	assert.Contains(t, text, "inbox 2025-03 (2 emails, more)")
+	assert.Contains(t, text, "Inbox")
+	assert.Contains(t, text, "2025-02")
+	assert.Contains(t, text, "●")
+	assert.Contains(t, text, "Quarterly report")
+	assert.Equal(t, email.ListOptions{Type: "inbox", Year: "2025", Month: "03", Order: email.OrderDesc}, client.listed[0])
+
+	// read the first email
+	press(a, tcell.KeyEnter)
+	assert.Contains(t, screenText(screen), "See attached")
+	assert.False(t, *a.items[0].Unread)
+
+	// the next page is loaded when reaching the end of the list
+	press(a, tcell.KeyEsc, 'j')
+	assert.Len(t, client.listed, 2)
+	assert.Equal(t, "cursor", client.listed[1].NextCursor)
+	assert.Contains(t, screenText(screen), "Older")
+
+	// HTML is rendered as text
+	press(a, tcell.KeyEnter)
+	assert.Contains(t, screenText(screen), "Pizza today?")
+
+	// actions
+	press(a, 't')
+	assert.Contains(t, screenText(screen), "Trashed id-2")
+	press(a, 'u', 'd', 'n')
+	assert.Contains(t, screenText(screen), "Cancelled")
+	press(a, 'd', 'y')
+	assert.Equal(t, []string{"trash id-2", "untrash id-2", "delete id-2"}, client.actions)
+	assert.Len(t, a.items, 2)
+	assert.Nil(t, a.reading)
+	press(a, 's')
+	assert.Contains(t, screenText(screen), "Only drafts can be sent")
+
+	// reply
+	press(a, tcell.KeyEsc, 'k', 'r', "Thanks!", tcell.KeyBackspace2, tcell.KeyEnter)
+	assert.Len(t, client.created, 1)
+	assert.Equal(t, "id-1@example.com", client.created[0].InReplyTo)
+	assert.Equal(t, []string{"alice@example.com"}, client.created[0].To)
+	assert.True(t, strings.HasPrefix(client.created[0].Text, "Thanks\n"))
+	assert.Contains(t, screenText(screen), "Reply saved as draft draft-id")
+	press(a, 'R', tcell.KeyEsc)
+	assert.Len(t, client.created, 1)
+
+	// switch to the drafts of last month, skipping the headers
+	press(a, tcell.KeyBacktab, 'G', 'k', tcell.KeyEnter)
+	assert.Equal(t, email.ListOptions{Type: "draft", Year: "2025", Month: "03", Order: email.OrderDesc}, client.listed[len(client.listed)-1])
+	press(a, tcell.KeyTab, tcell.KeyTab, 'j', tcell.KeyEnter)
+	assert.Equal(t, "draft", a.currentFolder().Type)
+	assert.Equal(t, time.February, a.currentFolder().Month)
+
+	press(a, 'q')
+	assert.True(t, a.quit)
+}
+
+func TestApp_Error(t *testing.T) {
+	client := &fakeClient{err: errors.New("boom")}
+	a, screen := newTestApp(t, client, Options{Types: []string{email.EmailTypeSent}})
+	a.openFolder()
+	a.draw()
+	text := screenText(screen)
+	assert.Contains(t, text, "Error: boom")
+	assert.Contains(t, text, "No emails")
+	assert.Equal(t, "sent", a.currentFolder().Type)
+}
+
+func TestApp_Run(t *testing.T) {
+	client := &fakeClient{pages: map[string]*email.ListResult{
+		"": {Items: []email.Email{{MessageID: "id-1", Subject: "Hello"}}},
+	}}
+	a, screen := newTestApp(t, client, Options{})
+	screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyCtrlC, 0, tcell.ModNone)
+
+	err := a.Run()
+	assert.Nil(t, err)
+	assert.Contains(t, screenText(screen), "Hello")
+	assert.Len(t, a.folders, 3*(DefaultMonths+1))
+}